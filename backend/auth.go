@@ -1,8 +1,8 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -11,40 +11,69 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+const (
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 7 * 24 * time.Hour
 
-// Claims represents JWT claims
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// Claims represents JWT claims. Type distinguishes access from refresh
+// tokens, SessionID names the refresh token family and the registered ID
+// claim (jti) identifies an individual refresh token.
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Type      string `json:"typ"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a JWT token
-func GenerateToken(user *User, duration time.Duration) (string, error) {
+// jwtSecretFromEnv returns JWT_SECRET; the server refuses to start without it
+func jwtSecretFromEnv() ([]byte, error) {
+	return secretFromEnv("JWT_SECRET")
+}
+
+// signClaims signs a token for user with the given type, session and lifetime
+func (h *Handler) signClaims(user *User, tokenType, sessionID, jti string, expiresAt time.Time) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		Type:      tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(h.jwtSecret)
+}
+
+// GenerateAccessToken creates a short-lived access token bound to a session
+func (h *Handler) GenerateAccessToken(user *User, sessionID string) (string, error) {
+	return h.signClaims(user, tokenTypeAccess, sessionID, "", time.Now().Add(accessTokenTTL))
+}
+
+// GenerateRefreshToken creates the refresh token currently valid for session
+func (h *Handler) GenerateRefreshToken(user *User, session *Session) (string, error) {
+	return h.signClaims(user, tokenTypeRefresh, session.ID, session.RefreshJTI, session.ExpiresAt)
 }
 
 // VerifyToken validates a JWT token
-func VerifyToken(tokenString string) (*Claims, error) {
+func (h *Handler) VerifyToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecret, nil
+		return h.jwtSecret, nil
 	})
 
 	if err != nil {
@@ -70,8 +99,10 @@ func CheckPassword(password, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
 
-// AuthMiddleware validates JWT token
-func AuthMiddleware(c *fiber.Ctx) error {
+// AuthMiddleware validates the access token and checks that its session
+// has not been revoked. The role and email come from the stored account,
+// not the token, so role changes and deactivation apply at once.
+func (h *Handler) AuthMiddleware(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
@@ -90,8 +121,8 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		})
 	}
 
-	claims, err := VerifyToken(parts[1])
-	if err != nil {
+	claims, err := h.VerifyToken(parts[1])
+	if err != nil || claims.Type != tokenTypeAccess {
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Invalid or expired token",
@@ -99,10 +130,35 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		})
 	}
 
-	// Store claims in context
-	c.Locals("user_id", claims.UserID)
-	c.Locals("email", claims.Email)
-	c.Locals("role", claims.Role)
+	session, err := h.store.Sessions.GetByID(c.UserContext(), claims.SessionID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return storeError(c, err, "")
+	}
+	if session == nil || !session.Active(time.Now()) || session.UserID != claims.UserID {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Session has been revoked",
+			Code:    fiber.StatusUnauthorized,
+		})
+	}
+
+	user, err := h.store.Users.GetByID(c.UserContext(), session.UserID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return storeError(c, err, "")
+	}
+	if user == nil || !user.IsActive {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Account is disabled",
+			Code:    fiber.StatusUnauthorized,
+		})
+	}
+
+	// Store the account in context
+	c.Locals("user_id", user.ID)
+	c.Locals("email", user.Email)
+	c.Locals("role", user.Role)
+	c.Locals("session_id", session.ID)
 
 	return c.Next()
 }
@@ -128,3 +184,21 @@ func GetUserFromContext(c *fiber.Ctx) string {
 	}
 	return userID.(string)
 }
+
+// GetEmailFromContext extracts the email of the signed-in user from context
+func GetEmailFromContext(c *fiber.Ctx) string {
+	email := c.Locals("email")
+	if email == nil {
+		return ""
+	}
+	return email.(string)
+}
+
+// GetSessionFromContext extracts the session ID of the access token from context
+func GetSessionFromContext(c *fiber.Ctx) string {
+	sessionID := c.Locals("session_id")
+	if sessionID == nil {
+		return ""
+	}
+	return sessionID.(string)
+}
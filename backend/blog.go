@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// blogAnnouncement is the data for the blog_announcement template, which
+// becomes the body of the announcement campaign
+type blogAnnouncement struct {
+	Title    string
+	Excerpt  string
+	ImageURL string
+	ImageAlt string
+	Author   string
+	URL      string
+}
+
+// acceptLegacyContent moves content sent under the former content field to
+// content_markdown
+func acceptLegacyContent(post *BlogPost) {
+	if post.ContentMarkdown == "" {
+		post.ContentMarkdown = post.Content
+	}
+	post.Content = ""
+}
+
+// renderBlogPost renders the Markdown of a post into its sanitized HTML,
+// table of contents and reading time
+func renderBlogPost(post *BlogPost) error {
+	rendered, err := renderMarkdown(post.ContentMarkdown)
+	if err != nil {
+		return err
+	}
+	post.ContentHTML = rendered.HTML
+	post.TableOfContents = rendered.TableOfContents
+	post.ReadingTimeMinutes = rendered.ReadingMinutes
+	return nil
+}
+
+// RenderBlogPosts renders the posts whose Markdown has never been rendered,
+// such as those written before posts were authored in Markdown. It runs
+// once when the server starts.
+func (h *Handler) RenderBlogPosts(ctx context.Context) error {
+	var stale []BlogPost
+	page := PaginationParams{Page: 1, Limit: 100}
+	for {
+		posts, total, err := h.store.Blog.List(ctx, BlogFilter{PaginationParams: page})
+		if err != nil {
+			return err
+		}
+		for _, post := range posts {
+			if post.ContentHTML == "" && post.ContentMarkdown != "" {
+				stale = append(stale, post)
+			}
+		}
+		if len(posts) == 0 || page.Page*page.Limit >= total {
+			break
+		}
+		page.Page++
+	}
+
+	for i := range stale {
+		if err := renderBlogPost(&stale[i]); err != nil {
+			return fmt.Errorf("blog post %s: %w", stale[i].ID, err)
+		}
+		// Rendering is not an edit, so updated_at and the revisions stay
+		if err := h.store.Blog.Update(ctx, &stale[i]); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("blog post %s: %w", stale[i].ID, err)
+		}
+	}
+	if len(stale) > 0 {
+		log.Printf("Rendered %d blog post(s)", len(stale))
+	}
+	return nil
+}
+
+// publishBlogPost moves a post in one of the from statuses to scheduled when
+// at is in the future, or publishes it and sends its announcement
+func (h *Handler) publishBlogPost(ctx context.Context, id string, from []string, at time.Time) error {
+	if at.After(time.Now()) {
+		return h.store.Blog.Transition(ctx, id, from, BlogScheduled, at)
+	}
+	if err := h.store.Blog.Transition(ctx, id, from, BlogPublished, time.Now()); err != nil {
+		return err
+	}
+	h.announceBlogPost(ctx, id)
+	return nil
+}
+
+// PublishScheduledPosts publishes the scheduled posts whose time has come.
+// It runs periodically in the background.
+func (h *Handler) PublishScheduledPosts(ctx context.Context) error {
+	due, err := h.store.Blog.Due(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, post := range due {
+		err := h.store.Blog.Transition(ctx, post.ID, []string{BlogScheduled}, BlogPublished, time.Now())
+		if errors.Is(err, ErrConflict) {
+			// Unpublished or published by hand after this run began
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("blog post %s: %w", post.ID, err)
+		}
+		log.Printf("Blog post %s published", post.ID)
+		h.announceBlogPost(ctx, post.ID)
+	}
+	return nil
+}
+
+// announceBlogPost emails subscribers about a post that was just published,
+// when the post asks for it and has not been announced before. The email is
+// a newsletter campaign to every active subscriber, scheduled straight away.
+// Failures are logged, as the post is already live.
+func (h *Handler) announceBlogPost(ctx context.Context, id string) {
+	if err := h.createBlogAnnouncement(ctx, id); err != nil {
+		log.Printf("Announcing blog post %s failed: %v", id, err)
+	}
+}
+
+func (h *Handler) createBlogAnnouncement(ctx context.Context, id string) error {
+	post, err := h.store.Blog.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !post.Announce || post.AnnouncementID != nil {
+		return nil
+	}
+
+	msg, err := h.mail.templates.Render("blog_announcement", blogAnnouncement{
+		Title:    post.Title,
+		Excerpt:  post.Excerpt,
+		ImageURL: post.ImageURL,
+		ImageAlt: post.ImageAlt,
+		Author:   post.Author,
+		URL:      h.frontendURL + "/blog/" + post.Slug,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	campaign := &Campaign{
+		ID:          uuid.New().String(),
+		Subject:     msg.Subject,
+		HTMLBody:    msg.HTML,
+		TextBody:    msg.Text,
+		Segment:     segmentAllSubscribers,
+		Status:      CampaignScheduled,
+		ScheduledAt: &now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	// The campaign is stored and linked in one step, so a post published
+	// twice at once is still announced only once
+	err = h.store.Blog.Announce(ctx, id, campaign)
+	if errors.Is(err, ErrConflict) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Blog post %s announced in campaign %s", id, campaign.ID)
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBlogPublishWorkflow(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+	admin := "/api/v1/admin/blog/"
+
+	var post BlogPost
+	draft := BlogPost{Title: "New Plots in Lekki", Content: "Phase two is open.", Excerpt: "Phase two", Announce: true}
+	if status := call(t, app, "POST", "/api/v1/admin/blog", token, draft, &post); status != fiber.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	public := func() int {
+		t.Helper()
+		return call(t, app, "GET", "/api/v1/blog/"+post.ID, "", nil, nil)
+	}
+	if post.Status != BlogDraft || public() != fiber.StatusNotFound {
+		t.Fatalf("new post is %s and public status %d, want a hidden draft", post.Status, public())
+	}
+
+	// Scheduling keeps the post hidden until the publisher reaches it
+	later := time.Now().Add(time.Hour)
+	call(t, app, "POST", admin+post.ID+"/publish", token, PublishBlogPostRequest{PublishAt: &later}, &post)
+	if post.Status != BlogScheduled || post.PublishAt == nil || public() != fiber.StatusNotFound {
+		t.Fatalf("scheduled post is %s, public status %d", post.Status, public())
+	}
+	if err := h.PublishScheduledPosts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := h.store.Blog.GetByID(ctx, post.ID); got.Status != BlogScheduled {
+		t.Fatalf("a post scheduled for later was published as %s", got.Status)
+	}
+
+	// Publishing now goes live and announces the post once
+	if status := call(t, app, "POST", admin+post.ID+"/publish", token, nil, &post); status != fiber.StatusOK {
+		t.Fatalf("publish status = %d", status)
+	}
+	if post.Status != BlogPublished || !post.Published || post.PublishedAt == nil || public() != fiber.StatusOK {
+		t.Fatalf("published post = %+v, public status %d", post, public())
+	}
+	if post.AnnouncementID == nil {
+		t.Fatal("publishing did not create the announcement")
+	}
+	campaign, err := h.store.Campaigns.GetByID(ctx, *post.AnnouncementID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if campaign.Status != CampaignScheduled || !strings.Contains(campaign.Subject, "New Plots in Lekki") || !strings.Contains(campaign.HTMLBody, "/blog/"+post.Slug) {
+		t.Errorf("announcement campaign = %s %q", campaign.Status, campaign.Subject)
+	}
+	if status := call(t, app, "POST", admin+post.ID+"/publish", token, nil, nil); status != fiber.StatusConflict {
+		t.Errorf("publishing twice: status %d, want 409", status)
+	}
+
+	firstPublished := *post.PublishedAt
+	call(t, app, "POST", admin+post.ID+"/unpublish", token, nil, &post)
+	if post.Status != BlogDraft || public() != fiber.StatusNotFound {
+		t.Fatalf("unpublished post is %s, public status %d", post.Status, public())
+	}
+	call(t, app, "POST", admin+post.ID+"/publish", token, nil, &post)
+	if post.AnnouncementID == nil || *post.AnnouncementID != campaign.ID {
+		t.Errorf("republishing changed the announcement to %v", post.AnnouncementID)
+	}
+	if !post.PublishedAt.Equal(firstPublished) {
+		t.Errorf("republishing moved published_at from %v to %v", firstPublished, post.PublishedAt)
+	}
+	if campaigns, total, _ := h.store.Campaigns.List(ctx, CampaignFilter{PaginationParams: PaginationParams{Page: 1, Limit: 10}}); total != 1 {
+		t.Errorf("%d announcement campaigns, want 1: %+v", total, campaigns)
+	}
+}
+
+func TestPublishScheduledPosts(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+
+	var quiet BlogPost
+	call(t, app, "POST", "/api/v1/admin/blog", token, BlogPost{Title: "Site Visit Day", Content: "Join us."}, &quiet)
+	if err := h.store.Blog.Transition(ctx, quiet.ID, []string{BlogDraft}, BlogScheduled, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.PublishScheduledPosts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := h.store.Blog.GetByID(ctx, quiet.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != BlogPublished || got.AnnouncementID != nil {
+		t.Errorf("due post is %s with announcement %v, want published without one", got.Status, got.AnnouncementID)
+	}
+	var posts struct {
+		Total int `json:"total"`
+	}
+	call(t, app, "GET", "/api/v1/blog", "", nil, &posts)
+	if posts.Total != 1 {
+		t.Errorf("public blog lists %d posts, want 1", posts.Total)
+	}
+}
+
+func TestBlogAnnouncedOnce(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+
+	var post BlogPost
+	call(t, app, "POST", "/api/v1/admin/blog", token, BlogPost{Title: "Phase Three", Content: "Now open.", Announce: true}, &post)
+	if err := h.store.Blog.Transition(ctx, post.ID, []string{BlogDraft}, BlogPublished, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A manual publish and the scheduler can both reach a post at once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.announceBlogPost(ctx, post.ID)
+		}()
+	}
+	wg.Wait()
+
+	campaigns, total, err := h.store.Campaigns.List(ctx, CampaignFilter{PaginationParams: PaginationParams{Page: 1, Limit: 20}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("%d announcement campaigns, want 1", total)
+	}
+	got, _ := h.store.Blog.GetByID(ctx, post.ID)
+	if got.AnnouncementID == nil || *got.AnnouncementID != campaigns[0].ID || campaigns[0].Status != CampaignScheduled {
+		t.Errorf("post announced by %v, campaign %s is %s", got.AnnouncementID, campaigns[0].ID, campaigns[0].Status)
+	}
+}
+
+func TestBlogContentLengthCap(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+
+	long := BlogPost{Title: "Long read", ContentMarkdown: strings.Repeat("x", 100001)}
+	if status := call(t, app, "POST", "/api/v1/admin/blog", token, long, nil); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("post over the length cap: status %d, want 422", status)
+	}
+	long.ContentMarkdown = long.ContentMarkdown[1:]
+	if status := call(t, app, "POST", "/api/v1/admin/blog", token, long, nil); status != fiber.StatusCreated {
+		t.Errorf("post at the length cap: status %d, want 201", status)
+	}
+}
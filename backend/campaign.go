@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Token purposes for the tracking links in campaign emails
+const (
+	tokenCampaignOpen  = "campaign-open"
+	tokenCampaignClick = "campaign-click"
+)
+
+// campaignEmail is the data for the campaign template. The tracking URLs
+// are empty in copies that are not tracked.
+type campaignEmail struct {
+	Subject        string
+	HTML           htmltemplate.HTML
+	Text           string
+	UnsubscribeURL string
+	OpenPixelURL   string
+	NoTrackingURL  string
+}
+
+// renderCampaign builds the email of a campaign for one recipient. Every
+// copy carries the recipient's unsubscribe link in its footer and in a
+// List-Unsubscribe header, whatever the body says. With a recipientID the
+// copy is tracked: its web links go through the click tracker and an
+// invisible image records opens.
+func (h *Handler) renderCampaign(campaign *Campaign, email, recipientID string) (Message, error) {
+	text := campaign.TextBody
+	if strings.TrimSpace(text) == "" {
+		text = htmlToText(campaign.HTMLBody)
+	}
+	unsubscribe := h.unsubscribeURL(email)
+
+	data := campaignEmail{
+		Subject:        campaign.Subject,
+		HTML:           htmltemplate.HTML(campaign.HTMLBody),
+		Text:           text,
+		UnsubscribeURL: unsubscribe,
+	}
+	if recipientID != "" {
+		data.HTML = htmltemplate.HTML(h.trackLinks(campaign.HTMLBody, recipientID))
+		data.OpenPixelURL = h.newsletterURL("/newsletter/track/open", h.signToken(tokenCampaignOpen, recipientID, time.Time{}))
+		data.NoTrackingURL = h.newsletterURL("/newsletter/tracking-opt-out", h.signToken(tokenNewsletterNoTracking, email, time.Time{}))
+	}
+
+	msg, err := h.mail.templates.Render("campaign", data)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.To = []string{email}
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribe + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return msg, nil
+}
+
+// htmlHref matches the href attribute of a link, double or single quoted
+var htmlHref = regexp.MustCompile(`(?i)(<a\s[^>]*?\bhref\s*=\s*)("[^"]*"|'[^']*')`)
+
+// trackLinks points every http and https link in body at the click tracker.
+// The destination is signed into the token, so the tracker cannot be used
+// to redirect anywhere else.
+func (h *Handler) trackLinks(body, recipientID string) string {
+	return htmlHref.ReplaceAllStringFunc(body, func(match string) string {
+		parts := htmlHref.FindStringSubmatch(match)
+		quoted := parts[2]
+		target := html.UnescapeString(quoted[1 : len(quoted)-1])
+		lower := strings.ToLower(target)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			return match
+		}
+		token := h.signToken(tokenCampaignClick, recipientID+"\n"+target, time.Time{})
+		return parts[1] + `"` + html.EscapeString(h.newsletterURL("/newsletter/track/click", token)) + `"`
+	})
+}
+
+var (
+	htmlHidden    = regexp.MustCompile(`(?is)<(head|script|style)\b.*?</(head|script|style)\s*>`)
+	htmlLink      = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*"([^"]*)"[^>]*>(.*?)</a\s*>`)
+	htmlBlockEnd  = regexp.MustCompile(`(?i)</(p|div|h[1-6]|blockquote|table|ul|ol)\s*>`)
+	htmlLineBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(li|tr)\s*>`)
+	htmlListItem  = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText derives a plain text alternative from an HTML body. Links keep
+// their address in brackets after the link text.
+func htmlToText(body string) string {
+	body = htmlHidden.ReplaceAllString(body, "")
+	body = htmlLink.ReplaceAllString(body, "$2 ($1)")
+	body = htmlBlockEnd.ReplaceAllString(body, "\n\n")
+	body = htmlLineBreak.ReplaceAllString(body, "\n")
+	body = htmlListItem.ReplaceAllString(body, "- ")
+	body = html.UnescapeString(htmlTag.ReplaceAllString(body, ""))
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// segmentAllSubscribers is the campaign segment of every active subscriber
+const segmentAllSubscribers = "all"
+
+// errAudienceUnresolvable is returned for a campaign whose segment was
+// deleted or has a filter that no longer parses. Retrying cannot help.
+var errAudienceUnresolvable = errors.New("campaign audience cannot be resolved")
+
+// campaignAudience returns the active subscribers a campaign goes to: all
+// of them, or those matching its segment
+func (h *Handler) campaignAudience(ctx context.Context, campaign *Campaign) ([]NewsletterSubscriber, error) {
+	if campaign.Segment == "" || campaign.Segment == segmentAllSubscribers {
+		return h.activeSubscribers(ctx, nil)
+	}
+	subscribers, err := h.segmentSubscribers(ctx, campaign.Segment)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, errInvalidSegmentFilter) {
+		return nil, fmt.Errorf("segment %s: %w: %w", campaign.Segment, errAudienceUnresolvable, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("segment %s: %w", campaign.Segment, err)
+	}
+	return subscribers, nil
+}
+
+// activeSubscribers returns the active subscribers matching expr, or all of
+// them when expr is nil
+func (h *Handler) activeSubscribers(ctx context.Context, expr segmentExpr) ([]NewsletterSubscriber, error) {
+	matched := []NewsletterSubscriber{}
+	page := PaginationParams{Page: 1, Limit: 500}
+	for {
+		subscribers, total, err := h.store.Newsletter.List(ctx, NewsletterFilter{
+			PaginationParams: page,
+			Status:           SubscriberActive,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range subscribers {
+			if expr == nil || expr.match(&subscribers[i]) {
+				matched = append(matched, subscribers[i])
+			}
+		}
+		if len(subscribers) == 0 || page.Page*page.Limit >= total {
+			return matched, nil
+		}
+		page.Page++
+	}
+}
+
+// SendCampaigns sends the next batch of every due campaign. It runs
+// periodically in the background, so each campaign goes out at no more than
+// one batch per run. Progress is stored per recipient, and after a restart
+// sending carries on where it stopped. A campaign that errors is logged and
+// tried again on the next run without holding up the others.
+func (h *Handler) SendCampaigns(ctx context.Context) error {
+	due, err := h.store.Campaigns.Due(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if err := h.sendCampaignBatch(ctx, &due[i]); err != nil {
+			log.Printf("Campaign %s: %v", due[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// sendCampaignBatch starts a scheduled campaign, then sends to its next
+// pending recipients, or marks it sent when none are left. A campaign whose
+// audience cannot be resolved is marked failed.
+func (h *Handler) sendCampaignBatch(ctx context.Context, campaign *Campaign) error {
+	if campaign.Status == CampaignScheduled {
+		started, err := h.startCampaign(ctx, campaign)
+		if errors.Is(err, errAudienceUnresolvable) {
+			return h.failCampaign(ctx, campaign, err)
+		}
+		if err != nil || !started {
+			return err
+		}
+	}
+
+	recipients, err := h.store.Campaigns.PendingRecipients(ctx, campaign.ID, h.campaignBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		err := h.store.Campaigns.Transition(ctx, campaign.ID, []string{CampaignSending}, CampaignSent, time.Now())
+		if errors.Is(err, ErrConflict) {
+			// Cancelled after this run began
+			return nil
+		}
+		if err == nil {
+			log.Printf("Campaign %s sent", campaign.ID)
+		}
+		return err
+	}
+
+	for i := range recipients {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := h.deliverCampaign(ctx, campaign, &recipients[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failCampaign marks a scheduled campaign failed because of cause
+func (h *Handler) failCampaign(ctx context.Context, campaign *Campaign, cause error) error {
+	err := h.store.Campaigns.Transition(ctx, campaign.ID, []string{CampaignScheduled}, CampaignFailed, time.Now())
+	if errors.Is(err, ErrConflict) {
+		// Unscheduled after this run began
+		return nil
+	}
+	if err == nil {
+		log.Printf("Campaign %s failed: %v", campaign.ID, cause)
+	}
+	return err
+}
+
+// startCampaign queues the campaign's audience and marks it sending. It
+// reports false if the campaign was unscheduled in the meantime. Recipients
+// are queued first, so a restart in between just queues them again, which
+// is ignored.
+func (h *Handler) startCampaign(ctx context.Context, campaign *Campaign) (bool, error) {
+	audience, err := h.campaignAudience(ctx, campaign)
+	if err != nil {
+		return false, err
+	}
+	recipients := make([]CampaignRecipient, len(audience))
+	for i, subscriber := range audience {
+		id := subscriber.ID
+		recipients[i] = CampaignRecipient{SubscriberID: &id, Email: subscriber.Email}
+	}
+	if err := h.store.Campaigns.AddRecipients(ctx, campaign.ID, recipients); err != nil {
+		return false, err
+	}
+
+	err = h.store.Campaigns.Transition(ctx, campaign.ID, []string{CampaignScheduled}, CampaignSending, time.Now())
+	if errors.Is(err, ErrConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Printf("Campaign %s started for %d subscriber(s)", campaign.ID, len(recipients))
+	return true, nil
+}
+
+// deliverCampaign sends a campaign to one recipient and records the outcome.
+// Subscribers who left after the campaign started are skipped; failed sends
+// are retried in later batches until campaignMaxAttempts is reached.
+func (h *Handler) deliverCampaign(ctx context.Context, campaign *Campaign, recipient *CampaignRecipient) error {
+	subscriber, err := h.store.Newsletter.GetByEmail(ctx, recipient.Email)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if subscriber == nil || subscriber.Status != SubscriberActive {
+		recipient.Status = "skipped"
+		return h.store.Campaigns.UpdateRecipient(ctx, recipient)
+	}
+
+	trackAs := recipient.ID
+	if subscriber.TrackingOptOut {
+		trackAs = ""
+	}
+	msg, err := h.renderCampaign(campaign, recipient.Email, trackAs)
+	if err == nil {
+		sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		err = h.mail.Deliver(sendCtx, msg)
+		cancel()
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the recipient stays pending for the next run
+		return nil
+	}
+
+	recipient.Attempts++
+	if err != nil {
+		recipient.Error = err.Error()
+		if recipient.Attempts >= h.campaignMaxAttempts {
+			recipient.Status = "failed"
+		}
+	} else {
+		now := time.Now()
+		recipient.Status = "sent"
+		recipient.Error = ""
+		recipient.SentAt = &now
+	}
+	return h.store.Campaigns.UpdateRecipient(ctx, recipient)
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestTrackLinks(t *testing.T) {
+	h := &Handler{signingKey: []byte("test-key"), publicURL: "https://api.example.com"}
+	tracker := "https://api.example.com/api/v1/newsletter/track/click"
+
+	// hrefs lists the links of body, showing tracked ones as track:<destination>
+	hrefs := func(t *testing.T, body string) []string {
+		var links []string
+		for _, parts := range htmlHref.FindAllStringSubmatch(body, -1) {
+			href := html.UnescapeString(parts[2][1 : len(parts[2])-1])
+			if !strings.HasPrefix(href, tracker+"?") {
+				links = append(links, href)
+				continue
+			}
+			u, err := url.Parse(href)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, ok := h.parseToken(tokenCampaignClick, u.Query().Get("token"), time.Now())
+			recipientID, target, _ := strings.Cut(value, "\n")
+			if !ok || recipientID != "r1" {
+				t.Fatalf("tracked link %q carries %q", href, value)
+			}
+			links = append(links, "track:"+target)
+		}
+		return links
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"no links", "<p>Hello</p>", nil},
+		{"https", `<a href="https://example.com/plots">Plots</a>`, []string{"track:https://example.com/plots"}},
+		{"http, upper case", `<A HREF="HTTP://example.com">x</A>`, []string{"track:HTTP://example.com"}},
+		{"single quotes", `<a class="btn" href='https://example.com'>x</a>`, []string{"track:https://example.com"}},
+		{
+			"entities in the destination",
+			`<a href="https://example.com/?a=1&amp;b=2">x</a>`,
+			[]string{"track:https://example.com/?a=1&b=2"},
+		},
+		{"mailto is kept", `<a href="mailto:sales@example.com">Mail</a>`, []string{"mailto:sales@example.com"}},
+		{"relative is kept", `<a href="/properties">x</a>`, []string{"/properties"}},
+		{"anchor is kept", `<a href="#top">x</a>`, []string{"#top"}},
+		{"images are not links", `<img src="https://example.com/a.png">`, nil},
+		{
+			"every link",
+			`<a href="https://a.example">a</a> <a href="mailto:x@y.z">m</a> <a href="https://b.example">b</a>`,
+			[]string{"track:https://a.example", "mailto:x@y.z", "track:https://b.example"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hrefs(t, h.trackLinks(tt.body, "r1"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("links = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if body := `<img src="https://example.com/a.png">`; h.trackLinks(body, "r1") != body {
+		t.Errorf("trackLinks changed a body without links")
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"plain", "Hello", "Hello"},
+		{"paragraphs", "<p>One</p><p>Two</p>", "One\n\nTwo"},
+		{"line breaks", "a<br>b<br/>c", "a\nb\nc"},
+		{"whitespace", "<p>  lots   of\t space </p>", "lots of space"},
+		{"link", `<p>See <a href="https://example.com">our plots</a>.</p>`, "See our plots (https://example.com)."},
+		{"list", "<ul><li>One</li><li>Two</li></ul>", "- One\n- Two"},
+		{"heading", "<h1>Title</h1><p>Body</p>", "Title\n\nBody"},
+		{"entities", "<p>Fish &amp; chips &lt;3</p>", "Fish & chips <3"},
+		{"hidden parts", "<head><title>T</title></head><style>p{}</style><script>x()</script><p>Shown</p>", "Shown"},
+		{"blank lines collapse", "<p>a</p>\n\n\n<div></div><p>b</p>", "a\n\nb"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToText(tt.html); got != tt.want {
+				t.Errorf("htmlToText(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
+
+// addSubscriber stores a newsletter subscriber with the given status
+func addSubscriber(t *testing.T, h *Handler, email, status string) {
+	t.Helper()
+	err := h.store.Newsletter.Create(context.Background(), &NewsletterSubscriber{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Status:    status,
+		Active:    status == SubscriberActive,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scheduleCampaign creates a campaign through the API and schedules it to go out now
+func scheduleCampaign(t *testing.T, app *fiber.App, token string) Campaign {
+	t.Helper()
+	var campaign Campaign
+	req := CampaignRequest{Subject: "Plots in Lekki", HTMLBody: `<p>New plots: <a href="https://example.com/lekki">see them</a></p>`}
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/campaigns", token, req, &campaign); status != fiber.StatusCreated {
+		t.Fatalf("create campaign status = %d", status)
+	}
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/campaigns/"+campaign.ID+"/schedule", token, nil, &campaign); status != fiber.StatusOK {
+		t.Fatalf("schedule campaign status = %d", status)
+	}
+	return campaign
+}
+
+func TestCampaignSending(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+	h.campaignBatchSize = 2
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		addSubscriber(t, h, email, SubscriberActive)
+	}
+	addSubscriber(t, h, "pending@example.com", SubscriberPending)
+	addSubscriber(t, h, "gone@example.com", SubscriberUnsubscribed)
+	campaign := scheduleCampaign(t, app, token)
+
+	// Each run sends one batch
+	if err := h.SendCampaigns(ctx); err != nil {
+		t.Fatal(err)
+	}
+	delivered := h.mail.mailer.(*CaptureMailer).Messages()
+	if len(delivered) != 2 {
+		t.Fatalf("first run delivered %d emails, want a batch of 2", len(delivered))
+	}
+	msg := delivered[0]
+	if msg.Subject != "Plots in Lekki" || !strings.HasPrefix(msg.Headers["List-Unsubscribe"], "<"+h.publicURL+"/api/v1/newsletter/unsubscribe?token=") {
+		t.Errorf("campaign email = %q, headers %v", msg.Subject, msg.Headers)
+	}
+	if !strings.Contains(msg.Text, "see them (https://example.com/lekki)") {
+		t.Errorf("plain text body was not derived from the HTML:\n%s", msg.Text)
+	}
+
+	// Someone who unsubscribes mid-campaign is skipped
+	var remaining string
+	recipients, err := h.store.Campaigns.PendingRecipients(ctx, campaign.ID, 10)
+	if err != nil || len(recipients) != 1 {
+		t.Fatalf("pending recipients = %+v, %v", recipients, err)
+	}
+	remaining = recipients[0].Email
+	subscriber, _ := h.store.Newsletter.GetByEmail(ctx, remaining)
+	if err := h.unsubscribe(ctx, subscriber); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := h.SendCampaigns(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(h.mail.mailer.(*CaptureMailer).Messages()); n != 2 {
+		t.Errorf("%d emails delivered in total, want 2", n)
+	}
+	got, err := h.store.Campaigns.GetByID(ctx, campaign.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != CampaignSent || got.RecipientCount != 3 || got.SentCount != 2 || got.SkippedCount != 1 {
+		t.Errorf("campaign = %s with %d recipients, %d sent, %d skipped", got.Status, got.RecipientCount, got.SentCount, got.SkippedCount)
+	}
+	if status := call(t, app, "PUT", "/api/v1/admin/newsletter/campaigns/"+campaign.ID, token, CampaignRequest{Subject: "x", HTMLBody: "x"}, nil); status != fiber.StatusConflict {
+		t.Errorf("editing a sent campaign: status %d, want 409", status)
+	}
+}
+
+// failingMailer never delivers
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg Message) error {
+	return errors.New("mailbox unavailable")
+}
+
+func TestCampaignDeliveryFailures(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+	h.mail.mailer = failingMailer{}
+	h.campaignMaxAttempts = 2
+	addSubscriber(t, h, "a@example.com", SubscriberActive)
+	campaign := scheduleCampaign(t, app, token)
+
+	for run := 1; run <= 3; run++ {
+		if err := h.SendCampaigns(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := h.store.Campaigns.GetByID(ctx, campaign.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != CampaignSent || got.FailedCount != 1 || got.SentCount != 0 {
+		t.Errorf("campaign = %s, %d failed, %d sent; want sent with 1 failure", got.Status, got.FailedCount, got.SentCount)
+	}
+
+	var recipients struct {
+		Data []CampaignRecipient `json:"data"`
+	}
+	call(t, app, "GET", "/api/v1/admin/newsletter/campaigns/"+campaign.ID+"/recipients", token, nil, &recipients)
+	if len(recipients.Data) != 1 || recipients.Data[0].Attempts != 2 || recipients.Data[0].Error != "mailbox unavailable" {
+		t.Errorf("recipients = %+v", recipients.Data)
+	}
+}
+
+func TestCampaignUnresolvableSegment(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+	addSubscriber(t, h, "a@example.com", SubscriberActive)
+
+	segment := &Segment{ID: uuid.New().String(), Name: "VIPs", Filter: "tag:vip", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := h.store.Segments.Create(ctx, segment); err != nil {
+		t.Fatal(err)
+	}
+	var orphan Campaign
+	req := CampaignRequest{Subject: "VIP plots", HTMLBody: "<p>Hi</p>", Segment: segment.ID}
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/campaigns", token, req, &orphan); status != fiber.StatusCreated {
+		t.Fatalf("create campaign status = %d", status)
+	}
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/campaigns/"+orphan.ID+"/schedule", token, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("schedule campaign status = %d", status)
+	}
+	healthy := scheduleCampaign(t, app, token)
+	if err := h.store.Segments.Delete(ctx, segment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The campaign without an audience fails; the other one still goes out
+	if err := h.SendCampaigns(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := h.store.Campaigns.GetByID(ctx, orphan.ID); got.Status != CampaignFailed {
+		t.Errorf("campaign with a deleted segment is %s, want failed", got.Status)
+	}
+	if err := h.SendCampaigns(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := h.store.Campaigns.GetByID(ctx, healthy.ID); got.Status != CampaignSent || got.SentCount != 1 {
+		t.Errorf("other campaign is %s with %d sent, want sent to 1", got.Status, got.SentCount)
+	}
+
+	// A failed campaign can be pointed at everyone and scheduled again
+	req.Segment = segmentAllSubscribers
+	if status := call(t, app, "PUT", "/api/v1/admin/newsletter/campaigns/"+orphan.ID, token, req, nil); status != fiber.StatusOK {
+		t.Errorf("editing a failed campaign: status %d, want 200", status)
+	}
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/campaigns/"+orphan.ID+"/schedule", token, nil, nil); status != fiber.StatusOK {
+		t.Errorf("rescheduling a failed campaign: status %d, want 200", status)
+	}
+}
+
+// trackedLinks returns the path and query of the tracker links in an HTML body
+func trackedLinks(t *testing.T, body, path string) []string {
+	t.Helper()
+	var links []string
+	for _, attr := range regexp.MustCompile(`(?:href|src)="([^"]*)"`).FindAllStringSubmatch(body, -1) {
+		link, err := url.Parse(html.UnescapeString(attr[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.Path == path {
+			links = append(links, link.RequestURI())
+		}
+	}
+	return links
+}
+
+func TestCampaignTracking(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	ctx := context.Background()
+	addSubscriber(t, h, "tracked@example.com", SubscriberActive)
+	addSubscriber(t, h, "private@example.com", SubscriberActive)
+	private, _ := h.store.Newsletter.GetByEmail(ctx, "private@example.com")
+	optOut := h.newsletterURL("/newsletter/tracking-opt-out", h.signToken(tokenNewsletterNoTracking, private.Email, time.Time{}))
+	if status := call(t, app, "POST", strings.TrimPrefix(optOut, h.publicURL), "", nil, nil); status != fiber.StatusOK {
+		t.Fatalf("tracking opt-out status = %d", status)
+	}
+
+	campaign := scheduleCampaign(t, app, token)
+	for i := 0; i < 2; i++ {
+		if err := h.SendCampaigns(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emails := map[string]Message{}
+	for _, msg := range h.mail.mailer.(*CaptureMailer).Messages() {
+		emails[msg.To[0]] = msg
+	}
+
+	if links := trackedLinks(t, emails["private@example.com"].HTML, "/api/v1/newsletter/track/click"); len(links) != 0 {
+		t.Errorf("the email to a subscriber who opted out is tracked: %v", links)
+	}
+	tracked := emails["tracked@example.com"].HTML
+	clicks := trackedLinks(t, tracked, "/api/v1/newsletter/track/click")
+	opens := trackedLinks(t, tracked, "/api/v1/newsletter/track/open")
+	if len(clicks) != 1 || len(opens) != 1 {
+		t.Fatalf("tracked email has %d click and %d open links:\n%s", len(clicks), len(opens), tracked)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", opens[0], nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.Header.Get(fiber.HeaderContentType) != "image/gif" {
+			t.Errorf("open pixel content type = %q", resp.Header.Get(fiber.HeaderContentType))
+		}
+	}
+	resp, err := app.Test(httptest.NewRequest("GET", clicks[0], nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusFound || resp.Header.Get(fiber.HeaderLocation) != "https://example.com/lekki" {
+		t.Errorf("click: status %d, location %q", resp.StatusCode, resp.Header.Get(fiber.HeaderLocation))
+	}
+
+	// The destination is signed, so the tracker is not an open redirect
+	link, _ := url.Parse(clicks[0])
+	value, _ := h.parseToken(tokenCampaignClick, link.Query().Get("token"), time.Now())
+	recipientID, _, _ := strings.Cut(value, "\n")
+	forged := h.signToken(tokenCampaignOpen, recipientID+"\nhttps://evil.example", time.Time{})
+	if status := call(t, app, "GET", "/api/v1/newsletter/track/click?token="+url.QueryEscape(forged), "", nil, nil); status != fiber.StatusForbidden {
+		t.Errorf("click token signed for another purpose: status %d, want 403", status)
+	}
+
+	var stats CampaignStats
+	call(t, app, "GET", "/api/v1/admin/newsletter/campaigns/"+campaign.ID+"/stats", token, nil, &stats)
+	if stats.Sent != 2 || stats.Opens != 2 || stats.UniqueOpens != 1 || stats.UniqueClicks != 1 || stats.OpenRate != 50 || stats.ClickRate != 50 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.TopLinks) != 1 || stats.TopLinks[0].URL != "https://example.com/lekki" || stats.TopLinks[0].Clicks != 1 {
+		t.Errorf("top links = %+v", stats.TopLinks)
+	}
+}
@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/supabase-community/supabase-go"
 )
 
 var SupabaseClient *supabase.Client
 
+// DBPool is the direct Postgres connection pool used by the postgres store
+var DBPool *pgxpool.Pool
+
 // InitSupabase initializes Supabase client
 func InitSupabase() error {
 	supabaseURL := os.Getenv("SUPABASE_URL")
@@ -26,3 +38,133 @@ func InitSupabase() error {
 	SupabaseClient = client
 	return nil
 }
+
+// InitPostgres opens a connection pool to DATABASE_URL
+func InitPostgres() error {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("DATABASE_URL environment variable must be set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	if err != nil {
+		return err
+	}
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return err
+	}
+
+	DBPool = pool
+	return nil
+}
+
+// InitStore builds the Store selected by STORE_DRIVER (supabase, postgres or memory).
+// The postgres driver applies pending migrations first when DB_AUTO_MIGRATE=true.
+func InitStore() (*Store, error) {
+	switch driver := os.Getenv("STORE_DRIVER"); driver {
+	case "", "supabase":
+		if err := InitSupabase(); err != nil {
+			return nil, err
+		}
+		return NewSupabaseStore(SupabaseClient), nil
+	case "postgres":
+		if err := InitPostgres(); err != nil {
+			return nil, err
+		}
+		if os.Getenv("DB_AUTO_MIGRATE") == "true" {
+			if _, err := MigrateUp(context.Background(), DBPool); err != nil {
+				return nil, err
+			}
+		}
+		return NewPostgresStore(DBPool), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown STORE_DRIVER %q", driver)
+	}
+}
+
+// frontendURLFromEnv returns the base URL of the website for links in emails
+func frontendURLFromEnv() string {
+	if base := os.Getenv("FRONTEND_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return "http://localhost:5173"
+}
+
+// envDuration reads a duration such as "48h" or "30s" from the environment,
+// falling back when it is unset or invalid
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// secretFromEnv returns the first of keys that is set in the environment,
+// failing when none is. Secrets are read after the .env file is loaded.
+func secretFromEnv(keys ...string) ([]byte, error) {
+	for _, key := range keys {
+		if secret := os.Getenv(key); secret != "" {
+			return []byte(secret), nil
+		}
+	}
+	return nil, fmt.Errorf("%s environment variable must be set", strings.Join(keys, " or "))
+}
+
+// envInt reads a positive integer from the environment, falling back when
+// it is unset or invalid
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// EnsureAdminUser creates the admin account from ADMIN_EMAIL and ADMIN_PASSWORD
+// when both are set and no user with that email exists yet
+func EnsureAdminUser(ctx context.Context, store *Store) error {
+	email := strings.ToLower(strings.TrimSpace(os.Getenv("ADMIN_EMAIL")))
+	password := os.Getenv("ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		return nil
+	}
+
+	_, err := store.Users.GetByEmail(ctx, email)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	admin := &User{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Password:  hash,
+		FirstName: "Admin",
+		Role:      "admin",
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := store.Users.Create(ctx, admin); err != nil {
+		return err
+	}
+	log.Printf("Created admin user %s", email)
+	return nil
+}
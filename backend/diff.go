@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff line operations
+const (
+	diffEqual  = "equal"
+	diffDelete = "delete"
+	diffInsert = "insert"
+)
+
+// diffContext is the number of unchanged lines kept around each change
+const diffContext = 3
+
+// diffMaxEdits bounds the work of diffLines; the snapshots myersDiff keeps
+// grow with its square. Texts that differ by more lines are shown as the
+// changed middle removed and re-added in full.
+const diffMaxEdits = 500
+
+// splitLines splits text into lines, treating CRLF as LF. Empty text has no
+// lines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning a into b, one entry per
+// line of either text
+func diffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Op: diffEqual, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+	for _, line := range myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, DiffLine{Op: diffEqual, OldLine: len(a) - i + 1, NewLine: len(b) - i + 1, Text: a[len(a)-i]})
+	}
+	return lines
+}
+
+// myersDiff is Myers' O(ND) difference algorithm. It keeps the furthest
+// reaching x of every diagonal k after each number of edits d, then walks
+// back through those snapshots to recover the edits.
+func myersDiff(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	for d := 0; d <= max && d <= diffMaxEdits; d++ {
+		// Diagonals -d-1 .. d+1 as they were before this round
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(trace, a, b)
+			}
+		}
+	}
+
+	// Too many edits to search for the shortest script
+	lines := make([]DiffLine, 0, max)
+	for i, text := range a {
+		lines = append(lines, DiffLine{Op: diffDelete, OldLine: i + 1, Text: text})
+	}
+	for i, text := range b {
+		lines = append(lines, DiffLine{Op: diffInsert, NewLine: i + 1, Text: text})
+	}
+	return lines
+}
+
+// myersBacktrack recovers the edit script from the snapshots of myersDiff
+func myersBacktrack(trace [][]int, a, b []string) []DiffLine {
+	var reversed []DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		if d == 0 {
+			prevX, prevY = 0, 0
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, DiffLine{Op: diffEqual, OldLine: x + 1, NewLine: y + 1, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, DiffLine{Op: diffInsert, NewLine: prevY + 1, Text: b[prevY]})
+			} else {
+				reversed = append(reversed, DiffLine{Op: diffDelete, OldLine: prevX + 1, Text: a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// diffHunks groups the changes of an edit script into hunks, merging
+// changes that are close enough for their context to overlap
+func diffHunks(lines []DiffLine) []DiffHunk {
+	hunks := []DiffHunk{}
+	for i := 0; i < len(lines); {
+		if lines[i].Op == diffEqual {
+			i++
+			continue
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != diffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == diffEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContext {
+				end += diffContext
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = run
+		}
+
+		hunks = append(hunks, newDiffHunk(lines[start:end], lines[:start]))
+		i = end
+	}
+	return hunks
+}
+
+// newDiffHunk builds a hunk from its lines; before are the lines of the
+// edit script ahead of it, which give its start positions
+func newDiffHunk(lines, before []DiffLine) DiffHunk {
+	hunk := DiffHunk{Lines: lines}
+	for _, line := range before {
+		if line.Op != diffInsert {
+			hunk.OldStart++
+		}
+		if line.Op != diffDelete {
+			hunk.NewStart++
+		}
+	}
+	for _, line := range lines {
+		if line.Op != diffInsert {
+			hunk.OldLines++
+		}
+		if line.Op != diffDelete {
+			hunk.NewLines++
+		}
+	}
+	// Like diff -u, an empty side starts at the line before the hunk
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+	return hunk
+}
+
+// unifiedDiff writes the hunks of a field in unified diff format
+func unifiedDiff(b *strings.Builder, field string, from, to int, hunks []DiffHunk) {
+	fmt.Fprintf(b, "--- %s (revision %d)\n+++ %s (revision %d)\n", field, from, field, to)
+	for _, hunk := range hunks {
+		fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		for _, line := range hunk.Lines {
+			prefix := " "
+			switch line.Op {
+			case diffDelete:
+				prefix = "-"
+			case diffInsert:
+				prefix = "+"
+			}
+			b.WriteString(prefix + line.Text + "\n")
+		}
+	}
+}
+
+// diffRevisions compares every content field of two revisions
+func diffRevisions(from, to *BlogRevision) RevisionDiff {
+	diff := RevisionDiff{PostID: from.PostID, From: from.Revision, To: to.Revision, Fields: []FieldDiff{}}
+	fields := []struct{ name, old, new string }{
+		{"title", from.Title, to.Title},
+		{"excerpt", from.Excerpt, to.Excerpt},
+		{"content_markdown", from.ContentMarkdown, to.ContentMarkdown},
+		{"category", from.Category, to.Category},
+		{"tags", strings.Join(from.Tags, "\n"), strings.Join(to.Tags, "\n")},
+		{"image_url", from.ImageURL, to.ImageURL},
+		{"image_alt", from.ImageAlt, to.ImageAlt},
+		{"author", from.Author, to.Author},
+	}
+
+	var unified strings.Builder
+	for _, field := range fields {
+		if field.old == field.new {
+			continue
+		}
+		lines := diffLines(splitLines(field.old), splitLines(field.new))
+		for _, line := range lines {
+			switch line.Op {
+			case diffInsert:
+				diff.Added++
+			case diffDelete:
+				diff.Removed++
+			}
+		}
+		hunks := diffHunks(lines)
+		diff.Fields = append(diff.Fields, FieldDiff{Field: field.name, Hunks: hunks})
+		unifiedDiff(&unified, field.name, from.Revision, to.Revision, hunks)
+	}
+	diff.Unified = unified.String()
+	return diff
+}
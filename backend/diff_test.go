@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formatDiff writes an edit script one line per entry, prefixed like diff -u
+func formatDiff(lines []DiffLine) string {
+	var b strings.Builder
+	for _, line := range lines {
+		switch line.Op {
+		case diffEqual:
+			fmt.Fprintf(&b, " %s %d,%d\n", line.Text, line.OldLine, line.NewLine)
+		case diffDelete:
+			fmt.Fprintf(&b, "-%s %d\n", line.Text, line.OldLine)
+		case diffInsert:
+			fmt.Fprintf(&b, "+%s %d\n", line.Text, line.NewLine)
+		}
+	}
+	return b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"both empty", "", "", ""},
+		{"identical", "a\nb", "a\nb", " a 1,1\n b 2,2\n"},
+		{"insert into empty", "", "a\nb", "+a 1\n+b 2\n"},
+		{"delete all", "a\nb", "", "-a 1\n-b 2\n"},
+		{"change middle", "a\nb\nc", "a\nx\nc", " a 1,1\n-b 2\n+x 2\n c 3,3\n"},
+		{"insert at start", "b\nc", "a\nb\nc", "+a 1\n b 1,2\n c 2,3\n"},
+		{"delete at end", "a\nb\nc", "a\nb", " a 1,1\n b 2,2\n-c 3\n"},
+		{"CRLF is LF", "a\r\nb", "a\nb", " a 1,1\n b 2,2\n"},
+		{
+			"Myers example",
+			"A\nB\nC\nA\nB\nB\nA", "C\nB\nA\nB\nA\nC",
+			"-A 1\n-B 2\n C 3,1\n+B 2\n A 4,3\n B 5,4\n-B 6\n A 7,5\n+C 6\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDiff(diffLines(splitLines(tt.a), splitLines(tt.b)))
+			if got != tt.want {
+				t.Errorf("diffLines(%q, %q) =\n%s\nwant\n%s", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesApplies checks that every script turns a into b with the
+// fewest changes
+func TestDiffLinesApplies(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"a\nb\nc\nd\ne\nf", "a\nc\nd\nx\nf\ny", 4},
+		{"x\nx\nx", "x\ny\nx\ny\nx", 2},
+		{"1\n2\n3\n4\n5", "5\n4\n3\n2\n1", 8},
+		{"p\nq", "q\np", 2},
+	}
+	for _, tt := range tests {
+		a, b := splitLines(tt.a), splitLines(tt.b)
+		lines := diffLines(a, b)
+		var oldSide, newSide []string
+		edits := 0
+		for _, line := range lines {
+			if line.Op != diffInsert {
+				oldSide = append(oldSide, line.Text)
+			}
+			if line.Op != diffDelete {
+				newSide = append(newSide, line.Text)
+			}
+			if line.Op != diffEqual {
+				edits++
+			}
+		}
+		if strings.Join(oldSide, "\n") != tt.a || strings.Join(newSide, "\n") != tt.b {
+			t.Errorf("diffLines(%q, %q) does not reproduce both texts:\n%s", tt.a, tt.b, formatDiff(lines))
+		}
+		if edits != tt.edits {
+			t.Errorf("diffLines(%q, %q) has %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
+
+func TestDiffLinesTooManyEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i <= diffMaxEdits; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	a = append([]string{"same"}, a...)
+	b = append([]string{"same"}, b...)
+
+	lines := diffLines(a, b)
+	if len(lines) != 1+2*(diffMaxEdits+1) {
+		t.Fatalf("got %d lines, want %d", len(lines), 1+2*(diffMaxEdits+1))
+	}
+	if lines[0].Op != diffEqual || lines[1].Op != diffDelete || lines[1].OldLine != 2 {
+		t.Errorf("script does not start with the common line then the removed middle: %+v %+v", lines[0], lines[1])
+	}
+	if last := lines[len(lines)-1]; last.Op != diffInsert || last.NewLine != len(b) {
+		t.Errorf("script does not end with the re-added middle: %+v", last)
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	numbered := func(from, to int, change map[int]string) string {
+		var lines []string
+		for i := from; i <= to; i++ {
+			if s, ok := change[i]; ok {
+				if s != "" {
+					lines = append(lines, s)
+				}
+				continue
+			}
+			lines = append(lines, fmt.Sprint(i))
+		}
+		return strings.Join(lines, "\n")
+	}
+	type hunkRange struct{ oldStart, oldLines, newStart, newLines int }
+	tests := []struct {
+		name string
+		a, b string
+		want []hunkRange
+	}{
+		{"no changes", numbered(1, 5, nil), numbered(1, 5, nil), nil},
+		{"change in the middle", numbered(1, 20, nil), numbered(1, 20, map[int]string{10: "x"}), []hunkRange{{7, 7, 7, 7}}},
+		{"change on line 1", numbered(1, 20, nil), numbered(1, 20, map[int]string{1: "x"}), []hunkRange{{1, 4, 1, 4}}},
+		{"change on the last line", numbered(1, 20, nil), numbered(1, 20, map[int]string{20: "x"}), []hunkRange{{17, 4, 17, 4}}},
+		{"insert", numbered(1, 20, nil), numbered(1, 20, map[int]string{10: "10\nnew"}), []hunkRange{{8, 6, 8, 7}}},
+		{"delete", numbered(1, 20, nil), numbered(1, 20, map[int]string{10: ""}), []hunkRange{{7, 7, 7, 6}}},
+		{
+			"close changes share a hunk",
+			numbered(1, 30, nil), numbered(1, 30, map[int]string{10: "x", 17: "y"}),
+			[]hunkRange{{7, 14, 7, 14}},
+		},
+		{
+			"distant changes get their own hunks",
+			numbered(1, 30, nil), numbered(1, 30, map[int]string{10: "x", 18: "y"}),
+			[]hunkRange{{7, 7, 7, 7}, {15, 7, 15, 7}},
+		},
+		{"everything added", "", "a\nb", []hunkRange{{0, 0, 1, 2}}},
+		{"everything removed", "a\nb", "", []hunkRange{{1, 2, 0, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks := diffHunks(diffLines(splitLines(tt.a), splitLines(tt.b)))
+			if len(hunks) != len(tt.want) {
+				t.Fatalf("got %d hunks, want %d", len(hunks), len(tt.want))
+			}
+			for i, h := range hunks {
+				got := hunkRange{h.OldStart, h.OldLines, h.NewStart, h.NewLines}
+				if got != tt.want[i] {
+					t.Errorf("hunk %d = @@ -%d,%d +%d,%d @@, want @@ -%d,%d +%d,%d @@", i,
+						got.oldStart, got.oldLines, got.newStart, got.newLines,
+						tt.want[i].oldStart, tt.want[i].oldLines, tt.want[i].newStart, tt.want[i].newLines)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	from := &BlogRevision{PostID: "p", Revision: 1, Title: "Old", ContentMarkdown: "a\nb\nc", Tags: []string{"x"}}
+	to := &BlogRevision{PostID: "p", Revision: 2, Title: "New", ContentMarkdown: "a\nB\nc", Tags: []string{"x"}}
+
+	diff := diffRevisions(from, to)
+	if diff.Added != 2 || diff.Removed != 2 {
+		t.Errorf("added %d, removed %d, want 2 and 2", diff.Added, diff.Removed)
+	}
+	var fields []string
+	for _, f := range diff.Fields {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "title,content_markdown" {
+		t.Errorf("changed fields = %s, want title,content_markdown", got)
+	}
+	want := "--- title (revision 1)\n+++ title (revision 2)\n@@ -1,1 +1,1 @@\n-Old\n+New\n" +
+		"--- content_markdown (revision 1)\n+++ content_markdown (revision 2)\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"
+	if diff.Unified != want {
+		t.Errorf("unified diff =\n%s\nwant\n%s", diff.Unified, want)
+	}
+}
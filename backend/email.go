@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/email
+var emailTemplateFiles embed.FS
+
+// emailLayoutFile holds the partials shared by every HTML email
+const emailLayoutFile = "layout.html"
+
+// EmailTemplates renders the emails in templates/email. Each email <name>
+// has an HTML body in <name>.html and a plain text body in <name>.txt, whose
+// "subject" template gives the subject line. Files in an override directory
+// replace the embedded file of the same name.
+type EmailTemplates struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+// emailFuncs are the helpers available in email templates
+var emailFuncs = map[string]interface{}{
+	// link builds the argument of the "button" partial
+	"link": func(url, label string) map[string]string {
+		return map[string]string{"URL": url, "Label": label}
+	},
+}
+
+// LoadEmailTemplates parses the embedded templates, overlaid by the files
+// in dir when it is not empty
+func LoadEmailTemplates(dir string) (*EmailTemplates, error) {
+	embedded, err := fs.Sub(emailTemplateFiles, "templates/email")
+	if err != nil {
+		return nil, err
+	}
+	sources := []fs.FS{embedded}
+	if dir != "" {
+		sources = append(sources, os.DirFS(dir))
+	}
+
+	// Later sources win, so overrides replace embedded files
+	files := map[string]string{}
+	for _, source := range sources {
+		entries, err := fs.ReadDir(source, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			body, err := fs.ReadFile(source, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			files[entry.Name()] = string(body)
+		}
+	}
+
+	t := &EmailTemplates{
+		html: map[string]*htmltemplate.Template{},
+		text: map[string]*texttemplate.Template{},
+	}
+	for filename, body := range files {
+		if filename == emailLayoutFile {
+			continue
+		}
+		switch name := strings.TrimSuffix(filename, ".html"); {
+		case strings.HasSuffix(filename, ".html"):
+			tmpl, err := htmltemplate.New(filename).Funcs(emailFuncs).Parse(body)
+			if err == nil {
+				_, err = tmpl.New(emailLayoutFile).Parse(files[emailLayoutFile])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("email template %s: %w", filename, err)
+			}
+			t.html[name] = tmpl
+		case strings.HasSuffix(filename, ".txt"):
+			tmpl, err := texttemplate.New(filename).Funcs(emailFuncs).Parse(body)
+			if err != nil {
+				return nil, fmt.Errorf("email template %s: %w", filename, err)
+			}
+			if tmpl.Lookup("subject") == nil {
+				return nil, fmt.Errorf("email template %s has no subject", filename)
+			}
+			t.text[strings.TrimSuffix(filename, ".txt")] = tmpl
+		}
+	}
+	return t, nil
+}
+
+// Render executes the email called name for data
+func (t *EmailTemplates) Render(name string, data interface{}) (Message, error) {
+	text, ok := t.text[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	msg := Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    body.String(),
+	}
+
+	if html, ok := t.html[name]; ok {
+		var buf bytes.Buffer
+		if err := html.Execute(&buf, data); err != nil {
+			return Message{}, err
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
+
+// MailService renders templated emails and queues them for delivery
+type MailService struct {
+	mailer    Mailer
+	queue     *MailQueue
+	templates *EmailTemplates
+}
+
+// InitMail sets up the mailer, queue and templates from the environment
+func InitMail() (*MailService, error) {
+	mailer, err := NewMailerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	templates, err := LoadEmailTemplates(os.Getenv("MAIL_TEMPLATE_DIR"))
+	if err != nil {
+		return nil, err
+	}
+	queue := NewMailQueue(mailer,
+		envInt("MAIL_QUEUE_SIZE", 1000),
+		envInt("MAIL_MAX_ATTEMPTS", 5),
+		envDuration("MAIL_RETRY_BACKOFF", 5*time.Second))
+	return &MailService{mailer: mailer, queue: queue, templates: templates}, nil
+}
+
+// Send renders the email called name and queues it for to. Replies go to
+// replyTo when it is set.
+func (s *MailService) Send(to, replyTo, name string, data interface{}) error {
+	msg, err := s.templates.Render(name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	msg.ReplyTo = replyTo
+	return s.queue.Enqueue(msg)
+}
+
+// Deliver sends msg straight away instead of queueing it, for callers that
+// record the outcome themselves
+func (s *MailService) Deliver(ctx context.Context, msg Message) error {
+	return s.mailer.Send(ctx, msg)
+}
+
+// sendEmail queues a templated email, logging instead of failing the
+// request when it cannot be queued
+func (h *Handler) sendEmail(to, name string, data interface{}) {
+	h.sendEmailReplyTo(to, "", name, data)
+}
+
+// sendEmailReplyTo is sendEmail with replies going to replyTo
+func (h *Handler) sendEmailReplyTo(to, replyTo, name string, data interface{}) {
+	if err := h.mail.Send(to, replyTo, name, data); err != nil {
+		log.Printf("Could not send %s email to %s: %v", name, to, err)
+	}
+}
+
+// emailThrottleMaxKeys is how many keys an emailThrottle holds before it
+// forgets the ones whose interval has passed
+const emailThrottleMaxKeys = 10000
+
+// emailThrottle remembers when automatic emails were last triggered for each
+// key, such as a recipient address or a client IP, so public forms cannot be
+// used to flood an inbox
+type emailThrottle struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// newEmailThrottle allows one email per key every interval
+func newEmailThrottle(interval time.Duration) *emailThrottle {
+	return &emailThrottle{interval: interval, last: map[string]time.Time{}}
+}
+
+// allow reports whether none of keys triggered an email within the interval
+// before now, recording now for all of them when it did not
+func (t *emailThrottle) allow(now time.Time, keys ...string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range keys {
+		if last, ok := t.last[key]; ok && now.Sub(last) < t.interval {
+			return false
+		}
+	}
+	if len(t.last) >= emailThrottleMaxKeys {
+		for key, last := range t.last {
+			if now.Sub(last) >= t.interval {
+				delete(t.last, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		t.last[key] = now
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	override := `{{define "subject"}}Brochure: {{.PropertyTitle}}{{end}}See {{.URL}}`
+	if err := os.WriteFile(filepath.Join(dir, "brochure.txt"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadEmailTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := templates.Render("brochure", struct {
+		PropertyTitle string
+		DocumentLink
+	}{"Unity Estate", DocumentLink{URL: "https://example.com/b?x=1&y=2", ExpiresAt: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Brochure: Unity Estate" || msg.Text != "See https://example.com/b?x=1&y=2" {
+		t.Errorf("override rendered %q / %q", msg.Subject, msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "https://example.com/b?x=1&amp;y=2") {
+		t.Error("the embedded HTML body is not used or not escaped")
+	}
+	if _, err := templates.Render("missing", nil); err == nil {
+		t.Error("rendering an unknown template succeeded")
+	}
+}
+
+func TestEmailThrottle(t *testing.T) {
+	throttle := newEmailThrottle(time.Hour)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if !throttle.allow(now, "email:a@example.com", "ip:1.2.3.4") {
+		t.Fatal("first email was throttled")
+	}
+	if throttle.allow(now.Add(time.Minute), "email:a@example.com", "ip:5.6.7.8") {
+		t.Error("same address from another IP was allowed")
+	}
+	if throttle.allow(now.Add(time.Minute), "email:b@example.com", "ip:1.2.3.4") {
+		t.Error("another address from the same IP was allowed")
+	}
+	if !throttle.allow(now.Add(time.Minute), "email:c@example.com", "ip:9.9.9.9") {
+		t.Error("an unrelated address and IP were throttled")
+	}
+	if !throttle.allow(now.Add(time.Hour), "email:a@example.com", "ip:1.2.3.4") {
+		t.Error("the address was still throttled after the interval")
+	}
+}
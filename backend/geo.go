@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean Earth radius used for distance calculations
+const earthRadiusKm = 6371.0
+
+// GeoPoint is a WGS84 coordinate
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+// BoundingBox is a latitude/longitude rectangle. MinLng may exceed MaxLng
+// when the box crosses the antimeridian.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// Contains reports whether the coordinate lies inside the box
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLng <= b.MaxLng {
+		return lng >= b.MinLng && lng <= b.MaxLng
+	}
+	return lng >= b.MinLng || lng <= b.MaxLng
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometres
+func HaversineKm(a, b GeoPoint) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// boundingBoxAround returns a box that contains every point within radiusKm
+// of center, used to narrow a radius search before the exact distance check
+func boundingBoxAround(center GeoPoint, radiusKm float64) BoundingBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := BoundingBox{
+		MinLat: math.Max(-90, center.Lat-dLat),
+		MaxLat: math.Min(90, center.Lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// Near the poles every longitude can be within range
+	cosLat := math.Cos(center.Lat * math.Pi / 180)
+	if box.MinLat > -90 && box.MaxLat < 90 && cosLat > 0 {
+		dLng := dLat / cosLat
+		if dLng < 180 {
+			box.MinLng = math.Mod(center.Lng-dLng+540, 360) - 180
+			box.MaxLng = math.Mod(center.Lng+dLng+540, 360) - 180
+		}
+	}
+	return box
+}
+
+// parseCoordinates parses a comma-separated list of exactly n numbers
+func parseCoordinates(s string, n int) ([]float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// isGeoJSONPolygon reports whether raw is a GeoJSON Polygon or MultiPolygon
+// geometry whose rings are closed and have at least four positions
+func isGeoJSONPolygon(raw []byte) bool {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return false
+	}
+
+	var polygons [][][][]float64
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygon); err != nil {
+			return false
+		}
+		polygons = append(polygons, polygon)
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if len(polygons) == 0 {
+		return false
+	}
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return false
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return false
+			}
+			for _, position := range ring {
+				if len(position) < 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+					return false
+				}
+			}
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				return false
+			}
+		}
+	}
+	return true
+}
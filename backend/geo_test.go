@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHaversineKm(t *testing.T) {
+	london := GeoPoint{Lat: 51.5074, Lng: -0.1278}
+	paris := GeoPoint{Lat: 48.8566, Lng: 2.3522}
+	if d := HaversineKm(london, paris); math.Abs(d-343.5) > 1 {
+		t.Errorf("London to Paris = %.1f km, want about 343.5", d)
+	}
+	if d := HaversineKm(paris, paris); d != 0 {
+		t.Errorf("distance to itself = %v", d)
+	}
+	// Half way round the equator
+	if d := HaversineKm(GeoPoint{0, 0}, GeoPoint{0, 180}); math.Abs(d-math.Pi*earthRadiusKm) > 0.001 {
+		t.Errorf("antipodes = %.3f km", d)
+	}
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	// A radius around Fiji crosses the antimeridian, so the box wraps
+	fiji := GeoPoint{Lat: -17.7, Lng: 179.9}
+	box := boundingBoxAround(fiji, 50)
+	if box.MinLng <= box.MaxLng {
+		t.Fatalf("box %+v does not wrap the antimeridian", box)
+	}
+	for _, p := range []GeoPoint{{-17.7, -179.9}, {-17.5, 179.5}} {
+		if HaversineKm(fiji, p) <= 50 && !box.Contains(p.Lat, p.Lng) {
+			t.Errorf("%+v is within 50 km but outside %+v", p, box)
+		}
+	}
+	if box.Contains(-17.7, 0) {
+		t.Error("a wrapped box contains the prime meridian")
+	}
+
+	// Close to a pole every longitude is in range
+	if polar := boundingBoxAround(GeoPoint{Lat: 89.9, Lng: 10}, 100); polar.MinLng != -180 || polar.MaxLng != 180 || polar.MaxLat != 90 {
+		t.Errorf("polar box = %+v", polar)
+	}
+}
+
+func TestIsGeoJSONPolygon(t *testing.T) {
+	tests := []struct {
+		geometry string
+		want     bool
+	}{
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`, true},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`, true},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[181,0],[1,1],[0,0]]]}`, false},
+		{`{"type":"Polygon","coordinates":[]}`, false},
+		{`{"type":"Point","coordinates":[0,0]}`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := isGeoJSONPolygon([]byte(tt.geometry)); got != tt.want {
+			t.Errorf("isGeoJSONPolygon(%s) = %v, want %v", tt.geometry, got, tt.want)
+		}
+	}
+}
+
+func TestGeoSearch(t *testing.T) {
+	app, h := newTestApp(t)
+	place := func(id string, lat, lng float64) {
+		p := Property{ID: id, Title: id, Slug: id, Latitude: &lat, Longitude: &lng, CreatedAt: time.Now()}
+		if err := h.store.Properties.Create(context.Background(), &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	place("lekki", 6.4474, 3.4723)
+	place("ikoyi", 6.4541, 3.4346)
+	place("abuja", 9.0765, 7.3986)
+	if err := h.store.Properties.Create(context.Background(), &Property{ID: "unmapped", Title: "unmapped", Slug: "unmapped"}); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(query string) (int, []string) {
+		var resp struct {
+			Data []Property `json:"data"`
+		}
+		status := call(t, app, "GET", "/api/v1/properties?"+query, "", nil, &resp)
+		ids := []string{}
+		for _, p := range resp.Data {
+			ids = append(ids, p.ID)
+		}
+		sort.Strings(ids)
+		return status, ids
+	}
+
+	if _, ids := search("near=6.45,3.45&radius_km=10"); len(ids) != 2 || ids[0] != "ikoyi" || ids[1] != "lekki" {
+		t.Errorf("10 km around Lagos = %v, want [ikoyi lekki]", ids)
+	}
+	if _, ids := search("near=6.45,3.45&radius_km=1000"); len(ids) != 3 {
+		t.Errorf("1000 km around Lagos = %v, want every mapped property", ids)
+	}
+	if _, ids := search("bbox=7,8,8,10"); len(ids) != 1 || ids[0] != "abuja" {
+		t.Errorf("bbox around Abuja = %v", ids)
+	}
+
+	for _, bad := range []string{"near=6.45", "near=95,3.45&radius_km=5", "near=6.45,3.45", "bbox=8,10,7", "bbox=7,10,8,8", "near=6.45,3.45&radius_km=-1"} {
+		if status, _ := search(bad); status != fiber.StatusUnprocessableEntity {
+			t.Errorf("%s: status %d, want 422", bad, status)
+		}
+	}
+}
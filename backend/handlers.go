@@ -1,691 +1,3518 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"log"
 	"math"
+	"net/url"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/google/uuid"
 )
 
+// Handler serves the API endpoints on top of a Store
+type Handler struct {
+	store *Store
+	mail  *MailService
+	// adminEmail receives enquiries that no property agent handles
+	adminEmail string
+	// frontendURL is where links in emails to people point
+	frontendURL string
+	// reservationHold is how long a reservation keeps its plots
+	reservationHold time.Duration
+	// jwtSecret signs access and refresh tokens
+	jwtSecret []byte
+	// signingKey signs download links; publicURL is where those links point
+	signingKey []byte
+	publicURL  string
+	// documentLinkTTL is how long a signed document link stays valid
+	documentLinkTTL time.Duration
+	// brochureLinkTTL is how long the link returned for a brochure request works
+	brochureLinkTTL time.Duration
+	// newsletterConfirmTTL is how long a subscription confirmation link works;
+	// no new confirmation is sent within newsletterResendInterval of the last
+	newsletterConfirmTTL     time.Duration
+	newsletterResendInterval time.Duration
+	// contactAcks limits acknowledgements of the public contact form per
+	// address and client IP
+	contactAcks *emailThrottle
+	// campaignBatchSize is how many recipients a campaign is sent to per run;
+	// a recipient is given up on after campaignMaxAttempts failed sends
+	campaignBatchSize   int
+	campaignMaxAttempts int
+}
+
+// NewHandler creates a Handler backed by the given store and mail service.
+// It fails when a required secret is missing from the environment.
+func NewHandler(store *Store, mail *MailService) (*Handler, error) {
+	jwtSecret, err := jwtSecretFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := signingKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		store:                    store,
+		mail:                     mail,
+		adminEmail:               os.Getenv("ADMIN_EMAIL"),
+		frontendURL:              frontendURLFromEnv(),
+		reservationHold:          envDuration("RESERVATION_HOLD", 48*time.Hour),
+		jwtSecret:                jwtSecret,
+		signingKey:               signingKey,
+		publicURL:                publicURLFromEnv(),
+		documentLinkTTL:          envDuration("DOCUMENT_LINK_TTL", 24*time.Hour),
+		brochureLinkTTL:          envDuration("BROCHURE_LINK_TTL", 72*time.Hour),
+		newsletterConfirmTTL:     envDuration("NEWSLETTER_CONFIRM_TTL", 7*24*time.Hour),
+		newsletterResendInterval: envDuration("NEWSLETTER_RESEND_INTERVAL", time.Hour),
+		contactAcks:              newEmailThrottle(envDuration("CONTACT_ACK_INTERVAL", time.Hour)),
+		campaignBatchSize:        envInt("CAMPAIGN_BATCH_SIZE", 50),
+		campaignMaxAttempts:      envInt("CAMPAIGN_MAX_ATTEMPTS", 3),
+	}, nil
+}
+
+// ============ RESPONSE HELPERS ============
+
+// errorJSON writes an ErrorResponse using the standard status text
+func errorJSON(c *fiber.Ctx, status int, message string) error {
+	return c.Status(status).JSON(ErrorResponse{
+		Error:   utils.StatusMessage(status),
+		Message: message,
+		Code:    status,
+	})
+}
+
+// storeError translates a repository error into an HTTP error response
+func storeError(c *fiber.Ctx, err error, notFound string) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return errorJSON(c, fiber.StatusNotFound, notFound)
+	case errors.Is(err, ErrConflict):
+		return errorJSON(c, fiber.StatusConflict, "Record already exists")
+	}
+	log.Printf("store error on %s %s: %v", c.Method(), c.Path(), err)
+	return errorJSON(c, fiber.StatusInternalServerError, "Internal server error")
+}
+
+// paginationFromQuery reads page and limit query parameters
+func paginationFromQuery(c *fiber.Ctx, defaultLimit int) PaginationParams {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = defaultLimit
+	}
+	return PaginationParams{Page: page, Limit: limit}
+}
+
+// newListResponse wraps a page of results with pagination metadata
+func newListResponse(data interface{}, page PaginationParams, total int) ListResponse {
+	totalPages := 0
+	if page.Limit > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(page.Limit)))
+	}
+	return ListResponse{
+		Data:       data,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
 // ============ AUTHENTICATION HANDLERS ============
 
-// LoginAdmin authenticates admin user
-func LoginAdmin(c *fiber.Ctx) error {
+// errInvalidCredentials is returned when the email or password does not match
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// errAccountDisabled is returned when the account has been deactivated
+var errAccountDisabled = errors.New("account is disabled")
+
+// dummyPasswordHash is compared against when no user matches the email, so
+// unknown accounts take as long to reject as wrong passwords
+var dummyPasswordHash, _ = HashPassword("haven-communities-dummy-password")
+
+// authenticate looks up a user by email and verifies the password
+func (h *Handler) authenticate(c *fiber.Ctx, req LoginRequest) (*User, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	user, err := h.store.Users.GetByEmail(c.UserContext(), email)
+	if errors.Is(err, ErrNotFound) {
+		CheckPassword(req.Password, dummyPasswordHash)
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Password == "" || !CheckPassword(req.Password, user.Password) {
+		return nil, errInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, errAccountDisabled
+	}
+	return user, nil
+}
+
+// login authenticates the request body and issues tokens, optionally
+// restricted to admin accounts
+func (h *Handler) login(c *fiber.Ctx, adminOnly bool) error {
 	var req LoginRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// Validate input
-	if req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Email and password are required",
-			Code:    fiber.StatusBadRequest,
-		})
+	user, err := h.authenticate(c, req)
+	switch {
+	case errors.Is(err, errInvalidCredentials):
+		return errorJSON(c, fiber.StatusUnauthorized, "Invalid email or password")
+	case errors.Is(err, errAccountDisabled):
+		return errorJSON(c, fiber.StatusForbidden, "Account is disabled")
+	case err != nil:
+		return storeError(c, err, "")
 	}
 
-	// TODO: Query user from Supabase
-	// user, err := GetUserByEmail(req.Email)
-	// if err != nil || user == nil {
-	//   return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{...})
-	// }
+	if adminOnly && user.Role != "admin" {
+		return errorJSON(c, fiber.StatusForbidden, "Admin access required")
+	}
 
-	// TODO: Verify password
-	// if !CheckPassword(req.Password, user.Password) {
-	//   return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{...})
-	// }
+	return h.startSession(c, user)
+}
 
-	// Mock implementation for testing
-	user := &User{
-		ID:    "admin-001",
-		Email: req.Email,
-		Role:  "admin",
+// startSession opens a new refresh token family for user and responds with its tokens
+func (h *Handler) startSession(c *fiber.Ctx, user *User) error {
+	now := time.Now()
+	session := &Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		RefreshJTI: uuid.New().String(),
+		ExpiresAt:  now.Add(refreshTokenTTL),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := h.store.Sessions.Create(c.UserContext(), session); err != nil {
+		return storeError(c, err, "")
 	}
 
-	accessToken, err := GenerateToken(user, 24*time.Hour)
+	return h.sessionTokens(c, user, session)
+}
+
+// sessionTokens responds with an access token and the current refresh token of session
+func (h *Handler) sessionTokens(c *fiber.Ctx, user *User, session *Session) error {
+	accessToken, err := h.GenerateAccessToken(user, session.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to generate token",
-			Code:    fiber.StatusInternalServerError,
-		})
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to generate token")
 	}
 
-	refreshToken, err := GenerateToken(user, 7*24*time.Hour)
+	refreshToken, err := h.GenerateRefreshToken(user, session)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to generate refresh token",
-			Code:    fiber.StatusInternalServerError,
-		})
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to generate refresh token")
 	}
 
 	return c.JSON(AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    86400, // 24 hours in seconds
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
 		User:         *user,
 	})
 }
 
+// LoginAdmin authenticates an admin user
+func (h *Handler) LoginAdmin(c *fiber.Ctx) error {
+	return h.login(c, true)
+}
+
+// LoginUser authenticates any active user, issuing a token with their own role
+func (h *Handler) LoginUser(c *fiber.Ctx) error {
+	return h.login(c, false)
+}
+
 // SignupUser registers a new user
-func SignupUser(c *fiber.Ctx) error {
+func (h *Handler) SignupUser(c *fiber.Ctx) error {
 	var req SignupRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// TODO: Validate user doesn't exist
-	// TODO: Hash password
-	// TODO: Save to Supabase
-	// TODO: Send verification email
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to hash password")
+	}
 
 	user := &User{
 		ID:        uuid.New().String(),
 		Email:     req.Email,
+		Password:  hash,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Role:      "user",
 		IsActive:  true,
 		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
-	token, _ := GenerateToken(user, 24*time.Hour)
+	err = h.store.Users.Create(c.UserContext(), user)
+	if errors.Is(err, ErrConflict) {
+		return errorJSON(c, fiber.StatusConflict, "An account with this email already exists")
+	}
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
-	return c.Status(fiber.StatusCreated).JSON(AuthResponse{
-		AccessToken: token,
-		ExpiresIn:   86400,
-		User:        *user,
-	})
+	h.sendEmail(user.Email, "welcome", struct {
+		FirstName string
+		Email     string
+		SiteURL   string
+	}{user.FirstName, user.Email, h.frontendURL + "/projects"})
+
+	c.Status(fiber.StatusCreated)
+	return h.startSession(c, user)
 }
 
-// RefreshToken generates new access token
-func RefreshToken(c *fiber.Ctx) error {
+// RefreshToken rotates a refresh token, issuing a new access and refresh token.
+// Presenting a refresh token that was already rotated revokes its whole session.
+func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 	type RefreshRequest struct {
-		RefreshToken string `json:"refresh_token"`
+		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
 	var req RefreshRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	claims, err := h.VerifyToken(req.RefreshToken)
+	if err != nil || claims.Type != tokenTypeRefresh {
+		return errorJSON(c, fiber.StatusUnauthorized, "Invalid refresh token")
 	}
 
-	claims, err := VerifyToken(req.RefreshToken)
+	ctx := c.UserContext()
+	session, err := h.store.Sessions.GetByID(ctx, claims.SessionID)
+	if errors.Is(err, ErrNotFound) {
+		return errorJSON(c, fiber.StatusUnauthorized, "Invalid refresh token")
+	}
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
-			Error:   "Unauthorized",
-			Message: "Invalid refresh token",
-			Code:    fiber.StatusUnauthorized,
-		})
+		return storeError(c, err, "")
+	}
+	if !session.Active(time.Now()) {
+		return errorJSON(c, fiber.StatusUnauthorized, "Session has been revoked")
 	}
 
-	user := &User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-		Role:  claims.Role,
+	newJTI := uuid.New().String()
+	expiresAt := time.Now().Add(refreshTokenTTL)
+	err = h.store.Sessions.Rotate(ctx, session.ID, claims.ID, newJTI, expiresAt)
+	if errors.Is(err, ErrNotFound) {
+		if err := h.store.Sessions.Revoke(ctx, session.ID); err != nil {
+			return storeError(c, err, "")
+		}
+		log.Printf("refresh token reuse detected for user %s, session %s revoked", session.UserID, session.ID)
+		return errorJSON(c, fiber.StatusUnauthorized, "Refresh token has already been used")
+	}
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	user, err := h.store.Users.GetByID(ctx, session.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return errorJSON(c, fiber.StatusUnauthorized, "Invalid refresh token")
+	}
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	if !user.IsActive {
+		if err := h.store.Sessions.Revoke(ctx, session.ID); err != nil {
+			return storeError(c, err, "")
+		}
+		return errorJSON(c, fiber.StatusForbidden, "Account is disabled")
 	}
 
-	accessToken, _ := GenerateToken(user, 24*time.Hour)
+	session.RefreshJTI = newJTI
+	session.ExpiresAt = expiresAt
+	return h.sessionTokens(c, user, session)
+}
 
-	return c.JSON(fiber.Map{
-		"access_token": accessToken,
-		"expires_in":   86400,
+// Logout revokes the session of the current access token
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	if err := h.store.Sessions.Revoke(c.UserContext(), GetSessionFromContext(c)); err != nil {
+		return storeError(c, err, "")
+	}
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Logged out successfully",
 	})
 }
 
-// ============ PROPERTY HANDLERS ============
+// ============ SLUG HELPERS ============
 
-// GetProperties returns paginated list of properties
-func GetProperties(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	if page < 1 {
-		page = 1
+// slugRedirect returns the redirect from the old slug of a renamed entity,
+// or nil when the slug did not change
+func slugRedirect(entityType, id, oldSlug, newSlug string) *SlugRedirect {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
-	}
-
-	// TODO: Query from Supabase
-	// properties, total, err := GetPropertiesFromDB(page, limit)
-
-	// Mock data
-	properties := []Property{
-		{
-			ID:       "prop-001",
-			Title:    "Modern Apartment",
-			Slug:     "modern-apartment",
-			Location: "Downtown",
-			Price:    350000,
-			Status:   "available",
-			Units:    1,
-			Acres:    0.25,
-			ImageURL: "https://example.com/apt.jpg",
-		},
-		{
-			ID:       "prop-002",
-			Title:    "Luxury Villa",
-			Slug:     "luxury-villa",
-			Location: "Riverside",
-			Price:    850000,
-			Status:   "available",
-			Units:    5,
-			Acres:    2.5,
-			ImageURL: "https://example.com/villa.jpg",
-		},
+	return &SlugRedirect{
+		ID:         uuid.New().String(),
+		EntityType: entityType,
+		OldSlug:    oldSlug,
+		EntityID:   id,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// redirectSlug answers a lookup of a retired slug with a 301 pointing at the
+// entity's current slug, or 404 when the slug was never used
+func (h *Handler) redirectSlug(c *fiber.Ctx, entityType, slug, notFound string) error {
+	ctx := c.UserContext()
+	redirect, err := h.store.SlugRedirects.Get(ctx, entityType, slug)
+	if err != nil {
+		return storeError(c, err, notFound)
 	}
 
-	total := len(properties)
-	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	var current string
+	switch entityType {
+	case slugEntityProperty:
+		var p *Property
+		if p, err = h.store.Properties.GetByID(ctx, redirect.EntityID); err == nil {
+			current = p.Slug
+		}
+	case slugEntityBlogPost:
+		var post *BlogPost
+		if post, err = h.store.Blog.GetByID(ctx, redirect.EntityID); err == nil && post.Status != BlogPublished {
+			err = ErrNotFound
+		} else if err == nil {
+			current = post.Slug
+		}
+	}
+	if err != nil {
+		return storeError(c, err, notFound)
+	}
 
-	return c.JSON(ListResponse{
-		Data:       properties,
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
+	location := strings.TrimSuffix(c.Path(), slug) + current
+	c.Location(location)
+	return c.Status(fiber.StatusMovedPermanently).JSON(SlugRedirectResponse{
+		Redirect: true,
+		Slug:     current,
+		Location: location,
 	})
 }
 
+// ============ PROPERTY HANDLERS ============
+
+// GetProperties returns a filtered, sorted and paginated list of properties
+func (h *Handler) GetProperties(c *fiber.Ctx) error {
+	var req PropertySearchRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid search parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	page := paginationFromQuery(c, 10)
+	filter := PropertyFilter{
+		PaginationParams: page,
+		Status:           req.Status,
+		Location:         strings.TrimSpace(req.Location),
+		MinPrice:         req.MinPrice,
+		MaxPrice:         req.MaxPrice,
+		MinUnits:         req.MinUnits,
+		MaxUnits:         req.MaxUnits,
+		MinAcres:         req.MinAcres,
+		MaxAcres:         req.MaxAcres,
+		Query:            strings.TrimSpace(req.Q),
+		SortBy:           req.Sort,
+		SortAsc:          req.Order == "asc",
+	}
+	for _, feature := range strings.Split(req.Features, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			filter.Features = append(filter.Features, feature)
+		}
+	}
+
+	var fields []FieldError
+	if req.Near != "" {
+		point, ok := parseCoordinates(req.Near, 2)
+		switch {
+		case !ok || point[0] < -90 || point[0] > 90 || point[1] < -180 || point[1] > 180:
+			fields = append(fields, FieldError{Field: "near", Rule: "latlng", Message: "must be latitude,longitude"})
+		case req.RadiusKm == nil:
+			fields = append(fields, FieldError{Field: "radius_km", Rule: "required_with", Param: "near", Message: "is required with near"})
+		default:
+			filter.Near = &GeoPoint{Lat: point[0], Lng: point[1]}
+			filter.RadiusKm = *req.RadiusKm
+		}
+	}
+	if req.BBox != "" {
+		box, ok := parseCoordinates(req.BBox, 4)
+		if !ok || box[1] > box[3] || box[1] < -90 || box[3] > 90 || box[0] < -180 || box[2] > 180 {
+			fields = append(fields, FieldError{Field: "bbox", Rule: "bbox", Message: "must be min_lng,min_lat,max_lng,max_lat"})
+		} else {
+			filter.BBox = &BoundingBox{MinLng: box[0], MinLat: box[1], MaxLng: box[2], MaxLat: box[3]}
+		}
+	}
+	if fields != nil {
+		return validationError(c, fields)
+	}
+
+	properties, total, err := h.store.Properties.List(c.UserContext(), filter)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(properties, page, total))
+}
+
 // GetPropertyByID returns a property by ID
-func GetPropertyByID(c *fiber.Ctx) error {
+func (h *Handler) GetPropertyByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Property ID is required",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Property ID is required")
 	}
 
-	// TODO: Query from Supabase
-	// property, err := GetPropertyFromDB(id)
-
-	property := &Property{
-		ID:          "prop-001",
-		Title:       "Modern Apartment",
-		Slug:        "modern-apartment",
-		Description: "Beautiful modern apartment in downtown area",
-		Location:    "Downtown",
-		Price:       350000,
-		Status:      "available",
-		Units:       1,
-		Acres:       0.25,
-		ImageURL:    "https://example.com/apt.jpg",
-		CreatedAt:   time.Now(),
+	property, err := h.store.Properties.GetByID(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "Property not found")
 	}
 
 	return c.JSON(property)
 }
 
 // GetPropertyBySlug returns a property by slug
-func GetPropertyBySlug(c *fiber.Ctx) error {
+func (h *Handler) GetPropertyBySlug(c *fiber.Ctx) error {
 	slug := c.Params("slug")
 	if slug == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Slug is required",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Slug is required")
 	}
 
-	// TODO: Query from Supabase
-	property := &Property{
-		ID:    "prop-001",
-		Title: "Modern Apartment",
-		Slug:  slug,
+	property, err := h.store.Properties.GetBySlug(c.UserContext(), slug)
+	if errors.Is(err, ErrNotFound) {
+		return h.redirectSlug(c, slugEntityProperty, slug, "Property not found")
+	}
+	if err != nil {
+		return storeError(c, err, "Property not found")
 	}
 
 	return c.JSON(property)
 }
 
 // CreateProperty creates a new property (admin only)
-func CreateProperty(c *fiber.Ctx) error {
+func (h *Handler) CreateProperty(c *fiber.Ctx) error {
 	var property Property
 	if err := c.BodyParser(&property); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid property data",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid property data")
+	}
+	if fields := validateRequest(&property); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateAgent(c.UserContext(), property.AgentID); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
 	}
-
-	// TODO: Validate input
-	// TODO: Generate slug from title
-	// TODO: Save to Supabase
 
 	property.ID = uuid.New().String()
+	slug, err := h.assignSlug(c.UserContext(), slugEntityProperty, property.ID, property.Title)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	property.Slug = slug
 	property.CreatedAt = time.Now()
 	property.UpdatedAt = time.Now()
 
+	if err := h.store.Properties.Create(c.UserContext(), &property); err != nil {
+		return storeError(c, err, "")
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(property)
 }
 
-// UpdateProperty updates an existing property (admin only)
-func UpdateProperty(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var property Property
-	if err := c.BodyParser(&property); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid property data",
-			Code:    fiber.StatusBadRequest,
-		})
+// UpdateProperty updates an existing property (admin only)
+func (h *Handler) UpdateProperty(c *fiber.Ctx) error {
+	id := c.Params("id")
+	existing, err := h.store.Properties.GetByID(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	var property Property
+	if err := c.BodyParser(&property); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid property data")
+	}
+	if fields := validateRequest(&property); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateAgent(c.UserContext(), property.AgentID); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
+	}
+
+	property.ID = id
+	property.Slug = existing.Slug
+	if property.Title != existing.Title {
+		if property.Slug, err = h.assignSlug(c.UserContext(), slugEntityProperty, id, property.Title); err != nil {
+			return storeError(c, err, "")
+		}
+	}
+	property.CreatedAt = existing.CreatedAt
+	property.UpdatedAt = time.Now()
+
+	// A property with plots keeps the units and status they add up to
+	units, status, ok, err := h.plotInventory(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	if ok {
+		property.Units, property.Status = units, status
+	}
+
+	redirect := slugRedirect(slugEntityProperty, id, existing.Slug, property.Slug)
+	if err := h.store.Properties.Save(c.UserContext(), &property, redirect); err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	return c.JSON(property)
+}
+
+// DeleteProperty deletes a property (admin only)
+func (h *Handler) DeleteProperty(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.store.Properties.Delete(c.UserContext(), id); err != nil {
+		return storeError(c, err, "Property not found")
+	}
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Property deleted successfully",
+	})
+}
+
+// validateAgent checks that a property's agent is an active admin account
+func (h *Handler) validateAgent(ctx context.Context, agentID *string) ([]FieldError, error) {
+	if agentID == nil {
+		return nil, nil
+	}
+	agent, err := h.store.Users.GetByID(ctx, *agentID)
+	if errors.Is(err, ErrNotFound) || (err == nil && (agent.Role != "admin" || !agent.IsActive)) {
+		return []FieldError{{
+			Field:   "agent_id",
+			Rule:    "agent",
+			Message: "must be an active admin user",
+		}}, nil
+	}
+	return nil, err
+}
+
+// ============ PLOT HANDLERS ============
+
+// GetAvailablePlots returns the plots of a property that can still be bought
+func (h *Handler) GetAvailablePlots(c *fiber.Ctx) error {
+	return h.listPlots(c, "available")
+}
+
+// GetPropertyPlots returns every plot of a property (admin only)
+func (h *Handler) GetPropertyPlots(c *fiber.Ctx) error {
+	return h.listPlots(c, "")
+}
+
+func (h *Handler) listPlots(c *fiber.Ctx, status string) error {
+	propertyID := c.Params("id")
+	if _, err := h.store.Properties.GetByID(c.UserContext(), propertyID); err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	plots, err := h.store.Plots.ListByProperty(c.UserContext(), propertyID, status)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	sortPlots(plots)
+
+	return c.JSON(plots)
+}
+
+// CreatePlots adds a batch of plots to a property (admin only)
+func (h *Handler) CreatePlots(c *fiber.Ctx) error {
+	property, err := h.store.Properties.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	var req BulkCreatePlotsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid plot data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	now := time.Now()
+	plots := make([]Plot, len(req.Plots))
+	for i, p := range req.Plots {
+		plots[i] = Plot{
+			ID:         uuid.New().String(),
+			PropertyID: property.ID,
+			PlotNumber: strings.TrimSpace(p.PlotNumber),
+			SizeSqm:    p.SizeSqm,
+			Price:      p.Price,
+			IsCorner:   p.IsCorner,
+			Status:     "available",
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	if err := h.store.Plots.CreateBatch(c.UserContext(), plots); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return errorJSON(c, fiber.StatusConflict, "One or more plot numbers already exist for this property")
+		}
+		return storeError(c, err, "")
+	}
+	if err := h.syncPropertyInventory(c.UserContext(), property); err != nil {
+		return storeError(c, err, "")
+	}
+
+	sortPlots(plots)
+	return c.Status(fiber.StatusCreated).JSON(plots)
+}
+
+// plotReservedMessage answers a status change on a plot a reservation holds
+const plotReservedMessage = "Plot belongs to a reservation; convert or cancel the reservation instead"
+
+// UpdatePlot changes the size, price, corner flag or status of a plot (admin only)
+func (h *Handler) UpdatePlot(c *fiber.Ctx) error {
+	property, plot, err := h.propertyPlot(c.UserContext(), c.Params("id"), c.Params("plotId"))
+	if err != nil {
+		return storeError(c, err, "Plot not found")
+	}
+
+	var req UpdatePlotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid plot data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	if req.Status != nil && *req.Status != plot.Status && plot.ReservationID != nil {
+		return errorJSON(c, fiber.StatusConflict, plotReservedMessage)
+	}
+
+	if req.SizeSqm != nil {
+		plot.SizeSqm = *req.SizeSqm
+	}
+	if req.Price != nil {
+		plot.Price = *req.Price
+	}
+	if req.IsCorner != nil {
+		plot.IsCorner = *req.IsCorner
+	}
+	if req.Status != nil {
+		plot.Status = *req.Status
+	}
+	plot.UpdatedAt = time.Now()
+
+	if err := h.store.Plots.Update(c.UserContext(), plot); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return errorJSON(c, fiber.StatusConflict, plotReservedMessage)
+		}
+		return storeError(c, err, "Plot not found")
+	}
+	if err := h.syncPropertyInventory(c.UserContext(), property); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(plot)
+}
+
+// DeletePlot removes a plot from a property (admin only)
+func (h *Handler) DeletePlot(c *fiber.Ctx) error {
+	property, plot, err := h.propertyPlot(c.UserContext(), c.Params("id"), c.Params("plotId"))
+	if err != nil {
+		return storeError(c, err, "Plot not found")
+	}
+	if plot.ReservationID != nil {
+		return errorJSON(c, fiber.StatusConflict, "Plot belongs to a reservation and cannot be deleted")
+	}
+
+	if err := h.store.Plots.Delete(c.UserContext(), plot.ID); err != nil {
+		return storeError(c, err, "Plot not found")
+	}
+	if err := h.syncPropertyInventory(c.UserContext(), property); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Plot deleted successfully",
+	})
+}
+
+// propertyPlot loads a plot together with its property, returning
+// ErrNotFound when the plot does not belong to that property
+func (h *Handler) propertyPlot(ctx context.Context, propertyID, plotID string) (*Property, *Plot, error) {
+	property, err := h.store.Properties.GetByID(ctx, propertyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	plot, err := h.store.Plots.GetByID(ctx, plotID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plot.PropertyID != property.ID {
+		return nil, nil, ErrNotFound
+	}
+	return property, plot, nil
+}
+
+// plotInventory derives a property's units and status from its plots:
+// units counts the available plots, and the status is available while any
+// plot is, pending while plots are only reserved, and sold once all are. ok
+// is false for a property without plots, whose values are managed manually.
+func (h *Handler) plotInventory(ctx context.Context, propertyID string) (units int, status string, ok bool, err error) {
+	plots, err := h.store.Plots.ListByProperty(ctx, propertyID, "")
+	if err != nil || len(plots) == 0 {
+		return 0, "", false, err
+	}
+
+	available, reserved := 0, 0
+	for _, p := range plots {
+		switch p.Status {
+		case "available":
+			available++
+		case "reserved":
+			reserved++
+		}
+	}
+	status = "sold"
+	if available > 0 {
+		status = "available"
+	} else if reserved > 0 {
+		status = "pending"
+	}
+	return available, status, true, nil
+}
+
+// syncPropertyInventory stores the units and status derived from the
+// property's plots when they have changed
+func (h *Handler) syncPropertyInventory(ctx context.Context, property *Property) error {
+	units, status, ok, err := h.plotInventory(ctx, property.ID)
+	if err != nil || !ok {
+		return err
+	}
+	if property.Units == units && property.Status == status {
+		return nil
+	}
+	if err := h.store.Properties.SetInventory(ctx, property.ID, units, status); err != nil {
+		return err
+	}
+	property.Units = units
+	property.Status = status
+	return nil
+}
+
+// sortPlots orders plots by plot number, comparing digit runs numerically so
+// that plot 2 comes before plot 10
+func sortPlots(plots []Plot) {
+	sort.SliceStable(plots, func(i, j int) bool {
+		return naturalLess(plots[i].PlotNumber, plots[j].PlotNumber)
+	})
+}
+
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, ra := splitDigits(a)
+			nb, rb := splitDigits(b)
+			na, nb = strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = ra, rb
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// ============ RESERVATION HANDLERS ============
+
+// CreateReservation holds plots of a property for the current user
+func (h *Handler) CreateReservation(c *fiber.Ctx) error {
+	property, err := h.store.Properties.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	var req CreateReservationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid reservation data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	now := time.Now()
+	reservation := Reservation{
+		ID:         uuid.New().String(),
+		PropertyID: property.ID,
+		UserID:     GetUserFromContext(c),
+		PlotIDs:    req.PlotIDs,
+		Status:     "active",
+		ExpiresAt:  now.Add(h.reservationHold),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := h.store.Reservations.Create(c.UserContext(), &reservation); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return errorJSON(c, fiber.StatusConflict, "One or more plots are no longer available")
+		}
+		return storeError(c, err, "")
+	}
+	if err := h.syncPropertyInventory(c.UserContext(), property); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(reservation)
+}
+
+// GetUserReservations returns the current user's reservations
+func (h *Handler) GetUserReservations(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 20)
+
+	reservations, total, err := h.store.Reservations.List(c.UserContext(), ReservationFilter{
+		PaginationParams: page,
+		UserID:           GetUserFromContext(c),
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(reservations, page, total))
+}
+
+// GetReservations returns reservations filtered by status and property (admin only)
+func (h *Handler) GetReservations(c *fiber.Ctx) error {
+	var req ReservationListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	page := paginationFromQuery(c, 20)
+
+	reservations, total, err := h.store.Reservations.List(c.UserContext(), ReservationFilter{
+		PaginationParams: page,
+		Status:           req.Status,
+		PropertyID:       req.PropertyID,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(reservations, page, total))
+}
+
+// GetReservationByID returns a single reservation (admin only)
+func (h *Handler) GetReservationByID(c *fiber.Ctx) error {
+	reservation, err := h.store.Reservations.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Reservation not found")
+	}
+	return c.JSON(reservation)
+}
+
+// ConvertReservation turns an active hold into a sale of its plots and
+// stores the buyer's payment schedule (admin only)
+func (h *Handler) ConvertReservation(c *fiber.Ctx) error {
+	var req ConvertReservationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorJSON(c, fiber.StatusBadRequest, "Invalid conversion data")
+		}
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	reservation, err := h.store.Reservations.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Reservation not found")
+	}
+
+	plan := outrightPlan
+	if req.PaymentPlanID != "" {
+		p, err := h.store.PaymentPlans.GetByID(c.UserContext(), req.PaymentPlanID)
+		if err == nil && p.PropertyID != reservation.PropertyID {
+			err = ErrNotFound
+		}
+		if err != nil {
+			return storeError(c, err, "Payment plan not found")
+		}
+		if !p.IsActive {
+			return errorJSON(c, fiber.StatusConflict, "Payment plan is no longer offered")
+		}
+		plan = *p
+	}
+
+	basePrice, err := h.reservationPrice(c.UserContext(), reservation)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	schedule := BuildSchedule(plan, basePrice, time.Now())
+	schedule.ID = uuid.New().String()
+	schedule.UserID = reservation.UserID
+	schedule.PropertyID = reservation.PropertyID
+	schedule.ReservationID = &reservation.ID
+	schedule.CreatedAt = time.Now()
+
+	converted, err := h.convertReservation(c.UserContext(), reservation, &schedule)
+	if err != nil {
+		return reservationError(c, err)
+	}
+
+	return c.JSON(ReservationConversion{Reservation: *converted, Schedule: schedule})
+}
+
+// convertReservation sells the plots of an active reservation and stores
+// its payment schedule in one step. A hold the sweeper has not reached yet
+// is expired instead, like in endReservation.
+func (h *Handler) convertReservation(ctx context.Context, reservation *Reservation, schedule *PaymentSchedule) (*Reservation, error) {
+	if reservation.Status == "active" && !reservation.ExpiresAt.After(time.Now()) {
+		return h.endReservation(ctx, reservation, "converted")
+	}
+
+	converted, err := h.store.Reservations.Convert(ctx, reservation.ID, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.syncInventoryByID(ctx, converted.PropertyID); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// CancelReservation releases the plots of an active hold (admin only)
+func (h *Handler) CancelReservation(c *fiber.Ctx) error {
+	reservation, err := h.store.Reservations.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Reservation not found")
+	}
+
+	cancelled, err := h.endReservation(c.UserContext(), reservation, "cancelled")
+	if err != nil {
+		return reservationError(c, err)
+	}
+
+	return c.JSON(cancelled)
+}
+
+// errReservationExpired is returned when ending a hold whose time ran out
+// before the sweeper released it
+var errReservationExpired = errors.New("reservation has expired")
+
+// endReservation moves an active reservation to its final status and
+// updates the property inventory. A hold the sweeper has not reached yet is
+// expired instead, so it cannot be sold.
+func (h *Handler) endReservation(ctx context.Context, reservation *Reservation, status string) (*Reservation, error) {
+	expired := reservation.Status == "active" && !reservation.ExpiresAt.After(time.Now())
+	if expired {
+		status = "expired"
+	}
+
+	ended, err := h.store.Reservations.Complete(ctx, reservation.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.syncInventoryByID(ctx, ended.PropertyID); err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, errReservationExpired
+	}
+	return ended, nil
+}
+
+// reservationError responds to a failed endReservation
+func reservationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errReservationExpired):
+		return errorJSON(c, fiber.StatusConflict, "Reservation has expired")
+	case errors.Is(err, ErrConflict):
+		return errorJSON(c, fiber.StatusConflict, "Reservation is no longer active")
+	}
+	return storeError(c, err, "Reservation not found")
+}
+
+// reservationPrice sums the prices of the plots a reservation holds
+func (h *Handler) reservationPrice(ctx context.Context, reservation *Reservation) (float64, error) {
+	plots, err := h.store.Plots.ListByProperty(ctx, reservation.PropertyID, "")
+	if err != nil {
+		return 0, err
+	}
+	held := map[string]bool{}
+	for _, id := range reservation.PlotIDs {
+		held[id] = true
+	}
+	total := 0.0
+	for _, p := range plots {
+		if held[p.ID] {
+			total += p.Price
+		}
+	}
+	return total, nil
+}
+
+// ExpireReservations releases the plots of every reservation whose hold has
+// run out. It runs periodically in the background.
+func (h *Handler) ExpireReservations(ctx context.Context) error {
+	expired, err := h.store.Reservations.ExpireDue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	synced := map[string]bool{}
+	for _, reservation := range expired {
+		if synced[reservation.PropertyID] {
+			continue
+		}
+		synced[reservation.PropertyID] = true
+		if err := h.syncInventoryByID(ctx, reservation.PropertyID); err != nil {
+			return err
+		}
+	}
+	if len(expired) > 0 {
+		log.Printf("Expired %d reservation(s)", len(expired))
+	}
+	return nil
+}
+
+// syncInventoryByID is syncPropertyInventory for a property that may have
+// been deleted in the meantime
+func (h *Handler) syncInventoryByID(ctx context.Context, propertyID string) error {
+	property, err := h.store.Properties.GetByID(ctx, propertyID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return h.syncPropertyInventory(ctx, property)
+}
+
+// ============ PAYMENT PLAN HANDLERS ============
+
+// GetPaymentPlans returns the plans currently offered for a property
+func (h *Handler) GetPaymentPlans(c *fiber.Ctx) error {
+	return h.listPaymentPlans(c, true)
+}
+
+// GetAllPaymentPlans returns every plan of a property, including retired ones (admin only)
+func (h *Handler) GetAllPaymentPlans(c *fiber.Ctx) error {
+	return h.listPaymentPlans(c, false)
+}
+
+func (h *Handler) listPaymentPlans(c *fiber.Ctx, activeOnly bool) error {
+	propertyID := c.Params("id")
+	if _, err := h.store.Properties.GetByID(c.UserContext(), propertyID); err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	plans, err := h.store.PaymentPlans.ListByProperty(c.UserContext(), propertyID, activeOnly)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(plans)
+}
+
+// CreatePaymentPlan attaches a payment plan to a property (admin only)
+func (h *Handler) CreatePaymentPlan(c *fiber.Ctx) error {
+	property, err := h.store.Properties.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Property not found")
+	}
+
+	var req PaymentPlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid payment plan data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	plan := PaymentPlan{
+		ID:         uuid.New().String(),
+		PropertyID: property.ID,
+		IsActive:   true,
+		CreatedAt:  time.Now(),
+	}
+	applyPaymentPlanRequest(&plan, req)
+
+	if err := h.store.PaymentPlans.Create(c.UserContext(), &plan); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(plan)
+}
+
+// UpdatePaymentPlan replaces the terms of a payment plan (admin only).
+// Schedules already stored keep the terms they were created with.
+func (h *Handler) UpdatePaymentPlan(c *fiber.Ctx) error {
+	plan, err := h.propertyPaymentPlan(c.UserContext(), c.Params("id"), c.Params("planId"))
+	if err != nil {
+		return storeError(c, err, "Payment plan not found")
+	}
+
+	var req PaymentPlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid payment plan data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	applyPaymentPlanRequest(plan, req)
+	if err := h.store.PaymentPlans.Update(c.UserContext(), plan); err != nil {
+		return storeError(c, err, "Payment plan not found")
+	}
+
+	return c.JSON(plan)
+}
+
+// DeletePaymentPlan removes a payment plan (admin only)
+func (h *Handler) DeletePaymentPlan(c *fiber.Ctx) error {
+	plan, err := h.propertyPaymentPlan(c.UserContext(), c.Params("id"), c.Params("planId"))
+	if err != nil {
+		return storeError(c, err, "Payment plan not found")
+	}
+
+	if err := h.store.PaymentPlans.Delete(c.UserContext(), plan.ID); err != nil {
+		return storeError(c, err, "Payment plan not found")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Payment plan deleted successfully",
+	})
+}
+
+// GetScheduleQuote returns the installments a plot would be paid in under a
+// plan, starting today or on start_date. Nothing is stored.
+func (h *Handler) GetScheduleQuote(c *fiber.Ctx) error {
+	var req ScheduleQuoteRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	plan, err := h.propertyPaymentPlan(c.UserContext(), c.Params("id"), c.Params("planId"))
+	if err == nil && !plan.IsActive {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return storeError(c, err, "Payment plan not found")
+	}
+	plot, err := h.store.Plots.GetByID(c.UserContext(), req.PlotID)
+	if err == nil && plot.PropertyID != plan.PropertyID {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return storeError(c, err, "Plot not found")
+	}
+
+	start := time.Now()
+	if req.StartDate != "" {
+		if start, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			return validationError(c, []FieldError{{
+				Field:   "start_date",
+				Rule:    "datetime",
+				Param:   "2006-01-02",
+				Message: "must be a date formatted as YYYY-MM-DD",
+			}})
+		}
+	}
+
+	return c.JSON(BuildSchedule(*plan, plot.Price, start))
+}
+
+// GetUserSchedules returns the current user's payment schedules
+func (h *Handler) GetUserSchedules(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 20)
+
+	schedules, total, err := h.store.Schedules.List(c.UserContext(), PaymentScheduleFilter{
+		PaginationParams: page,
+		UserID:           GetUserFromContext(c),
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(schedules, page, total))
+}
+
+// GetSchedules returns stored payment schedules by buyer or property (admin only)
+func (h *Handler) GetSchedules(c *fiber.Ctx) error {
+	var req PaymentScheduleListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	page := paginationFromQuery(c, 20)
+
+	schedules, total, err := h.store.Schedules.List(c.UserContext(), PaymentScheduleFilter{
+		PaginationParams: page,
+		UserID:           req.UserID,
+		PropertyID:       req.PropertyID,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(schedules, page, total))
+}
+
+// GetScheduleByID returns a single stored payment schedule (admin only)
+func (h *Handler) GetScheduleByID(c *fiber.Ctx) error {
+	schedule, err := h.store.Schedules.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Payment schedule not found")
+	}
+	return c.JSON(schedule)
+}
+
+// propertyPaymentPlan loads a plan, returning ErrNotFound when it does not
+// belong to the property
+func (h *Handler) propertyPaymentPlan(ctx context.Context, propertyID, planID string) (*PaymentPlan, error) {
+	plan, err := h.store.PaymentPlans.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.PropertyID != propertyID {
+		return nil, ErrNotFound
+	}
+	return plan, nil
+}
+
+func applyPaymentPlanRequest(plan *PaymentPlan, req PaymentPlanRequest) {
+	plan.Name = strings.TrimSpace(req.Name)
+	plan.DepositPercent = req.DepositPercent
+	plan.Months = req.Months
+	plan.MarkupPercent = req.MarkupPercent
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+	plan.UpdatedAt = time.Now()
+}
+
+// ============ PAYMENT HANDLERS ============
+
+// RecordPayment enters a payment received against a buyer's schedule and
+// issues its receipt (admin only)
+func (h *Handler) RecordPayment(c *fiber.Ctx) error {
+	var req RecordPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid payment data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	schedule, err := h.store.Schedules.GetByID(c.UserContext(), req.ScheduleID)
+	if err != nil {
+		return storeError(c, err, "Payment schedule not found")
+	}
+	payments, err := h.store.Payments.ListBySchedule(c.UserContext(), schedule.ID)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	now := time.Now()
+	balance := ComputeBalance(*schedule, payments, now)
+	if toMinor(req.Amount) > toMinor(balance.Outstanding) {
+		outstanding := strconv.FormatFloat(balance.Outstanding, 'f', 2, 64)
+		return validationError(c, []FieldError{{
+			Field:   "amount",
+			Rule:    "lte",
+			Param:   outstanding,
+			Message: "must not exceed the outstanding balance of " + outstanding,
+		}})
+	}
+	if req.ReceivedAt.After(now) {
+		return validationError(c, []FieldError{{
+			Field:   "received_at",
+			Rule:    "past",
+			Message: "must not be in the future",
+		}})
+	}
+
+	payment := Payment{
+		ID:         uuid.New().String(),
+		ScheduleID: schedule.ID,
+		UserID:     schedule.UserID,
+		PropertyID: schedule.PropertyID,
+		Amount:     req.Amount,
+		Method:     req.Method,
+		Reference:  strings.TrimSpace(req.Reference),
+		ReceivedAt: req.ReceivedAt,
+		Status:     "recorded",
+		RecordedBy: GetUserFromContext(c),
+		CreatedAt:  now,
+	}
+	if err := h.store.Payments.Create(c.UserContext(), &payment); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return errorJSON(c, fiber.StatusConflict, "Another payment was recorded meanwhile; this one now exceeds the outstanding balance")
+		}
+		return storeError(c, err, "Payment schedule not found")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(payment)
+}
+
+// ReversePayment cancels a payment entered by mistake. It stays in the
+// ledger but no longer counts towards the balance (admin only).
+func (h *Handler) ReversePayment(c *fiber.Ctx) error {
+	var req ReversePaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid reversal data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	payment, err := h.store.Payments.Reverse(c.UserContext(), c.Params("id"), strings.TrimSpace(req.Reason), time.Now())
+	if err != nil {
+		if errors.Is(err, ErrConflict) {
+			return errorJSON(c, fiber.StatusConflict, "Payment is already reversed")
+		}
+		return storeError(c, err, "Payment not found")
+	}
+
+	return c.JSON(payment)
+}
+
+// GetPayments returns the payment ledger filtered by buyer, property or schedule (admin only)
+func (h *Handler) GetPayments(c *fiber.Ctx) error {
+	var req PaymentListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	page := paginationFromQuery(c, 20)
+
+	payments, total, err := h.store.Payments.List(c.UserContext(), PaymentFilter{
+		PaginationParams: page,
+		UserID:           req.UserID,
+		PropertyID:       req.PropertyID,
+		ScheduleID:       req.ScheduleID,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(payments, page, total))
+}
+
+// GetPaymentByID returns a single payment (admin only)
+func (h *Handler) GetPaymentByID(c *fiber.Ctx) error {
+	payment, err := h.store.Payments.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Payment not found")
+	}
+	return c.JSON(payment)
+}
+
+// GetPaymentReceipt returns the receipt of any payment (admin only)
+func (h *Handler) GetPaymentReceipt(c *fiber.Ctx) error {
+	payment, err := h.store.Payments.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Payment not found")
+	}
+	return h.sendReceipt(c, payment)
+}
+
+// GetUserReceipt returns the receipt of one of the current user's payments
+func (h *Handler) GetUserReceipt(c *fiber.Ctx) error {
+	payment, err := h.ownedPayment(c.UserContext(), c.Params("id"), GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "Payment not found")
+	}
+	return h.sendReceipt(c, payment)
+}
+
+func (h *Handler) sendReceipt(c *fiber.Ctx, payment *Payment) error {
+	receipt, err := h.buildReceipt(c.UserContext(), payment)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	return c.JSON(receipt)
+}
+
+// buildReceipt gathers what a receipt shows about a payment
+func (h *Handler) buildReceipt(ctx context.Context, payment *Payment) (*Receipt, error) {
+	schedule, err := h.store.Schedules.GetByID(ctx, payment.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := h.store.Payments.ListBySchedule(ctx, payment.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		ReceiptNumber: payment.ReceiptNumber,
+		IssuedAt:      payment.CreatedAt,
+		PlanName:      schedule.PlanName,
+		Payment:       *payment,
+		BalanceAfter:  balanceAfter(*schedule, payments, *payment),
+	}
+	if user, err := h.store.Users.GetByID(ctx, payment.UserID); err == nil {
+		receipt.BuyerName = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		receipt.BuyerEmail = user.Email
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if property, err := h.store.Properties.GetByID(ctx, payment.PropertyID); err == nil {
+		receipt.PropertyTitle = property.Title
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetUserPurchases returns the current user's schedules with their balance,
+// next due installment and payment history
+func (h *Handler) GetUserPurchases(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 20)
+
+	schedules, total, err := h.store.Schedules.List(c.UserContext(), PaymentScheduleFilter{
+		PaginationParams: page,
+		UserID:           GetUserFromContext(c),
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	now := time.Now()
+	purchases := make([]Purchase, 0, len(schedules))
+	for _, schedule := range schedules {
+		payments, err := h.store.Payments.ListBySchedule(c.UserContext(), schedule.ID)
+		if err != nil {
+			return storeError(c, err, "")
+		}
+		purchase := Purchase{
+			Schedule: schedule,
+			Balance:  ComputeBalance(schedule, payments, now),
+			Payments: payments,
+		}
+		property, err := h.store.Properties.GetByID(c.UserContext(), schedule.PropertyID)
+		if err == nil {
+			purchase.PropertyTitle = property.Title
+			purchase.PropertySlug = property.Slug
+		} else if !errors.Is(err, ErrNotFound) {
+			return storeError(c, err, "")
+		}
+		purchases = append(purchases, purchase)
+	}
+
+	return c.JSON(newListResponse(purchases, page, total))
+}
+
+// ============ DOCUMENT HANDLERS ============
+
+// Document types that can be rendered as PDF
+const (
+	documentBrochure = "brochure"
+	documentReceipt  = "receipt"
+	documentSchedule = "schedule"
+)
+
+// GetPropertyBrochurePDF downloads a property brochure (admin only)
+func (h *Handler) GetPropertyBrochurePDF(c *fiber.Ctx) error {
+	return h.sendDocument(c, documentBrochure, c.Params("id"), "")
+}
+
+// GetPaymentReceiptPDF downloads the receipt of any payment (admin only)
+func (h *Handler) GetPaymentReceiptPDF(c *fiber.Ctx) error {
+	return h.sendDocument(c, documentReceipt, c.Params("id"), "")
+}
+
+// GetSchedulePDF downloads any payment schedule (admin only)
+func (h *Handler) GetSchedulePDF(c *fiber.Ctx) error {
+	return h.sendDocument(c, documentSchedule, c.Params("id"), "")
+}
+
+// GetUserReceiptPDF downloads the receipt of one of the current user's payments
+func (h *Handler) GetUserReceiptPDF(c *fiber.Ctx) error {
+	return h.sendDocument(c, documentReceipt, c.Params("id"), GetUserFromContext(c))
+}
+
+// GetUserSchedulePDF downloads one of the current user's payment schedules
+func (h *Handler) GetUserSchedulePDF(c *fiber.Ctx) error {
+	return h.sendDocument(c, documentSchedule, c.Params("id"), GetUserFromContext(c))
+}
+
+// CreateDocumentLink returns a signed link to any document (admin only)
+func (h *Handler) CreateDocumentLink(c *fiber.Ctx) error {
+	return h.createDocumentLink(c, "")
+}
+
+// CreateUserDocumentLink returns a signed link to a brochure or to one of
+// the current user's receipts or schedules, for sharing or mobile downloads
+func (h *Handler) CreateUserDocumentLink(c *fiber.Ctx) error {
+	return h.createDocumentLink(c, GetUserFromContext(c))
+}
+
+// DownloadDocument serves a PDF through a signed link without signing in
+func (h *Handler) DownloadDocument(c *fiber.Ctx) error {
+	kind, id := c.Params("type"), c.Params("id")
+	if !h.verifySignedURL(documentPath(kind, id), c.Query("expires"), c.Query("signature"), time.Now()) {
+		return errorJSON(c, fiber.StatusForbidden, "Download link is invalid or has expired")
+	}
+	return h.sendDocument(c, kind, id, "")
+}
+
+func (h *Handler) createDocumentLink(c *fiber.Ctx, ownerID string) error {
+	var req DocumentLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	// Only link to documents the caller could download directly
+	var err error
+	switch req.Type {
+	case documentBrochure:
+		_, err = h.store.Properties.GetByID(c.UserContext(), req.ID)
+	case documentReceipt:
+		_, err = h.ownedPayment(c.UserContext(), req.ID, ownerID)
+	case documentSchedule:
+		_, err = h.ownedSchedule(c.UserContext(), req.ID, ownerID)
+	}
+	if err != nil {
+		return storeError(c, err, "Document not found")
+	}
+
+	expires := time.Now().Add(h.documentLinkTTL).Truncate(time.Second)
+	return c.Status(fiber.StatusCreated).JSON(DocumentLink{
+		URL:       h.signedURL(documentPath(req.Type, req.ID), expires),
+		ExpiresAt: expires,
+	})
+}
+
+// sendDocument renders a document and sends it as a PDF attachment. A
+// non-empty ownerID limits receipts and schedules to that buyer's own.
+func (h *Handler) sendDocument(c *fiber.Ctx, kind, id, ownerID string) error {
+	filename, data, err := h.renderDocument(c.UserContext(), kind, id, ownerID)
+	if err != nil {
+		return storeError(c, err, "Document not found")
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Send(data)
+}
+
+// renderDocument builds the PDF of a brochure, receipt or schedule
+func (h *Handler) renderDocument(ctx context.Context, kind, id, ownerID string) (string, []byte, error) {
+	var buf bytes.Buffer
+	switch kind {
+	case documentBrochure:
+		property, err := h.store.Properties.GetByID(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		plans, err := h.store.PaymentPlans.ListByProperty(ctx, id, true)
+		if err != nil {
+			return "", nil, err
+		}
+		var image []byte
+		var imageType string
+		if property.ImageURL != "" {
+			if image, imageType, err = fetchImage(ctx, property.ImageURL); err != nil {
+				log.Printf("Brochure for property %s rendered without image: %v", id, err)
+			}
+		}
+		if err := RenderBrochure(&buf, property, plans, image, imageType); err != nil {
+			return "", nil, err
+		}
+		return property.Slug + "-brochure.pdf", buf.Bytes(), nil
+
+	case documentReceipt:
+		payment, err := h.ownedPayment(ctx, id, ownerID)
+		if err != nil {
+			return "", nil, err
+		}
+		receipt, err := h.buildReceipt(ctx, payment)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := RenderReceipt(&buf, receipt); err != nil {
+			return "", nil, err
+		}
+		return "receipt-" + receipt.ReceiptNumber + ".pdf", buf.Bytes(), nil
+
+	case documentSchedule:
+		schedule, err := h.ownedSchedule(ctx, id, ownerID)
+		if err != nil {
+			return "", nil, err
+		}
+		payments, err := h.store.Payments.ListBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		var propertyTitle, buyerName string
+		if property, err := h.store.Properties.GetByID(ctx, schedule.PropertyID); err == nil {
+			propertyTitle = property.Title
+		} else if !errors.Is(err, ErrNotFound) {
+			return "", nil, err
+		}
+		if user, err := h.store.Users.GetByID(ctx, schedule.UserID); err == nil {
+			buyerName = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		} else if !errors.Is(err, ErrNotFound) {
+			return "", nil, err
+		}
+		balance := ComputeBalance(*schedule, payments, time.Now())
+		if err := RenderSchedule(&buf, schedule, balance, propertyTitle, buyerName); err != nil {
+			return "", nil, err
+		}
+		return "payment-schedule-" + schedule.ID + ".pdf", buf.Bytes(), nil
+	}
+	return "", nil, ErrNotFound
+}
+
+// ownedPayment loads a payment, returning ErrNotFound when ownerID is set
+// and the payment belongs to someone else
+func (h *Handler) ownedPayment(ctx context.Context, id, ownerID string) (*Payment, error) {
+	payment, err := h.store.Payments.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && payment.UserID != ownerID {
+		return nil, ErrNotFound
+	}
+	return payment, nil
+}
+
+// ownedSchedule loads a payment schedule, returning ErrNotFound when ownerID
+// is set and the schedule belongs to someone else
+func (h *Handler) ownedSchedule(ctx context.Context, id, ownerID string) (*PaymentSchedule, error) {
+	schedule, err := h.store.Schedules.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && schedule.UserID != ownerID {
+		return nil, ErrNotFound
+	}
+	return schedule, nil
+}
+
+// documentPath is the signed path of a document download
+func documentPath(kind, id string) string {
+	return "/documents/" + kind + "/" + id
+}
+
+// ============ BLOG HANDLERS ============
+
+// GetBlogPosts returns paginated list of published blog posts, newest first
+func (h *Handler) GetBlogPosts(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 10)
+
+	posts, total, err := h.store.Blog.List(c.UserContext(), BlogFilter{
+		PaginationParams: page,
+		PublishedOnly:    true,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(posts, page, total))
+}
+
+// GetBlogPostByID returns a published blog post by ID
+func (h *Handler) GetBlogPostByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+	post, err := h.store.Blog.GetByID(c.UserContext(), id)
+	if err == nil && post.Status != BlogPublished {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// GetBlogPostBySlug returns a published blog post by slug
+func (h *Handler) GetBlogPostBySlug(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+	post, err := h.store.Blog.GetBySlug(c.UserContext(), slug)
+	if errors.Is(err, ErrNotFound) {
+		return h.redirectSlug(c, slugEntityBlogPost, slug, "Blog post not found")
+	}
+	if err == nil && post.Status != BlogPublished {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// GetBlogByCategory returns published blog posts in a category
+func (h *Handler) GetBlogByCategory(c *fiber.Ctx) error {
+	category := c.Params("category")
+	if category == "" {
+		return errorJSON(c, fiber.StatusBadRequest, "Category is required")
+	}
+
+	page := paginationFromQuery(c, 10)
+	posts, total, err := h.store.Blog.List(c.UserContext(), BlogFilter{
+		PaginationParams: page,
+		Category:         category,
+		PublishedOnly:    true,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(posts, page, total))
+}
+
+// GetAdminBlogPosts returns blog posts in every status, optionally filtered
+// by status and category (admin only)
+func (h *Handler) GetAdminBlogPosts(c *fiber.Ctx) error {
+	var req BlogListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	page := paginationFromQuery(c, 20)
+
+	posts, total, err := h.store.Blog.List(c.UserContext(), BlogFilter{
+		PaginationParams: page,
+		Status:           req.Status,
+		Category:         req.Category,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(posts, page, total))
+}
+
+// GetAdminBlogPost returns a blog post in any status (admin only)
+func (h *Handler) GetAdminBlogPost(c *fiber.Ctx) error {
+	post, err := h.store.Blog.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// CreateBlogPost creates a new blog post from its Markdown (admin only).
+// Posts start as drafts; published: true publishes straight away and a
+// future publish_at schedules the post.
+func (h *Handler) CreateBlogPost(c *fiber.Ctx) error {
+	var post BlogPost
+	if err := c.BodyParser(&post); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid blog post data")
+	}
+	acceptLegacyContent(&post)
+	if fields := validateRequest(&post); fields != nil {
+		return validationError(c, fields)
+	}
+
+	ctx := c.UserContext()
+	now := time.Now()
+	publish, publishAt := post.Published, post.PublishAt
+	post.ID = uuid.New().String()
+	slug, err := h.assignSlug(ctx, slugEntityBlogPost, post.ID, post.Title)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	post.Slug = slug
+	if err := renderBlogPost(&post); err != nil {
+		return storeError(c, err, "")
+	}
+	post.Status = BlogDraft
+	post.Published = false
+	post.PublishAt = nil
+	post.PublishedAt = nil
+	post.AnnouncementID = nil
+	post.CreatedAt = now
+	post.UpdatedAt = now
+
+	if err := h.store.Blog.Create(ctx, &post, newBlogRevision(c, &post, nil)); err != nil {
+		return storeError(c, err, "")
+	}
+
+	if publish || publishAt != nil {
+		at := now
+		if publishAt != nil && publishAt.After(now) {
+			at = *publishAt
+		}
+		if err := h.publishBlogPost(ctx, post.ID, []string{BlogDraft}, at); err != nil {
+			return storeError(c, err, "")
+		}
+		created, err := h.store.Blog.GetByID(ctx, post.ID)
+		if err != nil {
+			return storeError(c, err, "")
+		}
+		post = *created
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(post)
+}
+
+// UpdateBlogPost updates the content of a blog post (admin only). Its
+// status only changes through the publish and unpublish endpoints.
+func (h *Handler) UpdateBlogPost(c *fiber.Ctx) error {
+	id := c.Params("id")
+	existing, err := h.store.Blog.GetByID(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+
+	var post BlogPost
+	if err := c.BodyParser(&post); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid blog post data")
+	}
+	acceptLegacyContent(&post)
+	if fields := validateRequest(&post); fields != nil {
+		return validationError(c, fields)
+	}
+
+	if err := h.saveBlogPost(c, existing, &post, nil); err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// saveBlogPost stores new content for existing, keeping its status, and
+// records it as the post's next revision. A new title gets a new slug and
+// the old one redirects.
+func (h *Handler) saveBlogPost(c *fiber.Ctx, existing, post *BlogPost, restoredFrom *int) error {
+	post.ID = existing.ID
+	post.Slug = existing.Slug
+	if post.Title != existing.Title {
+		slug, err := h.assignSlug(c.UserContext(), slugEntityBlogPost, existing.ID, post.Title)
+		if err != nil {
+			return err
+		}
+		post.Slug = slug
+	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	if err := renderBlogPost(post); err != nil {
+		return err
+	}
+	post.Published = existing.Published
+	post.Status = existing.Status
+	post.PublishAt = existing.PublishAt
+	post.PublishedAt = existing.PublishedAt
+	post.AnnouncementID = existing.AnnouncementID
+	post.CreatedAt = existing.CreatedAt
+	post.UpdatedAt = time.Now()
+
+	redirect := slugRedirect(slugEntityBlogPost, existing.ID, existing.Slug, post.Slug)
+	return h.store.Blog.Save(c.UserContext(), post, redirect, newBlogRevision(c, post, restoredFrom))
+}
+
+// newBlogRevision returns the content of post as a revision saved by the
+// signed-in admin; the store numbers it
+func newBlogRevision(c *fiber.Ctx, post *BlogPost, restoredFrom *int) *BlogRevision {
+	revision := &BlogRevision{
+		ID:              uuid.New().String(),
+		PostID:          post.ID,
+		Title:           post.Title,
+		Excerpt:         post.Excerpt,
+		ContentMarkdown: post.ContentMarkdown,
+		Category:        post.Category,
+		Tags:            post.Tags,
+		ImageURL:        post.ImageURL,
+		ImageAlt:        post.ImageAlt,
+		Author:          post.Author,
+		EditorEmail:     GetEmailFromContext(c),
+		RestoredFrom:    restoredFrom,
+		CreatedAt:       post.UpdatedAt,
+	}
+	if revision.Tags == nil {
+		revision.Tags = []string{}
+	}
+	if editor := GetUserFromContext(c); editor != "" {
+		revision.EditedBy = &editor
+	}
+	return revision
+}
+
+// GetBlogRevisions returns the revisions of a blog post, newest first
+// (admin only)
+func (h *Handler) GetBlogRevisions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	if _, err := h.store.Blog.GetByID(ctx, c.Params("id")); err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	page := paginationFromQuery(c, 20)
+
+	revisions, total, err := h.store.BlogRevisions.List(ctx, c.Params("id"), page)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	return c.JSON(newListResponse(revisions, page, total))
+}
+
+// GetBlogRevision returns one revision of a blog post (admin only)
+func (h *Handler) GetBlogRevision(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("revision")
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid revision number")
+	}
+	revision, err := h.store.BlogRevisions.Get(c.UserContext(), c.Params("id"), number)
+	if err != nil {
+		return storeError(c, err, "Revision not found")
+	}
+	return c.JSON(revision)
+}
+
+// DiffBlogRevisions compares two revisions of a blog post line by line
+// (admin only)
+func (h *Handler) DiffBlogRevisions(c *fiber.Ctx) error {
+	var req RevisionDiffRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	ctx := c.UserContext()
+	from, err := h.store.BlogRevisions.Get(ctx, c.Params("id"), req.From)
+	if err != nil {
+		return storeError(c, err, fmt.Sprintf("Revision %d not found", req.From))
+	}
+	to, err := h.store.BlogRevisions.Get(ctx, c.Params("id"), req.To)
+	if err != nil {
+		return storeError(c, err, fmt.Sprintf("Revision %d not found", req.To))
+	}
+	return c.JSON(diffRevisions(from, to))
+}
+
+// RestoreBlogRevision puts the content of an old revision back into a blog
+// post, recorded as a new revision. The post's status is left alone
+// (admin only).
+func (h *Handler) RestoreBlogRevision(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("revision")
+	if err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid revision number")
+	}
+	ctx := c.UserContext()
+	existing, err := h.store.Blog.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	revision, err := h.store.BlogRevisions.Get(ctx, existing.ID, number)
+	if err != nil {
+		return storeError(c, err, "Revision not found")
+	}
+
+	post := *existing
+	post.Title = revision.Title
+	post.Excerpt = revision.Excerpt
+	post.ContentMarkdown = revision.ContentMarkdown
+	post.Category = revision.Category
+	post.Tags = revision.Tags
+	post.ImageURL = revision.ImageURL
+	post.ImageAlt = revision.ImageAlt
+	post.Author = revision.Author
+	if err := h.saveBlogPost(c, existing, &post, &number); err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// PublishBlogPost publishes a draft or scheduled post straight away, or
+// schedules it when publish_at is in the future (admin only)
+func (h *Handler) PublishBlogPost(c *fiber.Ctx) error {
+	var req PublishBlogPostRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorJSON(c, fiber.StatusBadRequest, "Invalid publish time")
+		}
+	}
+	at := time.Now()
+	if req.PublishAt != nil && req.PublishAt.After(at) {
+		at = *req.PublishAt
+	}
+
+	ctx := c.UserContext()
+	err := h.publishBlogPost(ctx, c.Params("id"), []string{BlogDraft, BlogScheduled}, at)
+	if errors.Is(err, ErrConflict) {
+		return errorJSON(c, fiber.StatusConflict, "Blog post is already published")
+	}
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+
+	post, err := h.store.Blog.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// UnpublishBlogPost takes a published or scheduled post back to draft
+// (admin only)
+func (h *Handler) UnpublishBlogPost(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	err := h.store.Blog.Transition(ctx, c.Params("id"), []string{BlogScheduled, BlogPublished}, BlogDraft, time.Now())
+	if errors.Is(err, ErrConflict) {
+		return errorJSON(c, fiber.StatusConflict, "Blog post is already a draft")
+	}
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+
+	post, err := h.store.Blog.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(post)
+}
+
+// DeleteBlogPost deletes a blog post (admin only)
+func (h *Handler) DeleteBlogPost(c *fiber.Ctx) error {
+	if err := h.store.Blog.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return storeError(c, err, "Blog post not found")
+	}
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Blog post deleted successfully",
+	})
+}
+
+// ============ CONTACT FORM HANDLERS ============
+
+// SubmitContactForm handles contact form submissions
+func (h *Handler) SubmitContactForm(c *fiber.Ctx) error {
+	var req ContactFormRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid form data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	submission := &ContactSubmission{
+		ID:         uuid.New().String(),
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Message:    req.Message,
+		PropertyID: req.PropertyID,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := h.store.Contacts.Create(c.UserContext(), submission); err != nil {
+		return storeError(c, err, "")
+	}
+	h.notifyContact(c.UserContext(), submission, c.IP())
+	if req.SubscribeNewsletter {
+		h.subscribeFromForm(c.UserContext(), req.Email, strings.TrimSpace(req.FirstName+" "+req.LastName), SubscriberSourceContact)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Success: true,
+		Data:    submission,
+		Message: "Contact form submitted successfully",
+	})
+}
+
+// contactEmail is the data of the contact notification and acknowledgement
+type contactEmail struct {
+	ContactSubmission
+	PropertyTitle string
+	// AdminURL opens the submission in the admin panel
+	AdminURL string
+}
+
+// notifyContact emails a new submission to the agent of the property it is
+// about, or to ADMIN_EMAIL, and acknowledges it to the submitter from ip.
+// The address is unverified, so acknowledgements are throttled per address
+// and IP. Failures are logged because the submission is already stored.
+func (h *Handler) notifyContact(ctx context.Context, submission *ContactSubmission, ip string) {
+	data := contactEmail{
+		ContactSubmission: *submission,
+		AdminURL:          h.frontendURL + "/admin/contacts/" + submission.ID,
+	}
+
+	recipient := h.adminEmail
+	if submission.PropertyID != nil {
+		property, err := h.store.Properties.GetByID(ctx, *submission.PropertyID)
+		if err == nil {
+			data.PropertyTitle = property.Title
+			if agent := h.propertyAgent(ctx, property); agent != nil {
+				recipient = agent.Email
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			log.Printf("Contact %s: could not load property: %v", submission.ID, err)
+		}
+	}
+
+	if recipient == "" {
+		log.Printf("Contact %s: no agent or ADMIN_EMAIL to notify", submission.ID)
+	} else {
+		// Replying to the notification answers the enquirer directly
+		h.sendEmailReplyTo(recipient, submission.Email, "contact_notification", data)
+	}
+	if h.contactAcks.allow(time.Now(), "email:"+strings.ToLower(submission.Email), "ip:"+ip) {
+		h.sendEmail(submission.Email, "contact_acknowledgement", data)
+	} else {
+		log.Printf("Contact %s: acknowledgement to %s throttled", submission.ID, submission.Email)
+	}
+}
+
+// propertyAgent returns the active admin assigned to a property, if any
+func (h *Handler) propertyAgent(ctx context.Context, property *Property) *User {
+	if property.AgentID == nil {
+		return nil
+	}
+	agent, err := h.store.Users.GetByID(ctx, *property.AgentID)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("Property %s: could not load agent: %v", property.ID, err)
+		}
+		return nil
+	}
+	if agent.Role != "admin" || !agent.IsActive {
+		return nil
+	}
+	return agent
+}
+
+// GetContactSubmissions returns all contact submissions (admin only)
+func (h *Handler) GetContactSubmissions(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 10)
+
+	submissions, total, err := h.store.Contacts.List(c.UserContext(), ContactFilter{
+		PaginationParams: page,
+		UnreadOnly:       c.QueryBool("unread"),
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(submissions, page, total))
+}
+
+// GetContactByID returns a contact submission by ID and marks it as read
+func (h *Handler) GetContactByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+	submission, err := h.store.Contacts.GetByID(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "Contact submission not found")
+	}
+
+	if !submission.IsRead {
+		if err := h.store.Contacts.MarkRead(c.UserContext(), id); err != nil {
+			return storeError(c, err, "Contact submission not found")
+		}
+		submission.IsRead = true
+	}
+
+	return c.JSON(submission)
+}
+
+// ============ NEWSLETTER HANDLERS ============
+
+// Token purposes for the links in newsletter emails
+const (
+	tokenNewsletterConfirm     = "newsletter-confirm"
+	tokenNewsletterUnsubscribe = "newsletter-unsubscribe"
+	tokenNewsletterNoTracking  = "newsletter-no-tracking"
+)
+
+// subscriptionEmail is the data for the newsletter_confirm template
+type subscriptionEmail struct {
+	Name           string
+	ConfirmURL     string
+	UnsubscribeURL string
+	ExpiresAt      time.Time
+}
+
+// SubscribeNewsletter starts a double opt-in subscription from the signup
+// form. New and unsubscribed emails are set to pending and sent a
+// confirmation link; the response is the same whatever the email's state.
+func (h *Handler) SubscribeNewsletter(c *fiber.Ctx) error {
+	var req NewsletterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	if err := h.subscribe(c.UserContext(), req, SubscriberSourceFooter); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(SuccessResponse{
+		Success: true,
+		Message: "Check your email to confirm your subscription",
+	})
+}
+
+// subscribe starts or renews the subscription of req.Email and emails a
+// confirmation link. Active subscriptions are left as they are, since
+// anyone can submit the form. Source records the form of the first signup;
+// interests add to those the subscriber already has.
+func (h *Handler) subscribe(ctx context.Context, req NewsletterRequest, source string) error {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	now := time.Now()
+	subscriber, err := h.store.Newsletter.GetByEmail(ctx, email)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		subscriber = &NewsletterSubscriber{
+			ID:                 uuid.New().String(),
+			Email:              email,
+			Name:               req.Name,
+			Status:             SubscriberPending,
+			ConfirmationSentAt: &now,
+			Source:             source,
+			Tags:               []string{},
+			Interests:          mergeLabels(nil, req.Interests),
+			CreatedAt:          now,
+		}
+		if err := h.store.Newsletter.Create(ctx, subscriber); err != nil {
+			return err
+		}
+		h.sendSubscriptionConfirmation(subscriber)
+	case err != nil:
+		return err
+	case subscriber.Status != SubscriberActive:
+		// Anyone can post any address here, so confirmations are not resent
+		// until newsletterResendInterval has passed. Until one is, the status
+		// stays as it was: an unsubscribed reader is not made pending by a
+		// form post that never reaches them.
+		resend := subscriber.ConfirmationSentAt == nil || now.Sub(*subscriber.ConfirmationSentAt) >= h.newsletterResendInterval
+		if req.Name != "" {
+			subscriber.Name = req.Name
+		}
+		subscriber.Interests = mergeLabels(subscriber.Interests, req.Interests)
+		if resend {
+			subscriber.Status = SubscriberPending
+			subscriber.Active = false
+			subscriber.ConfirmationSentAt = &now
+		}
+		if err := h.store.Newsletter.Update(ctx, subscriber); err != nil {
+			return err
+		}
+		if resend {
+			h.sendSubscriptionConfirmation(subscriber)
+		}
+	}
+	return nil
+}
+
+// subscribeFromForm signs up the sender of another form who ticked its
+// newsletter box. Failures are logged because the form itself succeeded.
+func (h *Handler) subscribeFromForm(ctx context.Context, email, name, source string) {
+	if err := h.subscribe(ctx, NewsletterRequest{Email: email, Name: name}, source); err != nil {
+		log.Printf("Newsletter signup from %s form failed: %v", source, err)
+	}
+}
+
+// mergeLabels adds labels to list, skipping blanks and those already in it
+// whatever their case. The result is never nil.
+func mergeLabels(list, labels []string) []string {
+	merged := make([]string, 0, len(list)+len(labels))
+	for _, group := range [][]string{list, labels} {
+		for _, label := range group {
+			label = strings.TrimSpace(label)
+			if label != "" && !containsFold(merged, label) {
+				merged = append(merged, label)
+			}
+		}
+	}
+	return merged
+}
+
+// sendSubscriptionConfirmation emails a pending subscriber their confirmation link
+func (h *Handler) sendSubscriptionConfirmation(subscriber *NewsletterSubscriber) {
+	expires := time.Now().Add(h.newsletterConfirmTTL).Truncate(time.Second)
+	h.sendEmail(subscriber.Email, "newsletter_confirm", subscriptionEmail{
+		Name:           subscriber.Name,
+		ConfirmURL:     h.newsletterURL("/newsletter/confirm", h.signToken(tokenNewsletterConfirm, subscriber.Email, expires)),
+		UnsubscribeURL: h.unsubscribeURL(subscriber.Email),
+		ExpiresAt:      expires,
+	})
+}
+
+// unsubscribeURL returns the permanent unsubscribe link for email
+func (h *Handler) unsubscribeURL(email string) string {
+	return h.newsletterURL("/newsletter/unsubscribe", h.signToken(tokenNewsletterUnsubscribe, email, time.Time{}))
+}
+
+// newsletterURL returns an absolute API URL for path carrying token
+func (h *Handler) newsletterURL(path, token string) string {
+	return h.publicURL + "/api/v1" + path + "?token=" + url.QueryEscape(token)
+}
+
+// ConfirmNewsletter activates a pending subscription from its emailed link
+func (h *Handler) ConfirmNewsletter(c *fiber.Ctx) error {
+	email, ok := h.parseToken(tokenNewsletterConfirm, c.Query("token"), time.Now())
+	if !ok {
+		return errorJSON(c, fiber.StatusForbidden, "Confirmation link is invalid or has expired")
+	}
+	subscriber, err := h.store.Newsletter.GetByEmail(c.UserContext(), email)
+	if err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	switch subscriber.Status {
+	case SubscriberUnsubscribed:
+		// An old confirmation link must not undo a later unsubscribe
+		return errorJSON(c, fiber.StatusGone, "This subscription was cancelled; subscribe again to rejoin")
+	case SubscriberPending:
+		now := time.Now()
+		subscriber.Status = SubscriberActive
+		subscriber.Active = true
+		subscriber.ConfirmedAt = &now
+		subscriber.UnsubAt = nil
+		if err := h.store.Newsletter.Update(c.UserContext(), subscriber); err != nil {
+			return storeError(c, err, "Subscriber not found")
+		}
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Your newsletter subscription is confirmed",
+	})
+}
+
+// UnsubscribeNewsletterLink unsubscribes the email a signed unsubscribe link
+// was issued for. It accepts GET from the link and POST for one-click
+// unsubscribe from mail clients.
+func (h *Handler) UnsubscribeNewsletterLink(c *fiber.Ctx) error {
+	email, ok := h.parseToken(tokenNewsletterUnsubscribe, c.Query("token"), time.Now())
+	if !ok {
+		return errorJSON(c, fiber.StatusForbidden, "Unsubscribe link is invalid")
+	}
+	subscriber, err := h.store.Newsletter.GetByEmail(c.UserContext(), email)
+	if err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+	if err := h.unsubscribe(c.UserContext(), subscriber); err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "You have been unsubscribed from the newsletter",
+	})
+}
+
+// OptOutNewsletterTracking stops tracking the opens and clicks of the email
+// a signed link was issued for. Campaign emails sent after this are not
+// tracked, and events from earlier emails are no longer recorded.
+func (h *Handler) OptOutNewsletterTracking(c *fiber.Ctx) error {
+	email, ok := h.parseToken(tokenNewsletterNoTracking, c.Query("token"), time.Now())
+	if !ok {
+		return errorJSON(c, fiber.StatusForbidden, "Link is invalid")
+	}
+	subscriber, err := h.store.Newsletter.GetByEmail(c.UserContext(), email)
+	if err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	if !subscriber.TrackingOptOut {
+		subscriber.TrackingOptOut = true
+		if err := h.store.Newsletter.Update(c.UserContext(), subscriber); err != nil {
+			return storeError(c, err, "Subscriber not found")
+		}
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Opens and clicks of our emails to you are no longer tracked",
+	})
+}
+
+// unsubscribe marks subscriber unsubscribed; it is a no-op if it already is
+func (h *Handler) unsubscribe(ctx context.Context, subscriber *NewsletterSubscriber) error {
+	if subscriber.Status == SubscriberUnsubscribed {
+		return nil
+	}
+	now := time.Now()
+	subscriber.Status = SubscriberUnsubscribed
+	subscriber.Active = false
+	subscriber.UnsubAt = &now
+	return h.store.Newsletter.Update(ctx, subscriber)
+}
+
+// GetNewsletterSubscribers returns all subscribers (admin only)
+func (h *Handler) GetNewsletterSubscribers(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 10)
+
+	subscribers, total, err := h.store.Newsletter.List(c.UserContext(), NewsletterFilter{
+		PaginationParams: page,
+		ActiveOnly:       c.QueryBool("active"),
+		Status:           c.Query("status"),
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(subscribers, page, total))
+}
+
+// emailParam returns the :email route parameter the way subscriber
+// addresses are stored, trimmed and in lower case
+func emailParam(c *fiber.Ctx) string {
+	return strings.ToLower(strings.TrimSpace(c.Params("email")))
+}
+
+// UpdateSubscriber replaces the tags and interests of a subscriber (admin
+// only). Tags are stored in lower case.
+func (h *Handler) UpdateSubscriber(c *fiber.Ctx) error {
+	var req SubscriberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid subscriber data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+
+	subscriber, err := h.store.Newsletter.GetByEmail(c.UserContext(), emailParam(c))
+	if err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+	for i, tag := range req.Tags {
+		req.Tags[i] = strings.ToLower(tag)
+	}
+	subscriber.Tags = mergeLabels(nil, req.Tags)
+	subscriber.Interests = mergeLabels(nil, req.Interests)
+	if err := h.store.Newsletter.Update(c.UserContext(), subscriber); err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	return c.JSON(subscriber)
+}
+
+// UnsubscribeNewsletter removes email from newsletter
+func (h *Handler) UnsubscribeNewsletter(c *fiber.Ctx) error {
+	email := emailParam(c)
+	subscriber, err := h.store.Newsletter.GetByEmail(c.UserContext(), email)
+	if err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	if err := h.unsubscribe(c.UserContext(), subscriber); err != nil {
+		return storeError(c, err, "Subscriber not found")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Email %s has been unsubscribed", email),
+	})
+}
+
+// ============ SEGMENT HANDLERS ============
+
+// GetSegments lists saved segments by name (admin only)
+func (h *Handler) GetSegments(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 20)
+
+	segments, total, err := h.store.Segments.List(c.UserContext(), page)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(segments, page, total))
+}
+
+// GetSegment returns a saved segment (admin only)
+func (h *Handler) GetSegment(c *fiber.Ctx) error {
+	segment, err := h.store.Segments.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+	return c.JSON(segment)
+}
+
+// CreateSegment saves a segment of subscribers (admin only)
+func (h *Handler) CreateSegment(c *fiber.Ctx) error {
+	var req SegmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid segment data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateSegmentFilter(c.UserContext(), req.Filter); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
+	}
+
+	now := time.Now()
+	adminID := GetUserFromContext(c)
+	segment := &Segment{
+		ID:        uuid.New().String(),
+		CreatedBy: &adminID,
+		CreatedAt: now,
+	}
+	applySegmentRequest(segment, req, now)
+	if err := h.store.Segments.Create(c.UserContext(), segment); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(segment)
+}
+
+// UpdateSegment replaces a segment's name, description and filter.
+// Campaigns that have not started sending pick up the new filter (admin only).
+func (h *Handler) UpdateSegment(c *fiber.Ctx) error {
+	segment, err := h.store.Segments.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+
+	var req SegmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid segment data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateSegmentFilter(c.UserContext(), req.Filter); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
+	}
+
+	applySegmentRequest(segment, req, time.Now())
+	if err := h.store.Segments.Update(c.UserContext(), segment); err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+
+	return c.JSON(segment)
+}
+
+// applySegmentRequest copies the editable fields of req onto segment
+func applySegmentRequest(segment *Segment, req SegmentRequest, now time.Time) {
+	segment.Name = strings.TrimSpace(req.Name)
+	segment.Description = req.Description
+	segment.Filter = strings.TrimSpace(req.Filter)
+	segment.UpdatedAt = now
+}
+
+// DeleteSegment removes a segment that no unsent campaign is addressed to
+// (admin only)
+func (h *Handler) DeleteSegment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	segment, err := h.store.Segments.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+	for _, status := range []string{CampaignDraft, CampaignScheduled, CampaignSending} {
+		_, total, err := h.store.Campaigns.List(ctx, CampaignFilter{
+			PaginationParams: PaginationParams{Page: 1, Limit: 1},
+			Status:           status,
+			Segment:          segment.ID,
+		})
+		if err != nil {
+			return storeError(c, err, "")
+		}
+		if total > 0 {
+			return errorJSON(c, fiber.StatusConflict, "Segment is the audience of a campaign that has not been sent")
+		}
+	}
+
+	if err := h.store.Segments.Delete(ctx, segment.ID); err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "Segment deleted successfully",
+	})
+}
+
+// GetSegmentSubscribers lists the active subscribers a segment currently
+// matches (admin only)
+func (h *Handler) GetSegmentSubscribers(c *fiber.Ctx) error {
+	subscribers, err := h.segmentSubscribers(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+	page := paginationFromQuery(c, 20)
+	return c.JSON(newListResponse(paginate(subscribers, page), page, len(subscribers)))
+}
+
+// ExportSegment downloads the active subscribers a segment matches as CSV
+// (admin only)
+func (h *Handler) ExportSegment(c *fiber.Ctx) error {
+	subscribers, err := h.segmentSubscribers(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Segment not found")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"email", "name", "source", "tags", "interests", "favorite_property_ids", "confirmed_at", "created_at"})
+	for _, s := range subscribers {
+		confirmedAt := ""
+		if s.ConfirmedAt != nil {
+			confirmedAt = s.ConfirmedAt.UTC().Format(time.RFC3339)
+		}
+		w.Write([]string{
+			csvText(s.Email),
+			csvText(s.Name),
+			csvText(s.Source),
+			csvText(strings.Join(s.Tags, ";")),
+			csvText(strings.Join(s.Interests, ";")),
+			strings.Join(s.FavoritePropertyIDs, ";"),
+			confirmedAt,
+			s.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Could not export segment")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="segment-`+c.Params("id")+`.csv"`)
+	return c.Send(buf.Bytes())
+}
+
+// csvText stops spreadsheets from running a cell as a formula. Most columns
+// come from public forms, so a leading =, +, -, @, tab or carriage return is
+// escaped with a quote.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// segmentSubscribers returns the active subscribers a saved segment matches
+func (h *Handler) segmentSubscribers(ctx context.Context, id string) ([]NewsletterSubscriber, error) {
+	segment, err := h.store.Segments.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := h.compileSegment(ctx, segment.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return h.activeSubscribers(ctx, expr)
+}
+
+// validateSegmentFilter checks that a segment filter parses and that the
+// properties its favorite terms name exist
+func (h *Handler) validateSegmentFilter(ctx context.Context, filter string) ([]FieldError, error) {
+	expr, err := parseSegmentFilter(filter)
+	if err != nil {
+		return []FieldError{{Field: "filter", Rule: "segment_filter", Message: err.Error()}}, nil
+	}
+
+	var fields []FieldError
+	var lookupErr error
+	segmentTerms(expr, func(term *segmentTerm) {
+		if term.field != segmentFieldFavorite || lookupErr != nil {
+			return
+		}
+		_, err := h.segmentProperty(ctx, term.value)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			fields = append(fields, FieldError{
+				Field:   "filter",
+				Rule:    "segment_filter",
+				Message: fmt.Sprintf("no property has the ID or slug %q", term.value),
+			})
+		case err != nil:
+			lookupErr = err
+		}
+	})
+	return fields, lookupErr
+}
+
+// errInvalidSegmentFilter is returned for a stored segment filter that no
+// longer parses
+var errInvalidSegmentFilter = errors.New("invalid segment filter")
+
+// compileSegment parses a segment filter and resolves its favorite terms to
+// property IDs. A term naming a property that has since been deleted
+// matches nobody.
+func (h *Handler) compileSegment(ctx context.Context, filter string) (segmentExpr, error) {
+	expr, err := parseSegmentFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSegmentFilter, err)
+	}
+
+	var lookupErr error
+	segmentTerms(expr, func(term *segmentTerm) {
+		if term.field != segmentFieldFavorite || lookupErr != nil {
+			return
+		}
+		property, err := h.segmentProperty(ctx, term.value)
+		switch {
+		case err == nil:
+			term.value = property.ID
+		case !errors.Is(err, ErrNotFound):
+			lookupErr = err
+		}
+	})
+	return expr, lookupErr
+}
+
+// segmentProperty finds a property by ID or slug, following the slug to
+// the renamed property if it changed after the segment was saved
+func (h *Handler) segmentProperty(ctx context.Context, idOrSlug string) (*Property, error) {
+	if validate.Var(idOrSlug, "uuid") == nil {
+		return h.store.Properties.GetByID(ctx, idOrSlug)
+	}
+	property, err := h.store.Properties.GetBySlug(ctx, idOrSlug)
+	if !errors.Is(err, ErrNotFound) {
+		return property, err
+	}
+	redirect, err := h.store.SlugRedirects.Get(ctx, slugEntityProperty, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	return h.store.Properties.GetByID(ctx, redirect.EntityID)
+}
+
+// ============ CAMPAIGN HANDLERS ============
+
+// campaignEditable reports whether a campaign's content can still change.
+// A failed campaign never started sending, so it can be fixed and retried.
+func campaignEditable(campaign *Campaign) bool {
+	return campaign.Status == CampaignDraft || campaign.Status == CampaignScheduled || campaign.Status == CampaignFailed
+}
+
+// GetCampaigns lists newsletter campaigns, newest first (admin only)
+func (h *Handler) GetCampaigns(c *fiber.Ctx) error {
+	var req CampaignListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	page := paginationFromQuery(c, 20)
+
+	campaigns, total, err := h.store.Campaigns.List(c.UserContext(), CampaignFilter{
+		PaginationParams: page,
+		Status:           req.Status,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(campaigns, page, total))
+}
+
+// GetCampaign returns a campaign with its delivery counts (admin only)
+func (h *Handler) GetCampaign(c *fiber.Ctx) error {
+	campaign, err := h.store.Campaigns.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+	return c.JSON(campaign)
+}
+
+// CreateCampaign saves a draft campaign (admin only)
+func (h *Handler) CreateCampaign(c *fiber.Ctx) error {
+	var req CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid campaign data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateCampaignSegment(c.UserContext(), req.Segment); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
+	}
+
+	now := time.Now()
+	adminID := GetUserFromContext(c)
+	campaign := &Campaign{
+		ID:        uuid.New().String(),
+		Status:    CampaignDraft,
+		CreatedBy: &adminID,
+		CreatedAt: now,
+	}
+	applyCampaignRequest(campaign, req, now)
+	if err := h.store.Campaigns.Create(c.UserContext(), campaign); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(campaign)
+}
+
+// UpdateCampaign edits a campaign that has not started sending (admin only)
+func (h *Handler) UpdateCampaign(c *fiber.Ctx) error {
+	campaign, err := h.store.Campaigns.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+	if !campaignEditable(campaign) {
+		return errorJSON(c, fiber.StatusConflict, "Campaign has already started sending")
+	}
+
+	var req CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid campaign data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	if fields, err := h.validateCampaignSegment(c.UserContext(), req.Segment); err != nil {
+		return storeError(c, err, "")
+	} else if fields != nil {
+		return validationError(c, fields)
+	}
+
+	applyCampaignRequest(campaign, req, time.Now())
+	if err := h.store.Campaigns.Update(c.UserContext(), campaign); err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+
+	return c.JSON(campaign)
+}
+
+// applyCampaignRequest copies the editable fields of req onto campaign
+func applyCampaignRequest(campaign *Campaign, req CampaignRequest, now time.Time) {
+	campaign.Subject = strings.TrimSpace(req.Subject)
+	campaign.HTMLBody = req.HTMLBody
+	campaign.TextBody = req.TextBody
+	campaign.Segment = req.Segment
+	if campaign.Segment == "" {
+		campaign.Segment = segmentAllSubscribers
+	}
+	campaign.UpdatedAt = now
+}
+
+// validateCampaignSegment checks that a campaign's audience is "all" or a
+// saved segment
+func (h *Handler) validateCampaignSegment(ctx context.Context, segment string) ([]FieldError, error) {
+	if segment == "" || segment == segmentAllSubscribers {
+		return nil, nil
+	}
+	invalid := []FieldError{{
+		Field:   "segment",
+		Rule:    "segment",
+		Message: `must be "all" or the ID of a saved segment`,
+	}}
+	if validate.Var(segment, "uuid") != nil {
+		return invalid, nil
 	}
+	_, err := h.store.Segments.GetByID(ctx, segment)
+	if errors.Is(err, ErrNotFound) {
+		return invalid, nil
+	}
+	return nil, err
+}
 
-	// TODO: Update in Supabase
-	property.ID = id
-	property.UpdatedAt = time.Now()
+// DeleteCampaign removes a campaign that has not started sending (admin only)
+func (h *Handler) DeleteCampaign(c *fiber.Ctx) error {
+	campaign, err := h.store.Campaigns.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+	if !campaignEditable(campaign) {
+		return errorJSON(c, fiber.StatusConflict, "Campaign has already started sending")
+	}
 
-	return c.JSON(property)
-}
+	if err := h.store.Campaigns.Delete(c.UserContext(), campaign.ID); err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
 
-// DeleteProperty deletes a property (admin only)
-func DeleteProperty(c *fiber.Ctx) error {
-	id := c.Params("id")
-	// TODO: Delete from Supabase
 	return c.JSON(SuccessResponse{
 		Success: true,
-		Message: "Property deleted successfully",
+		Message: "Campaign deleted successfully",
 	})
 }
 
-// ============ BLOG HANDLERS ============
-
-// GetBlogPosts returns paginated list of blog posts
-func GetBlogPosts(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-
-	// TODO: Query from Supabase
-	posts := []BlogPost{
-		{
-			ID:       "blog-001",
-			Title:    "Top Real Estate Trends",
-			Slug:     "top-real-estate-trends",
-			Category: "Investment",
-			Author:   "John Doe",
-		},
+// ScheduleCampaign queues a draft campaign for sending at send_at, or as
+// soon as possible without it. A scheduled or failed campaign can be
+// rescheduled (admin only).
+func (h *Handler) ScheduleCampaign(c *fiber.Ctx) error {
+	var req ScheduleCampaignRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorJSON(c, fiber.StatusBadRequest, "Invalid schedule")
+		}
+	}
+	sendAt := time.Now()
+	if req.SendAt != nil && req.SendAt.After(sendAt) {
+		sendAt = *req.SendAt
 	}
 
-	return c.JSON(ListResponse{
-		Data:       posts,
-		Page:       page,
-		Limit:      limit,
-		Total:      3,
-		TotalPages: 1,
-	})
+	return h.transitionCampaign(c, []string{CampaignDraft, CampaignScheduled, CampaignFailed}, CampaignScheduled, sendAt,
+		"Only draft, scheduled or failed campaigns can be scheduled")
 }
 
-// GetBlogPostByID returns a blog post by ID
-func GetBlogPostByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	// TODO: Query from Supabase
-	post := &BlogPost{
-		ID:       id,
-		Title:    "Blog Post Title",
-		Content:  "Blog post content...",
-		Author:   "Author Name",
-		Category: "Investment",
+// CancelCampaign stops a campaign. A scheduled campaign goes back to draft;
+// one that is sending stops after the current batch and keeps its
+// delivery history (admin only).
+func (h *Handler) CancelCampaign(c *fiber.Ctx) error {
+	campaign, err := h.store.Campaigns.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
 	}
-	return c.JSON(post)
-}
 
-// GetBlogPostBySlug returns a blog post by slug
-func GetBlogPostBySlug(c *fiber.Ctx) error {
-	slug := c.Params("slug")
-	// TODO: Query from Supabase
-	post := &BlogPost{
-		ID:   "blog-001",
-		Slug: slug,
-		Title: "Blog Title",
+	switch campaign.Status {
+	case CampaignScheduled:
+		return h.transitionCampaign(c, []string{CampaignScheduled}, CampaignDraft, time.Now(), "Campaign has already started sending")
+	case CampaignSending:
+		return h.transitionCampaign(c, []string{CampaignSending}, CampaignCancelled, time.Now(), "Campaign has already been sent")
 	}
-	return c.JSON(post)
+	return errorJSON(c, fiber.StatusConflict, "Only scheduled or sending campaigns can be cancelled")
 }
 
-// GetBlogByCategory returns blog posts in a category
-func GetBlogByCategory(c *fiber.Ctx) error {
-	category := c.Params("category")
-	if category == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Category is required",
-			Code:    fiber.StatusBadRequest,
-		})
+// transitionCampaign moves the campaign in the id parameter to status to and
+// responds with it, or with 409 and conflict if it was not in a from status
+func (h *Handler) transitionCampaign(c *fiber.Ctx, from []string, to string, at time.Time, conflict string) error {
+	ctx := c.UserContext()
+	err := h.store.Campaigns.Transition(ctx, c.Params("id"), from, to, at)
+	if errors.Is(err, ErrConflict) {
+		return errorJSON(c, fiber.StatusConflict, conflict)
 	}
-
-	// TODO: Query from Supabase by category
-	posts := []BlogPost{
-		{
-			ID:       "blog-001",
-			Category: strings.ToTitle(category),
-			Title:    "Blog in category",
-		},
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
 	}
 
-	return c.JSON(ListResponse{
-		Data:  posts,
-		Total: len(posts),
-	})
+	campaign, err := h.store.Campaigns.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+	return c.JSON(campaign)
 }
 
-// CreateBlogPost creates a new blog post (admin only)
-func CreateBlogPost(c *fiber.Ctx) error {
-	var post BlogPost
-	if err := c.BodyParser(&post); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid blog post data",
-			Code:    fiber.StatusBadRequest,
-		})
+// SendTestCampaign emails a campaign to the signed-in admin straight away,
+// with [Test] in front of the subject. Nothing is recorded (admin only).
+func (h *Handler) SendTestCampaign(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	campaign, err := h.store.Campaigns.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
+	}
+	admin, err := h.store.Users.GetByID(ctx, GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "User not found")
 	}
 
-	// TODO: Save to Supabase
-	post.ID = uuid.New().String()
-	post.CreatedAt = time.Now()
-	post.UpdatedAt = time.Now()
+	msg, err := h.renderCampaign(campaign, admin.Email, "")
+	if err != nil {
+		return errorJSON(c, fiber.StatusUnprocessableEntity, "Campaign could not be rendered: "+err.Error())
+	}
+	msg.Subject = "[Test] " + msg.Subject
 
-	return c.Status(fiber.StatusCreated).JSON(post)
+	sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := h.mail.Deliver(sendCtx, msg); err != nil {
+		log.Printf("Test send of campaign %s failed: %v", campaign.ID, err)
+		return errorJSON(c, fiber.StatusBadGateway, "Test email could not be sent")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Test email sent to %s", admin.Email),
+	})
 }
 
-// UpdateBlogPost updates a blog post (admin only)
-func UpdateBlogPost(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var post BlogPost
-	if err := c.BodyParser(&post); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid blog post data",
-			Code:    fiber.StatusBadRequest,
-		})
+// GetCampaignRecipients lists who a campaign went to and how each delivery
+// went (admin only)
+func (h *Handler) GetCampaignRecipients(c *fiber.Ctx) error {
+	var req CampaignRecipientListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
+	}
+	ctx := c.UserContext()
+	if _, err := h.store.Campaigns.GetByID(ctx, c.Params("id")); err != nil {
+		return storeError(c, err, "Campaign not found")
 	}
+	page := paginationFromQuery(c, 50)
 
-	// TODO: Update in Supabase
-	post.ID = id
-	post.UpdatedAt = time.Now()
+	recipients, total, err := h.store.Campaigns.ListRecipients(ctx, CampaignRecipientFilter{
+		PaginationParams: page,
+		CampaignID:       c.Params("id"),
+		Status:           req.Status,
+	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
-	return c.JSON(post)
+	return c.JSON(newListResponse(recipients, page, total))
 }
 
-// DeleteBlogPost deletes a blog post (admin only)
-func DeleteBlogPost(c *fiber.Ctx) error {
-	// TODO: Delete from Supabase
-	return c.JSON(SuccessResponse{
-		Success: true,
-		Message: "Blog post deleted successfully",
-	})
+// transparentGIF is the 1x1 image served by the open tracker
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
 }
 
-// ============ CONTACT FORM HANDLERS ============
-
-// SubmitContactForm handles contact form submissions
-func SubmitContactForm(c *fiber.Ctx) error {
-	var req ContactFormRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid form data",
-			Code:    fiber.StatusBadRequest,
-		})
+// TrackCampaignOpen records that a recipient opened a campaign email and
+// serves the invisible image it embeds. The image is served even when the
+// token is invalid so mail clients show nothing broken.
+func (h *Handler) TrackCampaignOpen(c *fiber.Ctx) error {
+	if recipientID, ok := h.parseToken(tokenCampaignOpen, c.Query("token"), time.Now()); ok {
+		h.recordCampaignEvent(c.UserContext(), recipientID, "open", "")
 	}
 
-	// TODO: Validate input
-	// TODO: Save to Supabase
-	// TODO: Send email notification
+	c.Set(fiber.HeaderCacheControl, "no-store, max-age=0")
+	c.Set(fiber.HeaderContentType, "image/gif")
+	return c.Send(transparentGIF)
+}
 
-	submission := &ContactSubmission{
-		ID:        uuid.New().String(),
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Email:     req.Email,
-		Phone:     req.Phone,
-		Message:   req.Message,
-		PropertyID: req.PropertyID,
-		CreatedAt: time.Now(),
+// TrackCampaignClick records a recipient's click on a campaign link and
+// redirects to the link's destination
+func (h *Handler) TrackCampaignClick(c *fiber.Ctx) error {
+	value, ok := h.parseToken(tokenCampaignClick, c.Query("token"), time.Now())
+	recipientID, target, found := strings.Cut(value, "\n")
+	if !ok || !found {
+		return errorJSON(c, fiber.StatusForbidden, "Link is invalid")
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
-		Success: true,
-		Data:    submission,
-		Message: "Contact form submitted successfully",
-	})
+	h.recordCampaignEvent(c.UserContext(), recipientID, "click", target)
+	return c.Redirect(target, fiber.StatusFound)
 }
 
-// GetContactSubmissions returns all contact submissions (admin only)
-func GetContactSubmissions(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+// recordCampaignEvent stores an open or click unless the subscriber has
+// since unsubscribed or opted out of tracking. Failures are logged, as the
+// recipient should get their image or redirect regardless.
+func (h *Handler) recordCampaignEvent(ctx context.Context, recipientID, eventType, url string) {
+	err := h.storeCampaignEvent(ctx, recipientID, eventType, url)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Printf("Could not record campaign %s: %v", eventType, err)
+	}
+}
 
-	// TODO: Query from Supabase
-	submissions := []ContactSubmission{}
+func (h *Handler) storeCampaignEvent(ctx context.Context, recipientID, eventType, url string) error {
+	recipient, err := h.store.Campaigns.GetRecipient(ctx, recipientID)
+	if err != nil {
+		return err
+	}
+	subscriber, err := h.store.Newsletter.GetByEmail(ctx, recipient.Email)
+	if err != nil {
+		return err
+	}
+	if subscriber.Status != SubscriberActive || subscriber.TrackingOptOut {
+		return nil
+	}
 
-	return c.JSON(ListResponse{
-		Data:  submissions,
-		Page:  page,
-		Limit: limit,
-		Total: 0,
+	return h.store.Campaigns.RecordEvent(ctx, &CampaignEvent{
+		ID:          uuid.New().String(),
+		CampaignID:  recipient.CampaignID,
+		RecipientID: recipient.ID,
+		Type:        eventType,
+		URL:         url,
+		CreatedAt:   time.Now(),
 	})
 }
 
-// GetContactByID returns a contact submission by ID
-func GetContactByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	// TODO: Query from Supabase and mark as read
-	submission := &ContactSubmission{
-		ID: id,
+// GetCampaignStats returns the open rate, click rate and most clicked links
+// of a campaign (admin only)
+func (h *Handler) GetCampaignStats(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	campaign, err := h.store.Campaigns.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "Campaign not found")
 	}
-	return c.JSON(submission)
+	stats, err := h.store.Campaigns.EventStats(ctx, campaign.ID)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	stats.TopLinks, err = h.store.Campaigns.TopLinks(ctx, campaign.ID, 10)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	stats.CampaignID = campaign.ID
+	stats.Sent = campaign.SentCount
+	if stats.Sent > 0 {
+		stats.OpenRate = math.Round(float64(stats.UniqueOpens)/float64(stats.Sent)*1000) / 10
+		stats.ClickRate = math.Round(float64(stats.UniqueClicks)/float64(stats.Sent)*1000) / 10
+	}
+	return c.JSON(stats)
 }
 
-// ============ NEWSLETTER HANDLERS ============
+// ============ BROCHURE HANDLERS ============
 
-// SubscribeNewsletter adds email to newsletter
-func SubscribeNewsletter(c *fiber.Ctx) error {
-	var req NewsletterRequest
+// leadSourceBrochure marks leads that first asked for a brochure
+const leadSourceBrochure = "brochure"
+
+// DownloadBrochure records a brochure request, captures the email as a lead
+// and returns a signed link to the brochure that expires
+func (h *Handler) DownloadBrochure(c *fiber.Ctx) error {
+	var req struct {
+		PropertyID string `json:"property_id" validate:"required,uuid"`
+		Email      string `json:"email" validate:"required,email,max=255"`
+		// SubscribeNewsletter also signs the email up for the newsletter
+		SubscribeNewsletter bool `json:"subscribe_newsletter"`
+	}
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request data",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// TODO: Check if already subscribed
-	// TODO: Save to Supabase
-	// TODO: Send confirmation email
+	ctx := c.UserContext()
+	property, err := h.store.Properties.GetByID(ctx, req.PropertyID)
+	if err != nil {
+		return storeError(c, err, "Property not found")
+	}
 
-	subscriber := &NewsletterSubscriber{
-		ID:        uuid.New().String(),
-		Email:     req.Email,
-		Name:      req.Name,
-		Active:    true,
-		CreatedAt: time.Now(),
+	// Requests from an email with an account are attributed to that user
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	var userID *string
+	if user, err := h.store.Users.GetByEmail(ctx, email); err == nil {
+		userID = &user.ID
+	} else if !errors.Is(err, ErrNotFound) {
+		return storeError(c, err, "")
+	}
+
+	now := time.Now()
+	request := BrochureRequest{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Email:      email,
+		PropertyID: property.ID,
+		CreatedAt:  now,
+	}
+	if err := h.store.Brochures.Create(ctx, &request); err != nil {
+		return storeError(c, err, "")
+	}
+	lead := Lead{
+		ID:         uuid.New().String(),
+		Email:      email,
+		Source:     leadSourceBrochure,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := h.store.Leads.Capture(ctx, &lead); err != nil {
+		return storeError(c, err, "")
+	}
+
+	expires := now.Add(h.brochureLinkTTL).Truncate(time.Second)
+	link := DocumentLink{
+		URL:       h.signedURL(brochurePath(request.ID), expires),
+		ExpiresAt: expires,
+	}
+	h.sendEmail(email, "brochure", struct {
+		PropertyTitle string
+		DocumentLink
+	}{property.Title, link})
+	if req.SubscribeNewsletter {
+		h.subscribeFromForm(ctx, email, "", SubscriberSourceBrochure)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
 		Success: true,
-		Data:    subscriber,
-		Message: "Successfully subscribed to newsletter",
+		Data:    link,
+		Message: "Brochure link created",
 	})
 }
 
-// GetNewsletterSubscribers returns all subscribers (admin only)
-func GetNewsletterSubscribers(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-
-	// TODO: Query from Supabase
-	subscribers := []NewsletterSubscriber{}
+// DownloadRequestedBrochure serves the brochure behind a signed brochure
+// request link and counts the download
+func (h *Handler) DownloadRequestedBrochure(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !h.verifySignedURL(brochurePath(id), c.Query("expires"), c.Query("signature"), time.Now()) {
+		return errorJSON(c, fiber.StatusForbidden, "Download link is invalid or has expired")
+	}
 
-	return c.JSON(ListResponse{
-		Data:  subscribers,
-		Page:  page,
-		Limit: limit,
-		Total: 0,
-	})
+	request, err := h.store.Brochures.GetByID(c.UserContext(), id)
+	if err != nil {
+		return storeError(c, err, "Brochure not found")
+	}
+	if err := h.store.Brochures.RecordDownload(c.UserContext(), request.ID, time.Now()); err != nil {
+		return storeError(c, err, "Brochure not found")
+	}
+	return h.sendDocument(c, documentBrochure, request.PropertyID, "")
 }
 
-// UnsubscribeNewsletter removes email from newsletter
-func UnsubscribeNewsletter(c *fiber.Ctx) error {
-	email := c.Params("email")
-	// TODO: Update in Supabase
-
-	return c.JSON(SuccessResponse{
-		Success: true,
-		Message: fmt.Sprintf("Email %s has been unsubscribed", email),
-	})
+// GetBrochureStats returns brochure requests and downloads per property (admin only)
+func (h *Handler) GetBrochureStats(c *fiber.Ctx) error {
+	stats, err := h.store.Brochures.Stats(c.UserContext(), 0)
+	if err != nil {
+		return storeError(c, err, "")
+	}
+	return c.JSON(stats)
 }
 
-// ============ BROCHURE HANDLERS ============
-
-// DownloadBrochure handles brochure download requests
-func DownloadBrochure(c *fiber.Ctx) error {
-	var req struct {
-		PropertyID string `json:"property_id"`
-		Email      string `json:"email"`
+// GetLeads returns leads, most recently active first (admin only)
+func (h *Handler) GetLeads(c *fiber.Ctx) error {
+	var req LeadListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid query parameters")
 	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request",
-			Code:    fiber.StatusBadRequest,
-		})
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
+	page := paginationFromQuery(c, 20)
 
-	// TODO: Log download request
-	// TODO: Send brochure via email
-	// TODO: Increment download counter
-
-	return c.JSON(SuccessResponse{
-		Success: true,
-		Message: "Brochure download initiated",
+	leads, total, err := h.store.Leads.List(c.UserContext(), LeadFilter{
+		PaginationParams: page,
+		Source:           req.Source,
+		Search:           strings.TrimSpace(req.Search),
 	})
+	if err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(newListResponse(leads, page, total))
+}
+
+// brochurePath is the signed path of a brochure request download
+func brochurePath(requestID string) string {
+	return "/brochure/" + requestID + "/download"
 }
 
 // ============ USER HANDLERS ============
 
 // GetUserProfile returns current user profile
-func GetUserProfile(c *fiber.Ctx) error {
-	userID := GetUserFromContext(c)
-	// TODO: Query user from Supabase
-
-	user := &User{
-		ID: userID,
-		Email: c.Locals("email").(string),
+func (h *Handler) GetUserProfile(c *fiber.Ctx) error {
+	user, err := h.store.Users.GetByID(c.UserContext(), GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "User not found")
 	}
 
 	return c.JSON(user)
 }
 
 // UpdateUserProfile updates user profile
-func UpdateUserProfile(c *fiber.Ctx) error {
-	userID := GetUserFromContext(c)
-	var user User
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid user data",
-			Code:    fiber.StatusBadRequest,
-		})
+func (h *Handler) UpdateUserProfile(c *fiber.Ctx) error {
+	var req UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid user data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// TODO: Update in Supabase
-	user.ID = userID
+	user, err := h.store.Users.GetByID(c.UserContext(), GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "User not found")
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
 	user.UpdatedAt = time.Now()
 
+	if err := h.store.Users.Update(c.UserContext(), user); err != nil {
+		return storeError(c, err, "User not found")
+	}
+
 	return c.JSON(user)
 }
 
 // GetAllUsers returns all users (admin only)
-func GetAllUsers(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+func (h *Handler) GetAllUsers(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 10)
 
-	// TODO: Query from Supabase
-	users := []User{}
+	users, total, err := h.store.Users.List(c.UserContext(), page)
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
-	return c.JSON(ListResponse{
-		Data:  users,
-		Page:  page,
-		Limit: limit,
-		Total: 0,
-	})
+	return c.JSON(newListResponse(users, page, total))
 }
 
 // GetUserByID returns a user by ID (admin only)
-func GetUserByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	// TODO: Query from Supabase
-
-	user := &User{ID: id}
+func (h *Handler) GetUserByID(c *fiber.Ctx) error {
+	user, err := h.store.Users.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "User not found")
+	}
 	return c.JSON(user)
 }
 
 // UpdateUser updates a user (admin only)
-func UpdateUser(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var user User
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid user data",
-			Code:    fiber.StatusBadRequest,
-		})
+func (h *Handler) UpdateUser(c *fiber.Ctx) error {
+	var req UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid user data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// TODO: Update in Supabase
-	user.ID = id
+	user, err := h.store.Users.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "User not found")
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	roleChanged := req.Role != nil && *req.Role != user.Role
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
 	user.UpdatedAt = time.Now()
 
+	if err := h.store.Users.Update(c.UserContext(), user); err != nil {
+		return storeError(c, err, "User not found")
+	}
+
+	// Sign the user out everywhere so no token outlives the old role
+	if !user.IsActive || roleChanged {
+		if err := h.store.Sessions.RevokeByUser(c.UserContext(), user.ID); err != nil {
+			return storeError(c, err, "")
+		}
+	}
+
 	return c.JSON(user)
 }
 
 // DeleteUser deletes a user (admin only)
-func DeleteUser(c *fiber.Ctx) error {
-	// TODO: Delete from Supabase
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.store.Sessions.RevokeByUser(c.UserContext(), id); err != nil {
+		return storeError(c, err, "")
+	}
+	if err := h.store.Users.Delete(c.UserContext(), id); err != nil {
+		return storeError(c, err, "User not found")
+	}
 	return c.JSON(SuccessResponse{
 		Success: true,
 		Message: "User deleted successfully",
 	})
 }
 
+// RevokeUserSessions signs a user out everywhere (admin only)
+func (h *Handler) RevokeUserSessions(c *fiber.Ctx) error {
+	user, err := h.store.Users.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return storeError(c, err, "User not found")
+	}
+
+	if err := h.store.Sessions.RevokeByUser(c.UserContext(), user.ID); err != nil {
+		return storeError(c, err, "")
+	}
+
+	return c.JSON(SuccessResponse{
+		Success: true,
+		Message: "All sessions revoked",
+	})
+}
+
 // ============ FAVORITES HANDLERS ============
 
 // GetUserFavorites returns user's favorite properties
-func GetUserFavorites(c *fiber.Ctx) error {
-	userID := GetUserFromContext(c)
-	// TODO: Query favorites from Supabase
+func (h *Handler) GetUserFavorites(c *fiber.Ctx) error {
+	favorites, err := h.store.Favorites.ListByUser(c.UserContext(), GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
-	favorites := []Property{}
+	properties := make([]Property, 0, len(favorites))
+	for _, favorite := range favorites {
+		property, err := h.store.Properties.GetByID(c.UserContext(), favorite.PropertyID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return storeError(c, err, "")
+		}
+		properties = append(properties, *property)
+	}
 
 	return c.JSON(ListResponse{
-		Data:  favorites,
-		Total: 0,
+		Data:  properties,
+		Total: len(properties),
 	})
 }
 
 // AddToFavorites adds property to favorites
-func AddToFavorites(c *fiber.Ctx) error {
-	userID := GetUserFromContext(c)
+func (h *Handler) AddToFavorites(c *fiber.Ctx) error {
 	propertyID := c.Params("propertyId")
+	if _, err := h.store.Properties.GetByID(c.UserContext(), propertyID); err != nil {
+		return storeError(c, err, "Property not found")
+	}
 
-	// TODO: Save to Supabase
+	favorite := &Favorite{
+		ID:         uuid.New().String(),
+		UserID:     GetUserFromContext(c),
+		PropertyID: propertyID,
+		CreatedAt:  time.Now(),
+	}
+	if err := h.store.Favorites.Add(c.UserContext(), favorite); err != nil {
+		return storeError(c, err, "")
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
 		Success: true,
@@ -694,11 +3521,13 @@ func AddToFavorites(c *fiber.Ctx) error {
 }
 
 // RemoveFromFavorites removes property from favorites
-func RemoveFromFavorites(c *fiber.Ctx) error {
+func (h *Handler) RemoveFromFavorites(c *fiber.Ctx) error {
 	userID := GetUserFromContext(c)
 	propertyID := c.Params("propertyId")
 
-	// TODO: Delete from Supabase
+	if err := h.store.Favorites.Remove(c.UserContext(), userID, propertyID); err != nil {
+		return storeError(c, err, "Favorite not found")
+	}
 
 	return c.JSON(SuccessResponse{
 		Success: true,
@@ -709,18 +3538,19 @@ func RemoveFromFavorites(c *fiber.Ctx) error {
 // ============ REVIEW HANDLERS ============
 
 // CreateReview creates a property review
-func CreateReview(c *fiber.Ctx) error {
+func (h *Handler) CreateReview(c *fiber.Ctx) error {
 	userID := GetUserFromContext(c)
 	var req ReviewRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid review data",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid review data")
+	}
+	if fields := validateRequest(&req); fields != nil {
+		return validationError(c, fields)
 	}
 
-	// TODO: Save to Supabase
+	if _, err := h.store.Properties.GetByID(c.UserContext(), req.PropertyID); err != nil {
+		return storeError(c, err, "Property not found")
+	}
 
 	review := &Review{
 		ID:         uuid.New().String(),
@@ -729,17 +3559,22 @@ func CreateReview(c *fiber.Ctx) error {
 		Rating:     req.Rating,
 		Comment:    req.Comment,
 		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	if err := h.store.Reviews.Create(c.UserContext(), review); err != nil {
+		return storeError(c, err, "")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(review)
 }
 
 // GetUserReviews returns user's reviews
-func GetUserReviews(c *fiber.Ctx) error {
-	userID := GetUserFromContext(c)
-	// TODO: Query from Supabase
-
-	reviews := []Review{}
+func (h *Handler) GetUserReviews(c *fiber.Ctx) error {
+	reviews, err := h.store.Reviews.ListByUser(c.UserContext(), GetUserFromContext(c))
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
 	return c.JSON(reviews)
 }
@@ -747,48 +3582,65 @@ func GetUserReviews(c *fiber.Ctx) error {
 // ============ ADMIN DASHBOARD HANDLERS ============
 
 // GetDashboardStats returns dashboard statistics
-func GetDashboardStats(c *fiber.Ctx) error {
-	// TODO: Query stats from Supabase
-
-	stats := &DashboardStats{
-		TotalProperties:       2,
-		TotalBlogPosts:        3,
-		TotalContacts:         15,
-		UnreadContacts:        3,
-		NewsletterSubscribers: 250,
-		RegisteredUsers:       45,
-		LastUpdated:           time.Now(),
+func (h *Handler) GetDashboardStats(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	recent := PaginationParams{Page: 1, Limit: 5}
+	stats := &DashboardStats{LastUpdated: time.Now()}
+
+	var err error
+	if stats.RecentProperties, stats.TotalProperties, err = h.store.Properties.List(ctx, PropertyFilter{PaginationParams: recent}); err != nil {
+		return storeError(c, err, "")
+	}
+	if stats.RecentBlogPosts, stats.TotalBlogPosts, err = h.store.Blog.List(ctx, BlogFilter{PaginationParams: recent}); err != nil {
+		return storeError(c, err, "")
+	}
+	if stats.RecentContacts, stats.TotalContacts, err = h.store.Contacts.List(ctx, ContactFilter{PaginationParams: recent}); err != nil {
+		return storeError(c, err, "")
+	}
+	if _, stats.UnreadContacts, err = h.store.Contacts.List(ctx, ContactFilter{PaginationParams: PaginationParams{Page: 1, Limit: 1}, UnreadOnly: true}); err != nil {
+		return storeError(c, err, "")
+	}
+	if _, stats.NewsletterSubscribers, err = h.store.Newsletter.List(ctx, NewsletterFilter{PaginationParams: PaginationParams{Page: 1, Limit: 1}, ActiveOnly: true}); err != nil {
+		return storeError(c, err, "")
+	}
+	if _, stats.RegisteredUsers, err = h.store.Users.List(ctx, PaginationParams{Page: 1, Limit: 1}); err != nil {
+		return storeError(c, err, "")
+	}
+	if _, stats.TotalLeads, err = h.store.Leads.List(ctx, LeadFilter{PaginationParams: PaginationParams{Page: 1, Limit: 1}}); err != nil {
+		return storeError(c, err, "")
+	}
+	if stats.TopBrochures, err = h.store.Brochures.Stats(ctx, recent.Limit); err != nil {
+		return storeError(c, err, "")
 	}
 
 	return c.JSON(stats)
 }
 
 // GetRecentContacts returns recent contact submissions
-func GetRecentContacts(c *fiber.Ctx) error {
-	limit, _ := strconv.Atoi(c.Query("limit", "5"))
+func (h *Handler) GetRecentContacts(c *fiber.Ctx) error {
+	page := paginationFromQuery(c, 5)
+	page.Page = 1
 
-	// TODO: Query from Supabase
-	contacts := []ContactSubmission{}
+	contacts, total, err := h.store.Contacts.List(c.UserContext(), ContactFilter{PaginationParams: page})
+	if err != nil {
+		return storeError(c, err, "")
+	}
 
 	return c.JSON(ListResponse{
 		Data:  contacts,
-		Limit: limit,
-		Total: 0,
+		Limit: page.Limit,
+		Total: total,
 	})
 }
 
 // ============ IMAGE UPLOAD HANDLER ============
 
 // UploadImage handles image uploads to Supabase storage
-func UploadImage(c *fiber.Ctx) error {
+func (h *Handler) UploadImage(c *fiber.Ctx) error {
 	// Get file from request
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "File is required",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "File is required")
 	}
 
 	// Validate file type
@@ -798,11 +3650,7 @@ func UploadImage(c *fiber.Ctx) error {
 		"image/webp": true,
 	}
 	if !allowedTypes[file.Header.Get("Content-Type")] {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Only JPEG, PNG, and WebP images are allowed",
-			Code:    fiber.StatusBadRequest,
-		})
+		return errorJSON(c, fiber.StatusBadRequest, "Only JPEG, PNG, and WebP images are allowed")
 	}
 
 	// TODO: Upload to Supabase storage
@@ -0,0 +1,822 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// newTestApp serves the API routes of a handler backed by a memory store,
+// wired the same way as main
+func newTestApp(t *testing.T) (*fiber.App, *Handler) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	h, err := NewHandler(NewMemoryStore(), newTestMail(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New(fiber.Config{Immutable: true})
+	api := app.Group("/api/v1")
+	setupPublicRoutes(api, h)
+	api.Use(h.AuthMiddleware)
+	setupProtectedRoutes(api, h)
+	admin := api.Group("/admin", AdminMiddleware)
+	setupAdminRoutes(admin, h)
+	return app, h
+}
+
+// newTestMail returns a mail service whose queue is never delivered, so
+// tests can inspect what was queued with sentMail. Messages delivered
+// directly are kept by its CaptureMailer.
+func newTestMail(t *testing.T) *MailService {
+	t.Helper()
+	templates, err := LoadEmailTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer := &CaptureMailer{}
+	return &MailService{mailer: mailer, queue: NewMailQueue(mailer, 100, 1, 0), templates: templates}
+}
+
+// sentMail removes and returns the messages queued by h so far
+func sentMail(h *Handler) []Message {
+	var messages []Message
+	for {
+		select {
+		case msg := <-h.mail.queue.jobs:
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+// call sends a JSON request to app, authorized by token when it is not
+// empty, and decodes the response body into out when out is not nil. It
+// returns the response status.
+func call(t *testing.T, app *fiber.App, method, path, token string, body, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding %d response: %v", method, path, resp.StatusCode, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// createUser stores an account with the given role and password
+func createUser(t *testing.T, h *Handler, email, password, role string, active bool) *User {
+	t.Helper()
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &User{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Password:  hash,
+		FirstName: "Test",
+		Role:      role,
+		IsActive:  active,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := h.store.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestLogin(t *testing.T) {
+	app, h := newTestApp(t)
+	createUser(t, h, "admin@example.com", "admin-pass", "admin", true)
+	createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	createUser(t, h, "gone@example.com", "gone-pass", "user", false)
+
+	tests := []struct {
+		name       string
+		path       string
+		email      string
+		password   string
+		wantStatus int
+	}{
+		{"admin", "/api/v1/auth/login", "admin@example.com", "admin-pass", fiber.StatusOK},
+		{"email is trimmed and case-insensitive", "/api/v1/auth/login", "  Admin@Example.COM ", "admin-pass", fiber.StatusOK},
+		{"wrong password", "/api/v1/auth/login", "admin@example.com", "admin-pass2", fiber.StatusUnauthorized},
+		{"unknown account", "/api/v1/auth/login", "nobody@example.com", "admin-pass", fiber.StatusUnauthorized},
+		{"missing password", "/api/v1/auth/login", "admin@example.com", "", fiber.StatusUnprocessableEntity},
+		{"user on the admin login", "/api/v1/auth/login", "buyer@example.com", "buyer-pass", fiber.StatusForbidden},
+		{"user login", "/api/v1/auth/user/login", "buyer@example.com", "buyer-pass", fiber.StatusOK},
+		{"disabled account", "/api/v1/auth/user/login", "gone@example.com", "gone-pass", fiber.StatusForbidden},
+		{"disabled account, wrong password", "/api/v1/auth/user/login", "gone@example.com", "not-gone-pass", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp AuthResponse
+			status := call(t, app, "POST", tt.path, "", LoginRequest{Email: tt.email, Password: tt.password}, &resp)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if status != fiber.StatusOK {
+				return
+			}
+			claims, err := h.VerifyToken(resp.AccessToken)
+			if err != nil {
+				t.Fatalf("access token: %v", err)
+			}
+			if claims.Email != resp.User.Email || claims.Role != resp.User.Role {
+				t.Errorf("token is for %s (%s), user is %s (%s)", claims.Email, claims.Role, resp.User.Email, resp.User.Role)
+			}
+		})
+	}
+}
+
+func TestEnsureAdminUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	t.Setenv("ADMIN_EMAIL", " Owner@Example.com ")
+	t.Setenv("ADMIN_PASSWORD", "first-password")
+	if err := EnsureAdminUser(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := store.Users.GetByEmail(ctx, "owner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != "admin" || !admin.IsActive {
+		t.Errorf("admin is %s, active %v", admin.Role, admin.IsActive)
+	}
+	if admin.Password == "first-password" || !CheckPassword("first-password", admin.Password) {
+		t.Error("the admin password is not stored as a hash of ADMIN_PASSWORD")
+	}
+
+	// An existing admin keeps their password when the server restarts
+	t.Setenv("ADMIN_PASSWORD", "second-password")
+	if err := EnsureAdminUser(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	again, err := store.Users.GetByEmail(ctx, "owner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != admin.ID || !CheckPassword("first-password", again.Password) {
+		t.Error("EnsureAdminUser replaced the existing admin")
+	}
+}
+
+func TestSignupUser(t *testing.T) {
+	app, h := newTestApp(t)
+	signup := SignupRequest{Email: " New.Buyer@Example.com", Password: "s3cret-pass", FirstName: "Ada", LastName: "Obi"}
+
+	var resp AuthResponse
+	if status := call(t, app, "POST", "/api/v1/auth/signup", "", signup, &resp); status != fiber.StatusCreated {
+		t.Fatalf("signup status = %d, want 201", status)
+	}
+	if resp.AccessToken == "" || resp.RefreshToken == "" {
+		t.Error("signup did not return tokens")
+	}
+	if resp.User.Role != "user" || resp.User.Email != "new.buyer@example.com" {
+		t.Errorf("signed up as %s (%s)", resp.User.Email, resp.User.Role)
+	}
+	if welcome := sentMail(h); len(welcome) != 1 || welcome[0].To[0] != "new.buyer@example.com" || !strings.Contains(welcome[0].Text, "Ada") {
+		t.Errorf("welcome emails = %+v, want one to the new buyer", welcome)
+	}
+
+	stored, err := h.store.Users.GetByEmail(context.Background(), "new.buyer@example.com")
+	if err != nil {
+		t.Fatalf("signup was not stored: %v", err)
+	}
+	if stored.Password == signup.Password || !CheckPassword(signup.Password, stored.Password) {
+		t.Error("the password is not stored as a bcrypt hash")
+	}
+
+	login := LoginRequest{Email: "new.buyer@example.com", Password: "s3cret-pass"}
+	if status := call(t, app, "POST", "/api/v1/auth/user/login", "", login, nil); status != fiber.StatusOK {
+		t.Errorf("login after signup status = %d, want 200", status)
+	}
+
+	duplicate := signup
+	duplicate.Email = "NEW.BUYER@example.com"
+	if status := call(t, app, "POST", "/api/v1/auth/signup", "", duplicate, nil); status != fiber.StatusConflict {
+		t.Errorf("duplicate signup status = %d, want 409", status)
+	}
+	if extra := sentMail(h); len(extra) != 0 {
+		t.Errorf("duplicate signup sent %d emails", len(extra))
+	}
+
+	for _, bad := range []SignupRequest{
+		{Email: "not-an-email", Password: "s3cret-pass", FirstName: "A", LastName: "B"},
+		{Email: "short@example.com", Password: "12345", FirstName: "A", LastName: "B"},
+		{Email: "nameless@example.com", Password: "s3cret-pass"},
+	} {
+		if status := call(t, app, "POST", "/api/v1/auth/signup", "", bad, nil); status < 400 || status >= 500 {
+			t.Errorf("signup %+v status = %d, want a 4xx", bad, status)
+		}
+		if _, err := h.store.Users.GetByEmail(context.Background(), bad.Email); err == nil {
+			t.Errorf("invalid signup %s was stored", bad.Email)
+		}
+	}
+}
+
+// loginAs signs email in through the user login and returns the token pair
+func loginAs(t *testing.T, app *fiber.App, email, password string) AuthResponse {
+	t.Helper()
+	var resp AuthResponse
+	if status := call(t, app, "POST", "/api/v1/auth/user/login", "", LoginRequest{Email: email, Password: password}, &resp); status != fiber.StatusOK {
+		t.Fatalf("login as %s: status %d", email, status)
+	}
+	return resp
+}
+
+// adminToken creates an admin account and returns an access token for it
+func adminToken(t *testing.T, app *fiber.App, h *Handler) string {
+	t.Helper()
+	createUser(t, h, "admin@example.com", "admin-pass", "admin", true)
+	var resp AuthResponse
+	if status := call(t, app, "POST", "/api/v1/auth/login", "", LoginRequest{Email: "admin@example.com", Password: "admin-pass"}, &resp); status != fiber.StatusOK {
+		t.Fatalf("admin login: status %d", status)
+	}
+	return resp.AccessToken
+}
+
+// refresh presents a refresh token and returns the status and new token pair
+func refresh(t *testing.T, app *fiber.App, token string) (int, AuthResponse) {
+	t.Helper()
+	var resp AuthResponse
+	status := call(t, app, "POST", "/api/v1/auth/refresh", "", fiber.Map{"refresh_token": token}, &resp)
+	return status, resp
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	app, h := newTestApp(t)
+	createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	first := loginAs(t, app, "buyer@example.com", "buyer-pass")
+
+	status, second := refresh(t, app, first.RefreshToken)
+	if status != fiber.StatusOK {
+		t.Fatalf("refresh status = %d, want 200", status)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh did not rotate the refresh token")
+	}
+	if status := call(t, app, "GET", "/api/v1/me", second.AccessToken, nil, nil); status != fiber.StatusOK {
+		t.Errorf("rotated access token: status %d, want 200", status)
+	}
+
+	// The rotated token keeps working until it is itself rotated
+	if status, _ := refresh(t, app, second.RefreshToken); status != fiber.StatusOK {
+		t.Errorf("second refresh status = %d, want 200", status)
+	}
+
+	// An access token is not accepted as a refresh token, nor the reverse
+	if status, _ := refresh(t, app, first.AccessToken); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh with an access token: status %d, want 401", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", second.RefreshToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh token as bearer: status %d, want 401", status)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	app, h := newTestApp(t)
+	createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	stolen := loginAs(t, app, "buyer@example.com", "buyer-pass")
+	other := loginAs(t, app, "buyer@example.com", "buyer-pass")
+
+	status, rotated := refresh(t, app, stolen.RefreshToken)
+	if status != fiber.StatusOK {
+		t.Fatalf("refresh status = %d, want 200", status)
+	}
+
+	// Replaying the already rotated token revokes the whole family
+	if status, _ := refresh(t, app, stolen.RefreshToken); status != fiber.StatusUnauthorized {
+		t.Fatalf("replayed refresh token: status %d, want 401", status)
+	}
+	if status, _ := refresh(t, app, rotated.RefreshToken); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh after reuse: status %d, want 401", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", rotated.AccessToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("access token after reuse: status %d, want 401", status)
+	}
+
+	// Sessions opened by other logins are unaffected
+	if status := call(t, app, "GET", "/api/v1/me", other.AccessToken, nil, nil); status != fiber.StatusOK {
+		t.Errorf("other session after reuse: status %d, want 200", status)
+	}
+}
+
+func TestLogoutAndRevocation(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	buyer := createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+
+	phone := loginAs(t, app, "buyer@example.com", "buyer-pass")
+	laptop := loginAs(t, app, "buyer@example.com", "buyer-pass")
+	if status := call(t, app, "POST", "/api/v1/auth/logout", phone.AccessToken, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("logout status = %d, want 200", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", phone.AccessToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("access token after logout: status %d, want 401", status)
+	}
+	if status, _ := refresh(t, app, phone.RefreshToken); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh after logout: status %d, want 401", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", laptop.AccessToken, nil, nil); status != fiber.StatusOK {
+		t.Errorf("logout signed out another session: status %d", status)
+	}
+
+	if status := call(t, app, "POST", "/api/v1/admin/users/"+buyer.ID+"/revoke-sessions", token, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("revoke sessions status = %d, want 200", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", laptop.AccessToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("access token after revoke-sessions: status %d, want 401", status)
+	}
+	if status, _ := refresh(t, app, laptop.RefreshToken); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh after revoke-sessions: status %d, want 401", status)
+	}
+}
+
+func TestNewHandlerRequiresJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	if _, err := NewHandler(NewMemoryStore(), newTestMail(t)); err == nil {
+		t.Fatal("NewHandler succeeded without JWT_SECRET")
+	}
+}
+
+func TestAccessUsesStoredAccount(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	agent := createUser(t, h, "agent@example.com", "agent-pass", "admin", true)
+	buyer := createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	agentLogin := loginAs(t, app, "agent@example.com", "agent-pass")
+	buyerLogin := loginAs(t, app, "buyer@example.com", "buyer-pass")
+
+	// A demoted admin loses admin access at once, even with an unexpired token
+	role := "user"
+	if status := call(t, app, "PUT", "/api/v1/admin/users/"+agent.ID, token, UpdateUserRequest{Role: &role}, nil); status != fiber.StatusOK {
+		t.Fatalf("demote status = %d, want 200", status)
+	}
+	if status := call(t, app, "GET", "/api/v1/admin/users", agentLogin.AccessToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("old admin token after demotion: status %d, want 401", status)
+	}
+	if status, _ := refresh(t, app, agentLogin.RefreshToken); status != fiber.StatusUnauthorized {
+		t.Errorf("refresh after demotion: status %d, want 401", status)
+	}
+	relogin := loginAs(t, app, "agent@example.com", "agent-pass")
+	if status := call(t, app, "GET", "/api/v1/admin/users", relogin.AccessToken, nil, nil); status != fiber.StatusForbidden {
+		t.Errorf("demoted admin on admin routes: status %d, want 403", status)
+	}
+
+	// A deactivated account is locked out even if its session survived
+	buyer.IsActive = false
+	if err := h.store.Users.Update(context.Background(), buyer); err != nil {
+		t.Fatal(err)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", buyerLogin.AccessToken, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("deactivated account: status %d, want 401", status)
+	}
+
+	// Tokens signed with another secret are rejected
+	other := &Handler{jwtSecret: []byte("another-secret")}
+	forged, err := other.GenerateAccessToken(&User{ID: agent.ID, Email: agent.Email, Role: "admin"}, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := call(t, app, "GET", "/api/v1/me", forged, nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("token with another secret: status %d, want 401", status)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	numbers := []Plot{{PlotNumber: "B-10"}, {PlotNumber: "A-2"}, {PlotNumber: "B-9"}, {PlotNumber: "A-010"}, {PlotNumber: "A-1"}, {PlotNumber: "B"}}
+	sortPlots(numbers)
+	got := make([]string, len(numbers))
+	for i, p := range numbers {
+		got[i] = p.PlotNumber
+	}
+	want := []string{"A-1", "A-2", "A-010", "B", "B-9", "B-10"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sorted plots = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPlotInventory(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+
+	var property Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Unity Estate", Status: "available", Units: 99}, &property)
+	base := "/api/v1/admin/properties/" + property.ID + "/plots"
+
+	// Without plots the units and status stay as the admin set them
+	if got, _ := h.store.Properties.GetByID(context.Background(), property.ID); got.Units != 99 {
+		t.Fatalf("units = %d before any plots, want 99", got.Units)
+	}
+
+	var plots []Plot
+	batch := BulkCreatePlotsRequest{Plots: []PlotRequest{
+		{PlotNumber: "10", SizeSqm: 500, Price: 1000},
+		{PlotNumber: "2", SizeSqm: 450, Price: 900},
+		{PlotNumber: "1", SizeSqm: 600, Price: 1200, IsCorner: true},
+	}}
+	if status := call(t, app, "POST", base, token, batch, &plots); status != fiber.StatusCreated {
+		t.Fatalf("create plots status = %d, want 201", status)
+	}
+	if len(plots) != 3 || plots[0].PlotNumber != "1" || plots[2].PlotNumber != "10" {
+		t.Fatalf("created plots = %+v", plots)
+	}
+
+	inventory := func() (int, string) {
+		t.Helper()
+		p, err := h.store.Properties.GetByID(context.Background(), property.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.Units, p.Status
+	}
+	if units, status := inventory(); units != 3 || status != "available" {
+		t.Errorf("after adding plots: %d units, %s", units, status)
+	}
+
+	duplicate := BulkCreatePlotsRequest{Plots: []PlotRequest{{PlotNumber: "2", SizeSqm: 450, Price: 900}}}
+	if status := call(t, app, "POST", base, token, duplicate, nil); status != fiber.StatusConflict {
+		t.Errorf("duplicate plot number status = %d, want 409", status)
+	}
+
+	setStatus := func(plot Plot, status string) {
+		t.Helper()
+		if code := call(t, app, "PUT", base+"/"+plot.ID, token, UpdatePlotRequest{Status: &status}, nil); code != fiber.StatusOK {
+			t.Fatalf("set plot %s %s: status %d", plot.PlotNumber, status, code)
+		}
+	}
+	setStatus(plots[0], "sold")
+	setStatus(plots[1], "reserved")
+	if units, status := inventory(); units != 1 || status != "available" {
+		t.Errorf("one plot left: %d units, %s", units, status)
+	}
+	setStatus(plots[2], "sold")
+	if units, status := inventory(); units != 0 || status != "pending" {
+		t.Errorf("only reserved plots left: %d units, %s, want pending", units, status)
+	}
+	setStatus(plots[1], "sold")
+	if units, status := inventory(); units != 0 || status != "sold" {
+		t.Errorf("all plots sold: %d units, %s", units, status)
+	}
+
+	var available []Plot
+	call(t, app, "GET", "/api/v1/properties/"+property.ID+"/plots", "", nil, &available)
+	if len(available) != 0 {
+		t.Errorf("public plot list shows %d sold plots", len(available))
+	}
+
+	if code := call(t, app, "DELETE", base+"/"+plots[1].ID, token, nil, nil); code != fiber.StatusOK {
+		t.Fatalf("delete plot status = %d", code)
+	}
+	var other Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Elsewhere"}, &other)
+	if code := call(t, app, "PUT", "/api/v1/admin/properties/"+other.ID+"/plots/"+plots[0].ID, token, UpdatePlotRequest{}, nil); code != fiber.StatusNotFound {
+		t.Errorf("plot through another property: status %d, want 404", code)
+	}
+}
+
+func TestScheduleQuoteStartDate(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+
+	var property Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Unity Estate", Status: "available"}, &property)
+	var plots []Plot
+	call(t, app, "POST", "/api/v1/admin/properties/"+property.ID+"/plots", token,
+		BulkCreatePlotsRequest{Plots: []PlotRequest{{PlotNumber: "1", SizeSqm: 500, Price: 1000}}}, &plots)
+	active := true
+	var plan PaymentPlan
+	if status := call(t, app, "POST", "/api/v1/admin/properties/"+property.ID+"/payment-plans", token,
+		PaymentPlanRequest{Name: "Quarterly", DepositPercent: 20, Months: 3, IsActive: &active}, &plan); status != fiber.StatusCreated {
+		t.Fatalf("create plan status = %d, want 201", status)
+	}
+	quote := "/api/v1/properties/" + property.ID + "/payment-plans/" + plan.ID + "/schedule?plot_id=" + plots[0].ID
+
+	var schedule PaymentSchedule
+	if status := call(t, app, "GET", quote+"&start_date=2025-01-15", "", nil, &schedule); status != fiber.StatusOK {
+		t.Fatalf("quote status = %d, want 200", status)
+	}
+	if want := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC); !schedule.StartDate.Equal(want) {
+		t.Errorf("start date = %v, want %v", schedule.StartDate, want)
+	}
+
+	for _, date := range []string{"2025-02-30", "15/01/2025", "tomorrow"} {
+		var resp ErrorResponse
+		status := call(t, app, "GET", quote+"&start_date="+url.QueryEscape(date), "", nil, &resp)
+		if status != fiber.StatusUnprocessableEntity || len(resp.Fields) != 1 || resp.Fields[0].Field != "start_date" {
+			t.Errorf("start_date %q: status %d, fields %+v", date, status, resp.Fields)
+		}
+	}
+}
+
+func TestBrochureRequests(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	buyer := createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+
+	var property Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Unity Estate"}, &property)
+
+	request := func(email string) DocumentLink {
+		t.Helper()
+		var resp struct {
+			Data DocumentLink `json:"data"`
+		}
+		body := fiber.Map{"property_id": property.ID, "email": email}
+		if status := call(t, app, "POST", "/api/v1/brochure/download", "", body, &resp); status != fiber.StatusCreated {
+			t.Fatalf("brochure request for %s: status %d", email, status)
+		}
+		return resp.Data
+	}
+	download := func(link string) int {
+		t.Helper()
+		signed, err := url.Parse(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := app.Test(httptest.NewRequest("GET", signed.RequestURI(), nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	first := request("Buyer@Example.com")
+	mail := sentMail(h)
+	if len(mail) != 1 || mail[0].To[0] != "buyer@example.com" || mail[0].Subject != "Your Unity Estate brochure" {
+		t.Fatalf("brochure email = %+v", mail)
+	}
+	if !strings.Contains(mail[0].Text, first.URL) || !strings.Contains(mail[0].HTML, html.EscapeString(first.URL)) {
+		t.Error("the brochure email does not contain the download link")
+	}
+	request("prospect@example.com")
+	request("buyer@example.com")
+	if first.ExpiresAt.Before(time.Now().Add(71 * time.Hour)) {
+		t.Errorf("brochure link expires at %v, want about 72 hours from now", first.ExpiresAt)
+	}
+
+	for i := 0; i < 2; i++ {
+		if status := download(first.URL); status != fiber.StatusOK {
+			t.Fatalf("download %d: status %d", i+1, status)
+		}
+	}
+	if status := download(first.URL + "x"); status != fiber.StatusForbidden {
+		t.Errorf("download with a damaged signature: status %d, want 403", status)
+	}
+
+	var stats []BrochureStats
+	call(t, app, "GET", "/api/v1/admin/brochures/stats", token, nil, &stats)
+	if len(stats) != 1 || stats[0].PropertyID != property.ID || stats[0].Requests != 3 || stats[0].Downloads != 2 {
+		t.Errorf("brochure stats = %+v, want 3 requests and 2 downloads", stats)
+	}
+
+	var leads struct {
+		Data  []Lead `json:"data"`
+		Total int    `json:"total"`
+	}
+	call(t, app, "GET", "/api/v1/admin/leads", token, nil, &leads)
+	if leads.Total != 2 {
+		t.Fatalf("leads = %+v, want one per email", leads.Data)
+	}
+	for _, lead := range leads.Data {
+		switch lead.Email {
+		case "buyer@example.com":
+			if lead.RequestCount != 2 || lead.UserID == nil || *lead.UserID != buyer.ID {
+				t.Errorf("account lead = %+v, want 2 requests attributed to the buyer", lead)
+			}
+		case "prospect@example.com":
+			if lead.RequestCount != 1 || lead.UserID != nil || lead.Source != leadSourceBrochure {
+				t.Errorf("prospect lead = %+v", lead)
+			}
+		default:
+			t.Errorf("unexpected lead %s", lead.Email)
+		}
+	}
+
+	body := fiber.Map{"property_id": uuid.New().String(), "email": "buyer@example.com"}
+	if status := call(t, app, "POST", "/api/v1/brochure/download", "", body, nil); status != fiber.StatusNotFound {
+		t.Errorf("brochure of an unknown property: status %d, want 404", status)
+	}
+}
+
+func TestContactNotifications(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "office@example.com")
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	agent := createUser(t, h, "agent@example.com", "agent-pass", "admin", true)
+	buyer := createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+
+	if status := call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Bad Agent", AgentID: &buyer.ID}, nil); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("property with a non-admin agent: status %d, want 422", status)
+	}
+	var property Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Unity Estate", AgentID: &agent.ID}, &property)
+
+	submit := func(propertyID *string) map[string]Message {
+		t.Helper()
+		form := ContactFormRequest{FirstName: "Ada", LastName: "Obi", Email: "ada@example.com", Phone: "0800", Message: "Is plot 4 still available?", PropertyID: propertyID}
+		if status := call(t, app, "POST", "/api/v1/contact", "", form, nil); status != fiber.StatusCreated && status != fiber.StatusOK {
+			t.Fatalf("contact status = %d", status)
+		}
+		byRecipient := map[string]Message{}
+		for _, msg := range sentMail(h) {
+			byRecipient[msg.To[0]] = msg
+		}
+		return byRecipient
+	}
+
+	mail := submit(&property.ID)
+	notification, ok := mail["agent@example.com"]
+	if !ok || len(mail) != 2 {
+		t.Fatalf("enquiry about a property with an agent sent %v", mail)
+	}
+	if notification.ReplyTo != "ada@example.com" || !strings.Contains(notification.Text, "Unity Estate") {
+		t.Errorf("agent notification = %+v", notification)
+	}
+	ack, ok := mail["ada@example.com"]
+	if !ok {
+		t.Fatal("the enquirer was not acknowledged")
+	}
+	if strings.Contains(ack.Text, "plot 4") || strings.Contains(ack.HTML, "plot 4") {
+		t.Error("the acknowledgement echoes the message back to an unverified address")
+	}
+
+	// A second enquiry within the hour still reaches the office, but the
+	// enquirer is not acknowledged again
+	mail = submit(nil)
+	if _, ok := mail["office@example.com"]; !ok || len(mail) != 1 {
+		t.Errorf("general enquiry sent %v, want only ADMIN_EMAIL", mail)
+	}
+}
+
+// emailLink returns the path and query of the first link to path in msg
+func emailLink(t *testing.T, msg Message, path string) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Text) {
+		if link, err := url.Parse(field); err == nil && link.Path == path {
+			return link.RequestURI()
+		}
+	}
+	t.Fatalf("email %q has no %s link:\n%s", msg.Subject, path, msg.Text)
+	return ""
+}
+
+func TestNewsletterDoubleOptIn(t *testing.T) {
+	app, h := newTestApp(t)
+	ctx := context.Background()
+	h.newsletterResendInterval = 0 // see TestNewsletterConfirmationThrottle
+	subscribe := func() []Message {
+		t.Helper()
+		body := NewsletterRequest{Email: "Reader@Example.com", Name: "Reader"}
+		if status := call(t, app, "POST", "/api/v1/newsletter/subscribe", "", body, nil); status != fiber.StatusAccepted {
+			t.Fatalf("subscribe status = %d, want 202", status)
+		}
+		return sentMail(h)
+	}
+	status := func() string {
+		t.Helper()
+		subscriber, err := h.store.Newsletter.GetByEmail(ctx, "reader@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return subscriber.Status
+	}
+
+	mail := subscribe()
+	if len(mail) != 1 || status() != SubscriberPending {
+		t.Fatalf("new subscription: %d emails, status %s", len(mail), status())
+	}
+	confirm := emailLink(t, mail[0], "/api/v1/newsletter/confirm")
+	unsubscribe := emailLink(t, mail[0], "/api/v1/newsletter/unsubscribe")
+
+	if code := call(t, app, "GET", confirm+"x", "", nil, nil); code != fiber.StatusForbidden {
+		t.Errorf("damaged confirmation link: status %d, want 403", code)
+	}
+	if code := call(t, app, "GET", confirm, "", nil, nil); code != fiber.StatusOK || status() != SubscriberActive {
+		t.Fatalf("confirm: status %d, subscriber %s", code, status())
+	}
+	if mail := subscribe(); len(mail) != 0 || status() != SubscriberActive {
+		t.Errorf("subscribing while active: %d emails, status %s", len(mail), status())
+	}
+
+	if code := call(t, app, "POST", unsubscribe, "", nil, nil); code != fiber.StatusOK || status() != SubscriberUnsubscribed {
+		t.Fatalf("unsubscribe: status %d, subscriber %s", code, status())
+	}
+	if code := call(t, app, "GET", confirm, "", nil, nil); code != fiber.StatusGone || status() != SubscriberUnsubscribed {
+		t.Errorf("old confirmation link after unsubscribing: status %d, subscriber %s", code, status())
+	}
+
+	if mail := subscribe(); len(mail) != 1 || status() != SubscriberPending {
+		t.Errorf("resubscribing: %d emails, status %s", len(mail), status())
+	}
+}
+
+func TestNewsletterConfirmationThrottle(t *testing.T) {
+	app, h := newTestApp(t)
+	ctx := context.Background()
+	subscribe := func(name, interest string) int {
+		t.Helper()
+		body := NewsletterRequest{Email: "target@example.com", Name: name, Interests: []string{interest}}
+		if status := call(t, app, "POST", "/api/v1/newsletter/subscribe", "", body, nil); status != fiber.StatusAccepted {
+			t.Fatalf("subscribe status = %d, want 202", status)
+		}
+		return len(sentMail(h))
+	}
+
+	if n := subscribe("First", "Land"); n != 1 {
+		t.Fatalf("first subscription sent %d emails, want 1", n)
+	}
+	for i := 0; i < 5; i++ {
+		if n := subscribe("Again", "Homes"); n != 0 {
+			t.Fatalf("repeat subscription %d sent %d emails, want none within the resend interval", i, n)
+		}
+	}
+	subscriber, err := h.store.Newsletter.GetByEmail(ctx, "target@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscriber.Name != "Again" || len(subscriber.Interests) != 2 {
+		t.Errorf("throttled subscription kept name %q and interests %v, want the update", subscriber.Name, subscriber.Interests)
+	}
+
+	// Once the interval has passed a new confirmation goes out
+	sentAt := time.Now().Add(-h.newsletterResendInterval - time.Minute)
+	subscriber.ConfirmationSentAt = &sentAt
+	if err := h.store.Newsletter.Update(ctx, subscriber); err != nil {
+		t.Fatal(err)
+	}
+	if n := subscribe("Later", "Land"); n != 1 {
+		t.Errorf("subscription after the interval sent %d emails, want 1", n)
+	}
+
+	// A throttled post must not turn an unsubscribed reader into a pending one
+	subscriber, _ = h.store.Newsletter.GetByEmail(ctx, "target@example.com")
+	subscriber.Status = SubscriberUnsubscribed
+	if err := h.store.Newsletter.Update(ctx, subscriber); err != nil {
+		t.Fatal(err)
+	}
+	if n := subscribe("Spoof", "Homes"); n != 0 {
+		t.Fatalf("throttled resubscription sent %d emails, want none", n)
+	}
+	if got, _ := h.store.Newsletter.GetByEmail(ctx, "target@example.com"); got.Status != SubscriberUnsubscribed {
+		t.Errorf("status after a throttled resubscription = %s, want unsubscribed", got.Status)
+	}
+}
+
+func TestSubscriberEmailParamIgnoresCase(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	addSubscriber(t, h, "reader@example.com", SubscriberActive)
+	path := "/api/v1/admin/newsletter/subscribers/Reader@Example.COM"
+
+	var subscriber NewsletterSubscriber
+	if status := call(t, app, "PUT", path, token, SubscriberRequest{Tags: []string{"VIP"}}, &subscriber); status != fiber.StatusOK {
+		t.Fatalf("update status = %d, want 200", status)
+	}
+	if len(subscriber.Tags) != 1 || subscriber.Tags[0] != "vip" {
+		t.Errorf("tags = %v, want [vip]", subscriber.Tags)
+	}
+	if status := call(t, app, "DELETE", path, token, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("unsubscribe status = %d, want 200", status)
+	}
+	if got, _ := h.store.Newsletter.GetByEmail(context.Background(), "reader@example.com"); got.Status != SubscriberUnsubscribed {
+		t.Errorf("status = %s, want unsubscribed", got.Status)
+	}
+}
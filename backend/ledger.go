@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// formatReceiptNumber renders the nth receipt number. Migration 0018 builds
+// the same format in the receipt_number column default.
+func formatReceiptNumber(n int64) string {
+	return fmt.Sprintf("RCP-%06d", n)
+}
+
+// toMinor converts an amount to kobo or cents so sums do not drift
+func toMinor(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// ComputeBalance works out how much of a schedule the recorded payments
+// cover. Payments settle installments oldest first; the next due installment
+// is the earliest one not fully paid, which may already be overdue.
+func ComputeBalance(schedule PaymentSchedule, payments []Payment, now time.Time) Balance {
+	paid := int64(0)
+	for _, p := range payments {
+		if p.Status == "recorded" {
+			paid += toMinor(p.Amount)
+		}
+	}
+	total := toMinor(schedule.TotalAmount)
+
+	balance := Balance{
+		TotalAmount: schedule.TotalAmount,
+		AmountPaid:  fromMinor(paid),
+		Outstanding: fromMinor(max(total-paid, 0)),
+	}
+
+	today := dateOnly(now)
+	due, overdue := int64(0), int64(0)
+	for _, inst := range schedule.Installments {
+		due += toMinor(inst.Amount)
+		if due <= paid {
+			continue
+		}
+		unpaid := min(toMinor(inst.Amount), due-paid)
+		if balance.NextDueDate == nil {
+			date := inst.DueDate
+			balance.NextDueDate = &date
+			balance.NextDueAmount = fromMinor(unpaid)
+		}
+		if inst.DueDate.Before(today) {
+			overdue += unpaid
+		}
+	}
+	balance.OverdueAmount = fromMinor(overdue)
+	return balance
+}
+
+// balanceAfter returns what was outstanding on a schedule once payment and
+// every recorded payment before it were counted
+func balanceAfter(schedule PaymentSchedule, payments []Payment, payment Payment) float64 {
+	paid := toMinor(payment.Amount)
+	for _, p := range payments {
+		if p.ID != payment.ID && p.Status == "recorded" && !p.CreatedAt.After(payment.CreatedAt) {
+			paid += toMinor(p.Amount)
+		}
+	}
+	return fromMinor(max(toMinor(schedule.TotalAmount)-paid, 0))
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// testSchedule is a 3,000 schedule of three monthly installments, the first
+// due a month before now
+func testSchedule(now time.Time) PaymentSchedule {
+	start := dateOnly(now).AddDate(0, -1, 0)
+	return PaymentSchedule{
+		ID:          uuid.New().String(),
+		UserID:      uuid.New().String(),
+		PropertyID:  uuid.New().String(),
+		PlanName:    "Three months",
+		TotalAmount: 3000,
+		Installments: []Installment{
+			{Number: 1, DueDate: start, Amount: 1000},
+			{Number: 2, DueDate: start.AddDate(0, 1, 0), Amount: 1000},
+			{Number: 3, DueDate: start.AddDate(0, 2, 0), Amount: 1000},
+		},
+		StartDate: start,
+		CreatedAt: start,
+	}
+}
+
+func TestComputeBalance(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	schedule := testSchedule(now)
+	paid := func(amounts ...float64) []Payment {
+		payments := make([]Payment, len(amounts))
+		for i, amount := range amounts {
+			payments[i] = Payment{Amount: amount, Status: "recorded"}
+		}
+		return payments
+	}
+
+	t.Run("nothing paid", func(t *testing.T) {
+		b := ComputeBalance(schedule, nil, now)
+		if b.Outstanding != 3000 || b.OverdueAmount != 1000 || b.NextDueAmount != 1000 || !b.NextDueDate.Equal(schedule.Installments[0].DueDate) {
+			t.Errorf("balance = %+v", b)
+		}
+	})
+	t.Run("part of the first installment", func(t *testing.T) {
+		b := ComputeBalance(schedule, paid(400.10, 0.2), now)
+		if b.AmountPaid != 400.3 || b.Outstanding != 2599.7 || b.OverdueAmount != 599.7 || b.NextDueAmount != 599.7 {
+			t.Errorf("balance = %+v", b)
+		}
+	})
+	t.Run("first installment settled", func(t *testing.T) {
+		b := ComputeBalance(schedule, paid(1000), now)
+		if b.OverdueAmount != 0 || !b.NextDueDate.Equal(schedule.Installments[1].DueDate) || b.NextDueAmount != 1000 {
+			t.Errorf("balance = %+v", b)
+		}
+	})
+	t.Run("reversed payments do not count", func(t *testing.T) {
+		payments := paid(1000, 500)
+		payments[0].Status = "reversed"
+		if b := ComputeBalance(schedule, payments, now); b.AmountPaid != 500 || b.Outstanding != 2500 {
+			t.Errorf("balance = %+v", b)
+		}
+	})
+	t.Run("fully paid", func(t *testing.T) {
+		b := ComputeBalance(schedule, paid(1500, 1500), now)
+		if b.Outstanding != 0 || b.NextDueDate != nil || b.OverdueAmount != 0 {
+			t.Errorf("balance = %+v", b)
+		}
+	})
+}
+
+func TestRecordPayment(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	now := time.Now()
+	schedule := testSchedule(now)
+	if err := h.store.Schedules.Create(context.Background(), &schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(amount float64, receivedAt time.Time) (int, Payment) {
+		t.Helper()
+		var payment Payment
+		status := call(t, app, "POST", "/api/v1/admin/payments", token, RecordPaymentRequest{
+			ScheduleID: schedule.ID,
+			Amount:     amount,
+			Method:     "bank_transfer",
+			ReceivedAt: receivedAt,
+		}, &payment)
+		return status, payment
+	}
+
+	status, first := record(1200.50, now.Add(-time.Hour))
+	if status != fiber.StatusCreated || first.ReceiptNumber == "" || first.Status != "recorded" || first.UserID != schedule.UserID {
+		t.Fatalf("first payment: status %d, %+v", status, first)
+	}
+
+	var errResp ErrorResponse
+	status = call(t, app, "POST", "/api/v1/admin/payments", token, RecordPaymentRequest{
+		ScheduleID: schedule.ID, Amount: 1799.51, Method: "cash", ReceivedAt: now,
+	}, &errResp)
+	if status != fiber.StatusUnprocessableEntity || len(errResp.Fields) != 1 || errResp.Fields[0].Param != "1799.50" {
+		t.Errorf("overpayment: status %d, fields %+v", status, errResp.Fields)
+	}
+	if status, _ := record(10, now.Add(time.Hour)); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("payment received in the future: status %d, want 422", status)
+	}
+
+	status, second := record(1799.50, now)
+	if status != fiber.StatusCreated || second.ReceiptNumber == first.ReceiptNumber {
+		t.Fatalf("paying the exact remainder: status %d, receipt %s", status, second.ReceiptNumber)
+	}
+	if status, _ := record(0.01, now); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("payment on a settled schedule: status %d, want 422", status)
+	}
+
+	var receipt Receipt
+	call(t, app, "GET", "/api/v1/admin/payments/"+first.ID+"/receipt", token, nil, &receipt)
+	if receipt.BalanceAfter != 1799.5 || receipt.ReceiptNumber != first.ReceiptNumber {
+		t.Errorf("first receipt = %+v", receipt)
+	}
+
+	// Reversing a payment frees up its amount again
+	reverse := ReversePaymentRequest{Reason: "Bounced"}
+	if status := call(t, app, "POST", "/api/v1/admin/payments/"+second.ID+"/reverse", token, reverse, nil); status != fiber.StatusOK {
+		t.Fatalf("reverse status = %d", status)
+	}
+	if status := call(t, app, "POST", "/api/v1/admin/payments/"+second.ID+"/reverse", token, reverse, nil); status != fiber.StatusConflict {
+		t.Errorf("reversing twice: status %d, want 409", status)
+	}
+	if status, _ := record(1799.50, now); status != fiber.StatusCreated {
+		t.Errorf("paying again after a reversal: status %d, want 201", status)
+	}
+}
+
+func TestRecordPaymentConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now()
+	schedule := testSchedule(now)
+	if err := store.Schedules.Create(ctx, &schedule); err != nil {
+		t.Fatal(err)
+	}
+	const admins = 20
+
+	// Each admin checked the balance before any payment went in, so only
+	// the store stands between them and an overpaid schedule
+	var wg sync.WaitGroup
+	errs := make([]error, admins)
+	start := make(chan struct{})
+	for i := 0; i < admins; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = store.Payments.Create(ctx, &Payment{
+				ID:         uuid.New().String(),
+				ScheduleID: schedule.ID,
+				UserID:     schedule.UserID,
+				PropertyID: schedule.PropertyID,
+				Amount:     1000,
+				Method:     "cash",
+				ReceivedAt: now,
+				Status:     "recorded",
+				CreatedAt:  now,
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	recorded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			recorded++
+		case !errors.Is(err, ErrConflict):
+			t.Errorf("admin %d: err = %v, want ErrConflict", i, err)
+		}
+	}
+	if recorded != 3 {
+		t.Errorf("%d payments of 1000 recorded on a 3000 schedule, want 3", recorded)
+	}
+	payments, err := store.Payments.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := ComputeBalance(schedule, payments, now); balance.AmountPaid != 3000 || balance.Outstanding != 0 {
+		t.Errorf("balance = %+v, want 3000 paid", balance)
+	}
+}
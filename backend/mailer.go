@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is one outbound email with an HTML and a plain text body
+type Message struct {
+	To      []string
+	ReplyTo string
+	Subject string
+	HTML    string
+	Text    string
+	// Headers are extra header fields such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Mailer delivers email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailerFromEnv builds the Mailer selected by MAIL_DRIVER: smtp, file
+// (writes .eml files to MAIL_CAPTURE_DIR) or memory. Without MAIL_DRIVER,
+// smtp is used when SMTP_HOST is set. Otherwise mail is only captured in
+// memory with ENV=development, and anywhere else startup fails rather than
+// silently dropping every email.
+func NewMailerFromEnv() (Mailer, error) {
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "noreply@havencommunities.com"
+	}
+
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		switch {
+		case os.Getenv("SMTP_HOST") != "":
+			driver = "smtp"
+		case os.Getenv("ENV") == "development":
+			driver = "memory"
+		default:
+			return nil, fmt.Errorf("MAIL_DRIVER or SMTP_HOST must be set outside ENV=development")
+		}
+	}
+
+	switch driver {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST must be set for MAIL_DRIVER=smtp")
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASS"),
+			From:     from,
+		}, nil
+	case "file":
+		dir := os.Getenv("MAIL_CAPTURE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		return &CaptureMailer{From: from, Dir: dir}, nil
+	case "memory":
+		return &CaptureMailer{From: from}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+// ============ SMTP ============
+
+// SMTPMailer sends through an SMTP server. Port 465 uses implicit TLS; other
+// ports upgrade with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers msg, giving up when ctx is done or after a minute
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	sender, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	body, err := buildMIME(m.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline)
+
+	tlsConfig := &tls.Config{ServerName: m.Host}
+	if m.Port == "465" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// ============ CAPTURE ============
+
+// captureLimit is how many of the latest messages a CaptureMailer keeps
+const captureLimit = 100
+
+// CaptureMailer keeps the latest sent messages in memory instead of
+// delivering them, for development and tests. When Dir is set each message
+// is also written there as an .eml file that mail clients can open.
+type CaptureMailer struct {
+	From string
+	Dir  string
+
+	mu       sync.Mutex
+	messages []Message
+	sent     int
+}
+
+// Send records msg, forgetting the oldest message once captureLimit are kept
+func (m *CaptureMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.messages) == captureLimit {
+		copy(m.messages, m.messages[1:])
+		m.messages = m.messages[:captureLimit-1]
+	}
+	m.messages = append(m.messages, msg)
+	m.sent++
+	if m.Dir == "" {
+		log.Printf("Captured email to %s: %s", strings.Join(msg.To, ", "), msg.Subject)
+		return nil
+	}
+
+	body, err := buildMIME(m.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%03d.eml", time.Now().UTC().Format("20060102T150405"), m.sent)
+	return os.WriteFile(filepath.Join(m.Dir, name), body, 0o644)
+}
+
+// Messages returns the latest captured messages, oldest first
+func (m *CaptureMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// buildMIME renders msg as a multipart/alternative email
+func buildMIME(from string, msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = host
+		}
+	}
+	header := []string{
+		"From: " + from,
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: <" + uuid.New().String() + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/alternative; boundary="` + parts.Boundary() + `"`,
+	}
+	if msg.ReplyTo != "" {
+		header = append(header, "Reply-To: "+msg.ReplyTo)
+	}
+	extra := make([]string, 0, len(msg.Headers))
+	for name, value := range msg.Headers {
+		extra = append(extra, textproto.CanonicalMIMEHeaderKey(name)+": "+value)
+	}
+	sort.Strings(extra)
+	header = append(header, extra...)
+	head := strings.Join(header, "\r\n") + "\r\n\r\n"
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return append([]byte(head), buf.Bytes()...), nil
+}
+
+// ============ QUEUE ============
+
+// ErrMailQueueFull is returned when more mail is waiting than the queue holds
+var ErrMailQueueFull = errors.New("mail queue is full")
+
+// MailQueue delivers messages in the background so handlers never wait on
+// the mail server. Failed sends are retried with exponential backoff.
+// Messages still queued when the server stops are lost.
+type MailQueue struct {
+	mailer      Mailer
+	jobs        chan Message
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewMailQueue creates a queue holding up to size messages
+func NewMailQueue(mailer Mailer, size, maxAttempts int, backoff time.Duration) *MailQueue {
+	return &MailQueue{
+		mailer:      mailer,
+		jobs:        make(chan Message, size),
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Enqueue schedules msg for delivery without blocking
+func (q *MailQueue) Enqueue(msg Message) error {
+	select {
+	case q.jobs <- msg:
+		return nil
+	default:
+		return ErrMailQueueFull
+	}
+}
+
+// Run delivers queued messages with the given number of workers until ctx
+// is cancelled
+func (q *MailQueue) Run(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-q.jobs:
+					q.deliver(ctx, msg)
+				}
+			}
+		}()
+	}
+}
+
+// deliver sends msg, waiting backoff, 2*backoff, ... between attempts
+func (q *MailQueue) deliver(ctx context.Context, msg Message) {
+	delay := q.backoff
+	for attempt := 1; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		err := q.mailer.Send(sendCtx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= q.maxAttempts {
+			log.Printf("Giving up on email %q to %s after %d attempts: %v", msg.Subject, strings.Join(msg.To, ", "), attempt, err)
+			return
+		}
+		log.Printf("Email %q to %s failed (attempt %d), retrying in %s: %v", msg.Subject, strings.Join(msg.To, ", "), attempt, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
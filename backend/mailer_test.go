@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyMailer fails the first failures sends, then records messages and
+// closes done
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []Message
+	done     chan struct{}
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts++
+	if m.attempts <= m.failures {
+		return errors.New("connection refused")
+	}
+	m.sent = append(m.sent, msg)
+	if m.done != nil {
+		close(m.done)
+	}
+	return nil
+}
+
+func waitFor(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the mail queue")
+	}
+}
+
+func TestMailQueueRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mailer := &flakyMailer{failures: 2, done: make(chan struct{})}
+	queue := NewMailQueue(mailer, 10, 5, time.Millisecond)
+	queue.Run(ctx, 1)
+	if err := queue.Enqueue(Message{To: []string{"a@example.com"}, Subject: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, mailer.done)
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+	if mailer.attempts != 3 || len(mailer.sent) != 1 || mailer.sent[0].Subject != "Hello" {
+		t.Errorf("%d attempts, sent %+v; want delivery on the third attempt", mailer.attempts, mailer.sent)
+	}
+}
+
+func TestMailQueueGivesUp(t *testing.T) {
+	mailer := &flakyMailer{failures: 100}
+	queue := NewMailQueue(mailer, 10, 3, time.Millisecond)
+	queue.deliver(context.Background(), Message{To: []string{"a@example.com"}})
+	if mailer.attempts != 3 || len(mailer.sent) != 0 {
+		t.Errorf("%d attempts, %d sent; want 3 attempts and nothing sent", mailer.attempts, len(mailer.sent))
+	}
+}
+
+func TestMailQueueFull(t *testing.T) {
+	queue := NewMailQueue(&CaptureMailer{}, 2, 1, 0)
+	for i := 0; i < 2; i++ {
+		if err := queue.Enqueue(Message{}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	if err := queue.Enqueue(Message{}); !errors.Is(err, ErrMailQueueFull) {
+		t.Errorf("enqueue on a full queue = %v, want ErrMailQueueFull", err)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := Message{
+		To:      []string{"a@example.com", "b@example.com"},
+		ReplyTo: "sales@example.com",
+		Subject: "Déjà vu",
+		HTML:    "<p>" + strings.Repeat("long line ", 20) + "</p>",
+		Text:    "Plain = text",
+	}
+	raw, err := buildMIME("Haven <noreply@haven.example>", msg, time.Unix(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(raw)
+	for _, want := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Reply-To: sales@example.com\r\n",
+		"Subject: =?utf-8?q?D=C3=A9j=C3=A0_vu?=\r\n",
+		"@haven.example>\r\n",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"Plain =3D text",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("message is missing %q", want)
+		}
+	}
+	if strings.Index(body, "text/plain") > strings.Index(body, "text/html") {
+		t.Error("the HTML part comes before the plain text part")
+	}
+}
+
+func TestCaptureMailerWritesEML(t *testing.T) {
+	dir := t.TempDir()
+	mailer := &CaptureMailer{From: "noreply@example.com", Dir: dir}
+	if err := mailer.Send(context.Background(), Message{To: []string{"a@example.com"}, Subject: "Hi", Text: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 || len(mailer.Messages()) != 1 {
+		t.Fatalf("captured %d files and %d messages, want 1 each", len(files), len(mailer.Messages()))
+	}
+	raw, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(raw), "Subject: Hi\r\n") {
+		t.Errorf("captured file:\n%s", raw)
+	}
+}
+
+func TestCaptureMailerKeepsLatest(t *testing.T) {
+	mailer := &CaptureMailer{}
+	for i := 0; i < captureLimit+5; i++ {
+		if err := mailer.Send(context.Background(), Message{To: []string{"a@example.com"}, Subject: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages := mailer.Messages()
+	if len(messages) != captureLimit {
+		t.Fatalf("kept %d messages, want %d", len(messages), captureLimit)
+	}
+	if messages[0].Subject != "5" || messages[captureLimit-1].Subject != fmt.Sprint(captureLimit+4) {
+		t.Errorf("kept messages %s to %s, want the latest", messages[0].Subject, messages[captureLimit-1].Subject)
+	}
+}
+
+func TestNewMailerFromEnv(t *testing.T) {
+	tests := []struct {
+		env, driver, host string
+		want              string
+	}{
+		{"development", "", "", "*main.CaptureMailer"},
+		{"production", "", "", "error"},
+		{"", "", "", "error"},
+		{"", "", "smtp.example.com", "*main.SMTPMailer"},
+		{"", "smtp", "smtp.example.com", "*main.SMTPMailer"},
+		{"", "memory", "smtp.example.com", "*main.CaptureMailer"},
+		{"", "smtp", "", "error"},
+		{"", "pigeon", "", "error"},
+	}
+	for _, tt := range tests {
+		t.Setenv("ENV", tt.env)
+		t.Setenv("MAIL_DRIVER", tt.driver)
+		t.Setenv("SMTP_HOST", tt.host)
+		mailer, err := NewMailerFromEnv()
+		got := "error"
+		if err == nil {
+			got = fmt.Sprintf("%T", mailer)
+		}
+		if got != tt.want {
+			t.Errorf("ENV=%q MAIL_DRIVER=%q SMTP_HOST=%q: got %s, want %s", tt.env, tt.driver, tt.host, got, tt.want)
+		}
+	}
+}
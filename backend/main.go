@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -17,15 +21,42 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	// Initialize Supabase client
-	if err := InitSupabase(); err != nil {
-		log.Fatalf("Failed to initialize Supabase: %v", err)
+	// Database migrations: `server migrate up | down [steps] | status`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
 	}
 
+	// Initialize storage
+	store, err := InitStore()
+	if err != nil {
+		log.Fatalf("Failed to initialize store: %v", err)
+	}
+	if err := EnsureAdminUser(context.Background(), store); err != nil {
+		log.Fatalf("Failed to create admin user: %v", err)
+	}
+	mail, err := InitMail()
+	if err != nil {
+		log.Fatalf("Failed to initialize mail: %v", err)
+	}
+	h, err := NewHandler(store, mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize handlers: %v", err)
+	}
+
+	// Background jobs stop when the server receives SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	startWorkers(ctx, h)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "Haven Communities API",
 		Prefork: false,
+		// Params and body values outlive the request in the memory store
+		Immutable: true,
 	})
 
 	// Middleware
@@ -43,15 +74,15 @@ func main() {
 	api := app.Group("/api/v1")
 
 	// Public routes
-	setupPublicRoutes(api)
+	setupPublicRoutes(api, h)
 
 	// Protected routes
-	api.Use(AuthMiddleware)
-	setupProtectedRoutes(api)
+	api.Use(h.AuthMiddleware)
+	setupProtectedRoutes(api, h)
 
 	// Admin routes
-	admin := api.Group("/admin", AuthMiddleware, AdminMiddleware)
-	setupAdminRoutes(admin)
+	admin := api.Group("/admin", AdminMiddleware)
+	setupAdminRoutes(admin, h)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -59,6 +90,11 @@ func main() {
 		port = "8101"
 	}
 
+	go func() {
+		<-ctx.Done()
+		app.Shutdown()
+	}()
+
 	log.Printf("🚀 Server running on http://localhost:%s", port)
 	if err := app.Listen(fmt.Sprintf(":%s", port)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -68,86 +104,182 @@ func main() {
 // HealthCheck returns server status
 func HealthCheck(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
-		"status": "ok",
-		"service": "Haven Communities API",
-		"timestamp": fiber.Now(),
+		"status":    "ok",
+		"service":   "Haven Communities API",
+		"timestamp": time.Now(),
 	})
 }
 
 // setupPublicRoutes configures public endpoints (no auth required)
-func setupPublicRoutes(api fiber.Router) {
+func setupPublicRoutes(api fiber.Router, h *Handler) {
 	// Properties/Projects
-	api.Get("/properties", GetProperties)
-	api.Get("/properties/:id", GetPropertyByID)
-	api.Get("/properties/slug/:slug", GetPropertyBySlug)
+	api.Get("/properties", h.GetProperties)
+	api.Get("/properties/:id", uuidParams("id"), h.GetPropertyByID)
+	api.Get("/properties/slug/:slug", h.GetPropertyBySlug)
+	api.Get("/properties/:id/plots", uuidParams("id"), h.GetAvailablePlots)
+	api.Get("/properties/:id/payment-plans", uuidParams("id"), h.GetPaymentPlans)
+	api.Get("/properties/:id/payment-plans/:planId/schedule", uuidParams("id", "planId"), h.GetScheduleQuote)
+
+	// Signed document downloads
+	api.Get("/documents/:type/:id", uuidParams("id"), h.DownloadDocument)
 
 	// Blog posts
-	api.Get("/blog", GetBlogPosts)
-	api.Get("/blog/:id", GetBlogPostByID)
-	api.Get("/blog/slug/:slug", GetBlogPostBySlug)
-	api.Get("/blog/category/:category", GetBlogByCategory)
+	api.Get("/blog", h.GetBlogPosts)
+	api.Get("/blog/:id", uuidParams("id"), h.GetBlogPostByID)
+	api.Get("/blog/slug/:slug", h.GetBlogPostBySlug)
+	api.Get("/blog/category/:category", h.GetBlogByCategory)
 
 	// Authentication
-	api.Post("/auth/login", LoginAdmin)
-	api.Post("/auth/signup", SignupUser)
-	api.Post("/auth/refresh", RefreshToken)
+	api.Post("/auth/login", h.LoginAdmin)
+	api.Post("/auth/user/login", h.LoginUser)
+	api.Post("/auth/signup", h.SignupUser)
+	api.Post("/auth/refresh", h.RefreshToken)
 
 	// Contact form
-	api.Post("/contact", SubmitContactForm)
+	api.Post("/contact", h.SubmitContactForm)
 
 	// Newsletter
-	api.Post("/newsletter/subscribe", SubscribeNewsletter)
+	api.Post("/newsletter/subscribe", h.SubscribeNewsletter)
+	api.Get("/newsletter/confirm", h.ConfirmNewsletter)
+	api.Get("/newsletter/unsubscribe", h.UnsubscribeNewsletterLink)
+	api.Post("/newsletter/unsubscribe", h.UnsubscribeNewsletterLink)
+	api.Get("/newsletter/tracking-opt-out", h.OptOutNewsletterTracking)
+	api.Post("/newsletter/tracking-opt-out", h.OptOutNewsletterTracking)
+	api.Get("/newsletter/track/open", h.TrackCampaignOpen)
+	api.Get("/newsletter/track/click", h.TrackCampaignClick)
 
 	// Brochure download
-	api.Post("/brochure/download", DownloadBrochure)
+	api.Post("/brochure/download", h.DownloadBrochure)
+	api.Get("/brochure/:id/download", uuidParams("id"), h.DownloadRequestedBrochure)
 }
 
 // setupProtectedRoutes configures user endpoints (auth required)
-func setupProtectedRoutes(api fiber.Router) {
+func setupProtectedRoutes(api fiber.Router, h *Handler) {
+	// Session
+	api.Post("/auth/logout", h.Logout)
+
 	// User profile
-	api.Get("/me", GetUserProfile)
-	api.Put("/me", UpdateUserProfile)
+	api.Get("/me", h.GetUserProfile)
+	api.Put("/me", h.UpdateUserProfile)
+
+	// Plot reservations
+	api.Post("/properties/:id/reservations", uuidParams("id"), h.CreateReservation)
+	api.Get("/me/reservations", h.GetUserReservations)
+	api.Get("/me/payment-schedules", h.GetUserSchedules)
+	api.Get("/me/purchases", h.GetUserPurchases)
+	api.Get("/me/payments/:id/receipt", uuidParams("id"), h.GetUserReceipt)
+	api.Get("/me/payments/:id/receipt.pdf", uuidParams("id"), h.GetUserReceiptPDF)
+	api.Get("/me/payment-schedules/:id/schedule.pdf", uuidParams("id"), h.GetUserSchedulePDF)
+	api.Post("/me/document-links", h.CreateUserDocumentLink)
 
 	// Favorites/Wishlist
-	api.Get("/favorites", GetUserFavorites)
-	api.Post("/favorites/:propertyId", AddToFavorites)
-	api.Delete("/favorites/:propertyId", RemoveFromFavorites)
+	api.Get("/favorites", h.GetUserFavorites)
+	api.Post("/favorites/:propertyId", uuidParams("propertyId"), h.AddToFavorites)
+	api.Delete("/favorites/:propertyId", uuidParams("propertyId"), h.RemoveFromFavorites)
 
 	// User reviews
-	api.Post("/reviews", CreateReview)
-	api.Get("/reviews/user", GetUserReviews)
+	api.Post("/reviews", h.CreateReview)
+	api.Get("/reviews/user", h.GetUserReviews)
 }
 
 // setupAdminRoutes configures admin endpoints
-func setupAdminRoutes(api fiber.Router) {
+func setupAdminRoutes(api fiber.Router, h *Handler) {
 	// Properties management
-	api.Post("/properties", CreateProperty)
-	api.Put("/properties/:id", UpdateProperty)
-	api.Delete("/properties/:id", DeleteProperty)
+	api.Post("/properties", h.CreateProperty)
+	api.Put("/properties/:id", uuidParams("id"), h.UpdateProperty)
+	api.Delete("/properties/:id", uuidParams("id"), h.DeleteProperty)
+	api.Get("/properties/:id/brochure.pdf", uuidParams("id"), h.GetPropertyBrochurePDF)
+
+	// Plot inventory
+	api.Get("/properties/:id/plots", uuidParams("id"), h.GetPropertyPlots)
+	api.Post("/properties/:id/plots", uuidParams("id"), h.CreatePlots)
+	api.Put("/properties/:id/plots/:plotId", uuidParams("id", "plotId"), h.UpdatePlot)
+	api.Delete("/properties/:id/plots/:plotId", uuidParams("id", "plotId"), h.DeletePlot)
+
+	// Reservations
+	api.Get("/reservations", h.GetReservations)
+	api.Get("/reservations/:id", uuidParams("id"), h.GetReservationByID)
+	api.Post("/reservations/:id/convert", uuidParams("id"), h.ConvertReservation)
+	api.Post("/reservations/:id/cancel", uuidParams("id"), h.CancelReservation)
+
+	// Payment plans and buyer schedules
+	api.Get("/properties/:id/payment-plans", uuidParams("id"), h.GetAllPaymentPlans)
+	api.Post("/properties/:id/payment-plans", uuidParams("id"), h.CreatePaymentPlan)
+	api.Put("/properties/:id/payment-plans/:planId", uuidParams("id", "planId"), h.UpdatePaymentPlan)
+	api.Delete("/properties/:id/payment-plans/:planId", uuidParams("id", "planId"), h.DeletePaymentPlan)
+	api.Get("/payment-schedules", h.GetSchedules)
+	api.Get("/payment-schedules/:id", uuidParams("id"), h.GetScheduleByID)
+	api.Get("/payment-schedules/:id/schedule.pdf", uuidParams("id"), h.GetSchedulePDF)
+
+	// Payment ledger
+	api.Get("/payments", h.GetPayments)
+	api.Post("/payments", h.RecordPayment)
+	api.Get("/payments/:id", uuidParams("id"), h.GetPaymentByID)
+	api.Get("/payments/:id/receipt", uuidParams("id"), h.GetPaymentReceipt)
+	api.Get("/payments/:id/receipt.pdf", uuidParams("id"), h.GetPaymentReceiptPDF)
+	api.Post("/payments/:id/reverse", uuidParams("id"), h.ReversePayment)
+
+	// Signed document links
+	api.Post("/document-links", h.CreateDocumentLink)
 
 	// Blog management
-	api.Post("/blog", CreateBlogPost)
-	api.Put("/blog/:id", UpdateBlogPost)
-	api.Delete("/blog/:id", DeleteBlogPost)
+	api.Get("/blog", h.GetAdminBlogPosts)
+	api.Post("/blog", h.CreateBlogPost)
+	api.Get("/blog/:id", uuidParams("id"), h.GetAdminBlogPost)
+	api.Put("/blog/:id", uuidParams("id"), h.UpdateBlogPost)
+	api.Delete("/blog/:id", uuidParams("id"), h.DeleteBlogPost)
+	api.Post("/blog/:id/publish", uuidParams("id"), h.PublishBlogPost)
+	api.Post("/blog/:id/unpublish", uuidParams("id"), h.UnpublishBlogPost)
+	api.Get("/blog/:id/revisions", uuidParams("id"), h.GetBlogRevisions)
+	api.Get("/blog/:id/revisions/diff", uuidParams("id"), h.DiffBlogRevisions)
+	api.Get("/blog/:id/revisions/:revision", uuidParams("id"), h.GetBlogRevision)
+	api.Post("/blog/:id/revisions/:revision/restore", uuidParams("id"), h.RestoreBlogRevision)
 
 	// Contact form submissions
-	api.Get("/contacts", GetContactSubmissions)
-	api.Get("/contacts/:id", GetContactByID)
+	api.Get("/contacts", h.GetContactSubmissions)
+	api.Get("/contacts/:id", uuidParams("id"), h.GetContactByID)
 
 	// Newsletter subscribers
-	api.Get("/newsletter/subscribers", GetNewsletterSubscribers)
-	api.Delete("/newsletter/subscribers/:email", UnsubscribeNewsletter)
+	api.Get("/newsletter/subscribers", h.GetNewsletterSubscribers)
+	api.Put("/newsletter/subscribers/:email", h.UpdateSubscriber)
+	api.Delete("/newsletter/subscribers/:email", h.UnsubscribeNewsletter)
+
+	// Newsletter segments
+	api.Get("/newsletter/segments", h.GetSegments)
+	api.Post("/newsletter/segments", h.CreateSegment)
+	api.Get("/newsletter/segments/:id", uuidParams("id"), h.GetSegment)
+	api.Put("/newsletter/segments/:id", uuidParams("id"), h.UpdateSegment)
+	api.Delete("/newsletter/segments/:id", uuidParams("id"), h.DeleteSegment)
+	api.Get("/newsletter/segments/:id/subscribers", uuidParams("id"), h.GetSegmentSubscribers)
+	api.Get("/newsletter/segments/:id/export", uuidParams("id"), h.ExportSegment)
+
+	// Newsletter campaigns
+	api.Get("/newsletter/campaigns", h.GetCampaigns)
+	api.Post("/newsletter/campaigns", h.CreateCampaign)
+	api.Get("/newsletter/campaigns/:id", uuidParams("id"), h.GetCampaign)
+	api.Put("/newsletter/campaigns/:id", uuidParams("id"), h.UpdateCampaign)
+	api.Delete("/newsletter/campaigns/:id", uuidParams("id"), h.DeleteCampaign)
+	api.Post("/newsletter/campaigns/:id/schedule", uuidParams("id"), h.ScheduleCampaign)
+	api.Post("/newsletter/campaigns/:id/cancel", uuidParams("id"), h.CancelCampaign)
+	api.Post("/newsletter/campaigns/:id/test", uuidParams("id"), h.SendTestCampaign)
+	api.Get("/newsletter/campaigns/:id/recipients", uuidParams("id"), h.GetCampaignRecipients)
+	api.Get("/newsletter/campaigns/:id/stats", uuidParams("id"), h.GetCampaignStats)
+
+	// Brochure downloads and leads
+	api.Get("/brochures/stats", h.GetBrochureStats)
+	api.Get("/leads", h.GetLeads)
 
 	// Dashboard stats
-	api.Get("/dashboard/stats", GetDashboardStats)
-	api.Get("/dashboard/recent-contacts", GetRecentContacts)
+	api.Get("/dashboard/stats", h.GetDashboardStats)
+	api.Get("/dashboard/recent-contacts", h.GetRecentContacts)
 
 	// Users management
-	api.Get("/users", GetAllUsers)
-	api.Get("/users/:id", GetUserByID)
-	api.Put("/users/:id", UpdateUser)
-	api.Delete("/users/:id", DeleteUser)
+	api.Get("/users", h.GetAllUsers)
+	api.Get("/users/:id", uuidParams("id"), h.GetUserByID)
+	api.Put("/users/:id", uuidParams("id"), h.UpdateUser)
+	api.Delete("/users/:id", uuidParams("id"), h.DeleteUser)
+	api.Post("/users/:id/revoke-sessions", uuidParams("id"), h.RevokeUserSessions)
 
 	// Image upload
-	api.Post("/upload", UploadImage)
+	api.Post("/upload", h.UploadImage)
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// readingWordsPerMinute is the reading speed behind reading time estimates
+const readingWordsPerMinute = 200
+
+// blogMarkdown renders GitHub flavoured Markdown with heading anchors. Raw
+// HTML is passed through and left to blogHTMLPolicy to clean up.
+var blogMarkdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// blogHTMLPolicy is the allowlist of elements and attributes blog HTML may
+// contain. Everything else, including scripts, styles, event handlers and
+// non-web links, is removed.
+var blogHTMLPolicy = newBlogHTMLPolicy()
+
+func newBlogHTMLPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("h1", "h2", "h3", "h4", "h5", "h6", "p", "br", "hr", "strong", "b", "em", "i", "del", "s",
+		"sub", "sup", "blockquote", "ul", "ol", "li", "pre", "code", "table", "thead", "tbody", "tr", "th", "td")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowRelativeURLs(true)
+	p.RequireNoFollowOnFullyQualifiedLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// renderedMarkdown is a Markdown document rendered for the blog
+type renderedMarkdown struct {
+	HTML            string
+	TableOfContents []BlogHeading
+	ReadingMinutes  int
+}
+
+// renderMarkdown turns Markdown into sanitized HTML, listing its headings
+// and estimating how long it takes to read
+func renderMarkdown(source string) (renderedMarkdown, error) {
+	src := []byte(source)
+	doc := blogMarkdown.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := blogMarkdown.Renderer().Render(&buf, src, doc); err != nil {
+		return renderedMarkdown{}, err
+	}
+	sanitized := blogHTMLPolicy.Sanitize(buf.String())
+
+	return renderedMarkdown{
+		HTML:            sanitized,
+		TableOfContents: markdownHeadings(doc, src),
+		ReadingMinutes:  readingMinutes(htmlToText(sanitized)),
+	}, nil
+}
+
+// markdownHeadings lists the headings of a parsed document in order
+func markdownHeadings(doc ast.Node, source []byte) []BlogHeading {
+	headings := []BlogHeading{}
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		entry := BlogHeading{Level: heading.Level, Text: plainText(heading, source)}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				entry.ID = string(b)
+			}
+		}
+		headings = append(headings, entry)
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// plainText is the text of a node without its Markdown formatting
+func plainText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.RawHTML:
+			// Inline tags carry no heading text
+		default:
+			b.WriteString(plainText(child, source))
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// readingMinutes estimates the minutes needed to read text, at least one
+// for any text at all
+func readingMinutes(text string) int {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / readingWordsPerMinute))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "script tag",
+			markdown: "Hello <script>alert(1)</script> world",
+			want:     []string{"Hello", "world"},
+			unwanted: []string{"<script", "alert(1)"},
+		},
+		{
+			name:     "script block",
+			markdown: "<script>\nfetch('/steal')\n</script>\n\nText",
+			want:     []string{"<p>Text</p>"},
+			unwanted: []string{"<script", "fetch("},
+		},
+		{
+			name:     "javascript link",
+			markdown: "[click](javascript:alert(1))",
+			want:     []string{"click"},
+			unwanted: []string{"javascript:", "href"},
+		},
+		{
+			name:     "javascript link in raw HTML",
+			markdown: `<a href="JavaScript:alert(1)">click</a>`,
+			want:     []string{"click"},
+			unwanted: []string{"avaScript:", "href"},
+		},
+		{
+			name:     "data image",
+			markdown: "![x](data:text/html;base64,PHNjcmlwdD4=)",
+			unwanted: []string{"data:"},
+		},
+		{
+			name:     "event handler",
+			markdown: `<img src="/a.png" onerror="alert(1)">`,
+			want:     []string{`src="/a.png"`},
+			unwanted: []string{"onerror"},
+		},
+		{
+			name:     "iframe and style",
+			markdown: "<iframe src=\"https://evil.example\"></iframe><style>body{}</style>ok",
+			want:     []string{"ok"},
+			unwanted: []string{"<iframe", "<style", "body{}"},
+		},
+		{
+			name:     "external link",
+			markdown: "[site](https://example.com)",
+			want:     []string{`href="https://example.com"`, "nofollow", "noopener", `target="_blank"`},
+		},
+		{
+			name:     "relative and mailto links",
+			markdown: "[plots](/properties/lekki) [mail](mailto:sales@example.com)",
+			want:     []string{`href="/properties/lekki"`, `href="mailto:sales@example.com"`},
+		},
+		{
+			name:     "GitHub flavoured extensions",
+			markdown: "| a | b |\n|:-|-:|\n| 1 | 2 |\n\n~~gone~~\n\n- [x] done",
+			want:     []string{"<table>", `align="left"`, "<del>gone</del>", `type="checkbox"`, "checked"},
+		},
+		{
+			name:     "code language",
+			markdown: "```go\nfmt.Println(1)\n```",
+			want:     []string{`class="language-go"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderMarkdown(tt.markdown)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(rendered.HTML, s) {
+					t.Errorf("HTML lacks %q:\n%s", s, rendered.HTML)
+				}
+			}
+			for _, s := range tt.unwanted {
+				if strings.Contains(rendered.HTML, s) {
+					t.Errorf("HTML contains %q:\n%s", s, rendered.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownTableOfContents(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []BlogHeading
+	}{
+		{"no headings", "Just text.", []BlogHeading{}},
+		{
+			name:     "levels and ids",
+			markdown: "# Buying Land\n\ntext\n\n## Title & Fees\n\n### Step 1: *Survey*",
+			want: []BlogHeading{
+				{Level: 1, Text: "Buying Land", ID: "buying-land"},
+				{Level: 2, Text: "Title & Fees", ID: "title--fees"},
+				{Level: 3, Text: "Step 1: Survey", ID: "step-1-survey"},
+			},
+		},
+		{
+			name:     "duplicate headings get unique ids",
+			markdown: "## Costs\n\n## Costs",
+			want: []BlogHeading{
+				{Level: 2, Text: "Costs", ID: "costs"},
+				{Level: 2, Text: "Costs", ID: "costs-1"},
+			},
+		},
+		{
+			name:     "formatting is dropped",
+			markdown: "## The `C of O` *explained*",
+			want:     []BlogHeading{{Level: 2, Text: "The C of O explained", ID: "the-c-of-o-explained"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderMarkdown(tt.markdown)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rendered.TableOfContents, tt.want) {
+				t.Errorf("TableOfContents = %+v, want %+v", rendered.TableOfContents, tt.want)
+			}
+			for _, h := range tt.want {
+				if !strings.Contains(rendered.HTML, `id="`+h.ID+`"`) {
+					t.Errorf("HTML lacks heading id %q:\n%s", h.ID, rendered.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestReadingMinutes(t *testing.T) {
+	words := func(n int) string { return strings.Repeat("word ", n) }
+	tests := []struct {
+		name     string
+		markdown string
+		want     int
+	}{
+		{"empty", "", 0},
+		{"only markup", "<script>a b c</script>", 0},
+		{"one word", "Hi", 1},
+		{"exactly one minute", words(readingWordsPerMinute), 1},
+		{"just over a minute", words(readingWordsPerMinute + 1), 2},
+		{"markup is not counted", "**" + strings.TrimSpace(words(readingWordsPerMinute)) + "**", 1},
+		{"long post", words(readingWordsPerMinute*7 + 3), 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderMarkdown(tt.markdown)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rendered.ReadingMinutes != tt.want {
+				t.Errorf("ReadingMinutes = %d, want %d", rendered.ReadingMinutes, tt.want)
+			}
+		})
+	}
+}
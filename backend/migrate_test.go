@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d; versions must run 1, 2, 3, ...", i, m.Version)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %04d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	first := Migration{Version: 1, Name: "create_users", Up: "CREATE TABLE users ();"}
+	second := Migration{Version: 2, Name: "create_posts", Up: "CREATE TABLE posts ();"}
+	migrations := []Migration{first, second}
+	edited := Migration{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id UUID);"}
+
+	tests := []struct {
+		name    string
+		applied map[int]string
+		want    []int
+		wantErr string
+	}{
+		{"fresh database", map[int]string{}, []int{1, 2}, ""},
+		{"one applied", map[int]string{1: first.Checksum()}, []int{2}, ""},
+		{"all applied", map[int]string{1: first.Checksum(), 2: second.Checksum()}, nil, ""},
+		{"applied before checksums", map[int]string{1: ""}, []int{2}, ""},
+		{
+			"modified after it was applied",
+			map[int]string{1: edited.Checksum()},
+			nil, "migration 0001_create_users was modified after it was applied",
+		},
+		{
+			"whitespace counts as a change",
+			map[int]string{1: first.Checksum(), 2: Migration{Up: second.Up + "\n"}.Checksum()},
+			nil, "migration 0002_create_posts was modified after it was applied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := pendingMigrations(migrations, tt.applied)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var versions []int
+			for _, m := range pending {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Errorf("pending = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS properties (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(255) UNIQUE NOT NULL,
+  description TEXT,
+  location VARCHAR(255),
+  price DECIMAL(12, 2),
+  status VARCHAR(50) DEFAULT 'available', -- available, sold, pending
+  units INTEGER DEFAULT 1,
+  acres DECIMAL(10, 2),
+  features JSONB DEFAULT '[]'::jsonb,
+  image_url VARCHAR(500),
+  image_alt VARCHAR(255),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_properties_status ON properties (status);
+CREATE INDEX IF NOT EXISTS idx_properties_created_at ON properties (created_at);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS blog_posts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title VARCHAR(500) NOT NULL,
+  slug VARCHAR(500) UNIQUE NOT NULL,
+  excerpt TEXT,
+  content TEXT,
+  category VARCHAR(100), -- Land, Homes, Construction, Investment
+  tags JSONB DEFAULT '[]'::jsonb,
+  image_url VARCHAR(500),
+  image_alt VARCHAR(255),
+  author VARCHAR(255),
+  published BOOLEAN DEFAULT false,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_posts_category ON blog_posts (category);
+CREATE INDEX IF NOT EXISTS idx_blog_posts_published ON blog_posts (published);
+CREATE INDEX IF NOT EXISTS idx_blog_posts_created_at ON blog_posts (created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS favorites (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (user_id, property_id)
+);
+
+-- (user_id, property_id) is covered by the unique constraint
+CREATE INDEX IF NOT EXISTS idx_favorites_property_id ON favorites (property_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS brochure_requests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  email VARCHAR(255) NOT NULL,
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_brochure_requests_email ON brochure_requests (email);
+CREATE INDEX IF NOT EXISTS idx_brochure_requests_property_id ON brochure_requests (property_id);
+CREATE INDEX IF NOT EXISTS idx_brochure_requests_created_at ON brochure_requests (created_at);
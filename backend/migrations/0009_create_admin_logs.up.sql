@@ -0,0 +1,15 @@
+-- Audit trail for admin actions
+CREATE TABLE IF NOT EXISTS admin_logs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id),
+  action VARCHAR(255) NOT NULL,
+  entity_type VARCHAR(100),
+  entity_id VARCHAR(255),
+  changes JSONB,
+  ip_address VARCHAR(45),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_logs_user_id ON admin_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_admin_logs_action ON admin_logs (action);
+CREATE INDEX IF NOT EXISTS idx_admin_logs_created_at ON admin_logs (created_at);
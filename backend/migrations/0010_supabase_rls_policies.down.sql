@@ -0,0 +1,30 @@
+DROP POLICY IF EXISTS "Users can read all users" ON users;
+DROP POLICY IF EXISTS "Users can update own profile" ON users;
+DROP POLICY IF EXISTS "Everyone can read properties" ON properties;
+DROP POLICY IF EXISTS "Only admins can insert properties" ON properties;
+DROP POLICY IF EXISTS "Only admins can update properties" ON properties;
+DROP POLICY IF EXISTS "Only admins can delete properties" ON properties;
+DROP POLICY IF EXISTS "Everyone can read published blog posts" ON blog_posts;
+DROP POLICY IF EXISTS "Only admins can see all blog posts" ON blog_posts;
+DROP POLICY IF EXISTS "Only admins can insert blog posts" ON blog_posts;
+DROP POLICY IF EXISTS "Everyone can submit contact form" ON contact_submissions;
+DROP POLICY IF EXISTS "Only admins can read contact submissions" ON contact_submissions;
+DROP POLICY IF EXISTS "Everyone can subscribe to newsletter" ON newsletter_subscribers;
+DROP POLICY IF EXISTS "Only admins can read subscribers" ON newsletter_subscribers;
+DROP POLICY IF EXISTS "Everyone can read reviews" ON reviews;
+DROP POLICY IF EXISTS "Authenticated users can create reviews" ON reviews;
+DROP POLICY IF EXISTS "Users can read own favorites" ON favorites;
+DROP POLICY IF EXISTS "Users can create own favorites" ON favorites;
+DROP POLICY IF EXISTS "Users can delete own favorites" ON favorites;
+DROP POLICY IF EXISTS "Everyone can request brochure" ON brochure_requests;
+DROP POLICY IF EXISTS "Only admins can read admin logs" ON admin_logs;
+
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+ALTER TABLE properties DISABLE ROW LEVEL SECURITY;
+ALTER TABLE blog_posts DISABLE ROW LEVEL SECURITY;
+ALTER TABLE contact_submissions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE newsletter_subscribers DISABLE ROW LEVEL SECURITY;
+ALTER TABLE reviews DISABLE ROW LEVEL SECURITY;
+ALTER TABLE favorites DISABLE ROW LEVEL SECURITY;
+ALTER TABLE brochure_requests DISABLE ROW LEVEL SECURITY;
+ALTER TABLE admin_logs DISABLE ROW LEVEL SECURITY;
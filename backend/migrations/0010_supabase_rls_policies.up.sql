@@ -0,0 +1,66 @@
+-- Row-level security policies for Supabase deployments.
+-- auth.uid() only exists on Supabase, so this is a no-op on plain Postgres.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    RAISE NOTICE 'auth schema not found, skipping RLS policies';
+    RETURN;
+  END IF;
+
+  ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE properties ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE blog_posts ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE contact_submissions ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE newsletter_subscribers ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE reviews ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE favorites ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE brochure_requests ENABLE ROW LEVEL SECURITY;
+  ALTER TABLE admin_logs ENABLE ROW LEVEL SECURITY;
+
+  -- Users: users can read all, authenticated users can update own profile
+  EXECUTE $p$CREATE POLICY "Users can read all users" ON users FOR SELECT USING (true)$p$;
+  EXECUTE $p$CREATE POLICY "Users can update own profile" ON users FOR UPDATE USING (auth.uid() = id)$p$;
+
+  -- Properties: everyone can read, only admins can write
+  EXECUTE $p$CREATE POLICY "Everyone can read properties" ON properties FOR SELECT USING (true)$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can insert properties" ON properties FOR INSERT
+    WITH CHECK (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can update properties" ON properties FOR UPDATE
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can delete properties" ON properties FOR DELETE
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+
+  -- Blog posts: everyone can read published posts, admins see and write all
+  EXECUTE $p$CREATE POLICY "Everyone can read published blog posts" ON blog_posts FOR SELECT USING (published = true)$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can see all blog posts" ON blog_posts FOR SELECT
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can insert blog posts" ON blog_posts FOR INSERT
+    WITH CHECK (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+
+  -- Contact submissions: everyone can insert, only admins can read
+  EXECUTE $p$CREATE POLICY "Everyone can submit contact form" ON contact_submissions FOR INSERT WITH CHECK (true)$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can read contact submissions" ON contact_submissions FOR SELECT
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+
+  -- Newsletter: everyone can insert, only admins can read
+  EXECUTE $p$CREATE POLICY "Everyone can subscribe to newsletter" ON newsletter_subscribers FOR INSERT WITH CHECK (true)$p$;
+  EXECUTE $p$CREATE POLICY "Only admins can read subscribers" ON newsletter_subscribers FOR SELECT
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+
+  -- Reviews: everyone can read, authenticated users can create own
+  EXECUTE $p$CREATE POLICY "Everyone can read reviews" ON reviews FOR SELECT USING (true)$p$;
+  EXECUTE $p$CREATE POLICY "Authenticated users can create reviews" ON reviews FOR INSERT WITH CHECK (auth.uid() = user_id)$p$;
+
+  -- Favorites: users can read/write own favorites
+  EXECUTE $p$CREATE POLICY "Users can read own favorites" ON favorites FOR SELECT USING (auth.uid() = user_id)$p$;
+  EXECUTE $p$CREATE POLICY "Users can create own favorites" ON favorites FOR INSERT WITH CHECK (auth.uid() = user_id)$p$;
+  EXECUTE $p$CREATE POLICY "Users can delete own favorites" ON favorites FOR DELETE USING (auth.uid() = user_id)$p$;
+
+  -- Brochure requests: everyone can request
+  EXECUTE $p$CREATE POLICY "Everyone can request brochure" ON brochure_requests FOR INSERT WITH CHECK (true)$p$;
+
+  -- Admin logs: only admins can read
+  EXECUTE $p$CREATE POLICY "Only admins can read admin logs" ON admin_logs FOR SELECT
+    USING (EXISTS (SELECT 1 FROM users WHERE id = auth.uid() AND role = 'admin'))$p$;
+END
+$$;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_properties_features;
+DROP INDEX IF EXISTS idx_properties_acres;
+DROP INDEX IF EXISTS idx_properties_price;
+DROP INDEX IF EXISTS idx_properties_search_vector;
+ALTER TABLE properties DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,11 @@
+-- Full-text search over property titles and descriptions
+ALTER TABLE properties ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_properties_search_vector ON properties USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_properties_price ON properties (price);
+CREATE INDEX IF NOT EXISTS idx_properties_acres ON properties (acres);
+CREATE INDEX IF NOT EXISTS idx_properties_features ON properties USING GIN (features);
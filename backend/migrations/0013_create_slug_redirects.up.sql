@@ -0,0 +1,21 @@
+-- Slugs that properties and blog posts used to have, kept so old links
+-- can be redirected after a title change
+CREATE TABLE IF NOT EXISTS slug_redirects (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  entity_type VARCHAR(20) NOT NULL, -- property, blog_post
+  old_slug VARCHAR(255) NOT NULL,
+  entity_id UUID NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (entity_type, old_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_entity_id ON slug_redirects (entity_id);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    ALTER TABLE slug_redirects ENABLE ROW LEVEL SECURITY;
+    EXECUTE $p$CREATE POLICY "Everyone can read slug redirects" ON slug_redirects FOR SELECT USING (true)$p$;
+  END IF;
+END
+$$;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_properties_latitude_longitude;
+DROP INDEX IF EXISTS idx_properties_earth;
+
+ALTER TABLE properties
+  DROP CONSTRAINT IF EXISTS properties_coordinates_pair,
+  DROP CONSTRAINT IF EXISTS properties_longitude_range,
+  DROP CONSTRAINT IF EXISTS properties_latitude_range;
+
+ALTER TABLE properties
+  DROP COLUMN IF EXISTS boundary,
+  DROP COLUMN IF EXISTS longitude,
+  DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,18 @@
+-- Map coordinates and an optional GeoJSON boundary for each property.
+-- Radius searches use the earthdistance extension, which ships with
+-- Postgres contrib and is available on Supabase.
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE properties
+  ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS boundary JSONB;
+
+ALTER TABLE properties
+  ADD CONSTRAINT properties_latitude_range CHECK (latitude BETWEEN -90 AND 90),
+  ADD CONSTRAINT properties_longitude_range CHECK (longitude BETWEEN -180 AND 180),
+  ADD CONSTRAINT properties_coordinates_pair CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_properties_earth ON properties USING GIST (ll_to_earth(latitude, longitude));
+CREATE INDEX IF NOT EXISTS idx_properties_latitude_longitude ON properties (latitude, longitude);
@@ -0,0 +1,25 @@
+-- Individual plots of a land estate. The property's units and status are
+-- derived from these rows by the API.
+CREATE TABLE IF NOT EXISTS plots (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  plot_number VARCHAR(50) NOT NULL,
+  size_sqm DECIMAL(12, 2) NOT NULL CHECK (size_sqm > 0),
+  price DECIMAL(14, 2) NOT NULL CHECK (price >= 0),
+  is_corner BOOLEAN NOT NULL DEFAULT false,
+  status VARCHAR(20) NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'reserved', 'sold')),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (property_id, plot_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_plots_property_id_status ON plots (property_id, status);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    ALTER TABLE plots ENABLE ROW LEVEL SECURITY;
+    EXECUTE $p$CREATE POLICY "Everyone can read plots" ON plots FOR SELECT USING (true)$p$;
+  END IF;
+END
+$$;
@@ -0,0 +1,6 @@
+DROP FUNCTION IF EXISTS expire_reservations(TIMESTAMP WITH TIME ZONE);
+DROP FUNCTION IF EXISTS complete_reservation(UUID, VARCHAR);
+DROP FUNCTION IF EXISTS reserve_plots(UUID, UUID, UUID, UUID[], TIMESTAMP WITH TIME ZONE);
+UPDATE plots SET status = 'available' WHERE status = 'reserved' AND reservation_id IS NOT NULL;
+ALTER TABLE plots DROP COLUMN IF EXISTS reservation_id;
+DROP TABLE IF EXISTS reservations;
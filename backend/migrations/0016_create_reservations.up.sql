@@ -0,0 +1,102 @@
+-- Buyer holds on plots. Plots change status only through the functions
+-- below, which update the reservation and its plots in one statement so two
+-- buyers can never hold the same plot. Errors use SQLSTATE HV404 (not found)
+-- and HV409 (conflict), which the API maps to 404 and 409.
+CREATE TABLE IF NOT EXISTS reservations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  plot_ids UUID[] NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'converted', 'cancelled', 'expired')),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_user_id ON reservations (user_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_property_id ON reservations (property_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_active_expires_at ON reservations (expires_at) WHERE status = 'active';
+
+ALTER TABLE plots ADD COLUMN IF NOT EXISTS reservation_id UUID REFERENCES reservations(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_plots_reservation_id ON plots (reservation_id);
+
+-- reserve_plots records a reservation and holds its plots, failing with
+-- HV409 unless every plot belongs to the property and is available. The
+-- row locks taken by the UPDATE make concurrent calls for the same plot wait,
+-- then see it as reserved.
+CREATE OR REPLACE FUNCTION reserve_plots(p_id UUID, p_property_id UUID, p_user_id UUID, p_plot_ids UUID[], p_expires_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF reservations
+LANGUAGE plpgsql AS $$
+DECLARE
+  held INTEGER;
+BEGIN
+  RETURN QUERY
+    INSERT INTO reservations (id, property_id, user_id, plot_ids, status, expires_at)
+    VALUES (p_id, p_property_id, p_user_id, p_plot_ids, 'active', p_expires_at)
+    RETURNING *;
+
+  UPDATE plots SET status = 'reserved', reservation_id = p_id, updated_at = NOW()
+  WHERE id = ANY (p_plot_ids) AND property_id = p_property_id AND status = 'available';
+  GET DIAGNOSTICS held = ROW_COUNT;
+  IF held <> cardinality(p_plot_ids) THEN
+    RAISE EXCEPTION 'one or more plots are not available' USING ERRCODE = 'HV409';
+  END IF;
+END
+$$;
+
+-- complete_reservation ends an active reservation: converted sells its
+-- plots, cancelled and expired release them
+CREATE OR REPLACE FUNCTION complete_reservation(p_id UUID, p_status VARCHAR)
+RETURNS SETOF reservations
+LANGUAGE plpgsql AS $$
+DECLARE
+  completed reservations;
+BEGIN
+  IF p_status NOT IN ('converted', 'cancelled', 'expired') THEN
+    RAISE EXCEPTION 'invalid final reservation status %', p_status USING ERRCODE = '22023';
+  END IF;
+
+  UPDATE reservations SET status = p_status, updated_at = NOW()
+  WHERE id = p_id AND status = 'active'
+  RETURNING * INTO completed;
+  IF NOT FOUND THEN
+    IF EXISTS (SELECT 1 FROM reservations WHERE id = p_id) THEN
+      RAISE EXCEPTION 'reservation is no longer active' USING ERRCODE = 'HV409';
+    END IF;
+    RAISE EXCEPTION 'reservation not found' USING ERRCODE = 'HV404';
+  END IF;
+
+  UPDATE plots SET
+    status = CASE WHEN p_status = 'converted' THEN 'sold' ELSE 'available' END,
+    reservation_id = CASE WHEN p_status = 'converted' THEN p_id END,
+    updated_at = NOW()
+  WHERE reservation_id = p_id;
+
+  RETURN NEXT completed;
+END
+$$;
+
+-- expire_reservations releases the plots of every active reservation that
+-- expired at or before p_now and returns those reservations
+CREATE OR REPLACE FUNCTION expire_reservations(p_now TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF reservations
+LANGUAGE sql AS $$
+  WITH expired AS (
+    UPDATE reservations SET status = 'expired', updated_at = NOW()
+    WHERE status = 'active' AND expires_at <= p_now
+    RETURNING *
+  ), released AS (
+    UPDATE plots SET status = 'available', reservation_id = NULL, updated_at = NOW()
+    WHERE reservation_id IN (SELECT id FROM expired)
+  )
+  SELECT * FROM expired;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    ALTER TABLE reservations ENABLE ROW LEVEL SECURITY;
+    EXECUTE $p$CREATE POLICY "Users can read own reservations" ON reservations FOR SELECT USING (auth.uid() = user_id)$p$;
+  END IF;
+END
+$$;
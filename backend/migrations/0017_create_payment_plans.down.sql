@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_schedules;
+DROP TABLE IF EXISTS payment_plans;
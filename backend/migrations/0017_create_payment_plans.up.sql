@@ -0,0 +1,47 @@
+-- Payment plans offered per property, and the schedules of buyers whose
+-- reservations were converted into sales. Schedules copy the plan terms and
+-- keep their installments as JSON so later plan edits do not change them.
+CREATE TABLE IF NOT EXISTS payment_plans (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  deposit_percent DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (deposit_percent BETWEEN 0 AND 100),
+  months INTEGER NOT NULL DEFAULT 0 CHECK (months BETWEEN 0 AND 120),
+  markup_percent DECIMAL(6, 2) NOT NULL DEFAULT 0 CHECK (markup_percent >= 0),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_plans_property_id ON payment_plans (property_id);
+
+CREATE TABLE IF NOT EXISTS payment_schedules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  reservation_id UUID UNIQUE REFERENCES reservations(id) ON DELETE SET NULL,
+  payment_plan_id UUID REFERENCES payment_plans(id) ON DELETE SET NULL,
+  plan_name VARCHAR(100) NOT NULL,
+  base_price DECIMAL(14, 2) NOT NULL,
+  deposit_percent DECIMAL(5, 2) NOT NULL,
+  months INTEGER NOT NULL,
+  markup_percent DECIMAL(6, 2) NOT NULL,
+  total_amount DECIMAL(14, 2) NOT NULL,
+  installments JSONB NOT NULL,
+  start_date DATE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_schedules_user_id ON payment_schedules (user_id);
+CREATE INDEX IF NOT EXISTS idx_payment_schedules_property_id ON payment_schedules (property_id);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    ALTER TABLE payment_plans ENABLE ROW LEVEL SECURITY;
+    ALTER TABLE payment_schedules ENABLE ROW LEVEL SECURITY;
+    EXECUTE $p$CREATE POLICY "Everyone can read active payment plans" ON payment_plans FOR SELECT USING (is_active = true)$p$;
+    EXECUTE $p$CREATE POLICY "Users can read own payment schedules" ON payment_schedules FOR SELECT USING (auth.uid() = user_id)$p$;
+  END IF;
+END
+$$;
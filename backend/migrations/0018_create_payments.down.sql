@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payments;
+DROP SEQUENCE IF EXISTS payment_receipt_seq;
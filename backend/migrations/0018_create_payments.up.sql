@@ -0,0 +1,34 @@
+-- Payments received against buyer schedules. Rows are never deleted; a
+-- mistaken payment is reversed instead. Receipt numbers come from a
+-- sequence so they are unique and increasing (RCP-000001, RCP-000002, ...).
+CREATE SEQUENCE IF NOT EXISTS payment_receipt_seq;
+
+CREATE TABLE IF NOT EXISTS payments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  schedule_id UUID NOT NULL REFERENCES payment_schedules(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+  amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+  method VARCHAR(20) NOT NULL CHECK (method IN ('bank_transfer', 'card', 'cash', 'cheque')),
+  reference VARCHAR(100) NOT NULL DEFAULT '',
+  received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  receipt_number VARCHAR(20) NOT NULL UNIQUE DEFAULT 'RCP-' || lpad(nextval('payment_receipt_seq')::text, 6, '0'),
+  status VARCHAR(20) NOT NULL DEFAULT 'recorded' CHECK (status IN ('recorded', 'reversed')),
+  reversed_at TIMESTAMP WITH TIME ZONE,
+  reversal_reason TEXT NOT NULL DEFAULT '',
+  recorded_by UUID NOT NULL, -- admin who entered it; kept if the account is deleted
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_schedule_id ON payments (schedule_id);
+CREATE INDEX IF NOT EXISTS idx_payments_user_id ON payments (user_id);
+CREATE INDEX IF NOT EXISTS idx_payments_property_id ON payments (property_id);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    ALTER TABLE payments ENABLE ROW LEVEL SECURITY;
+    EXECUTE $p$CREATE POLICY "Users can read own payments" ON payments FOR SELECT USING (auth.uid() = user_id)$p$;
+  END IF;
+END
+$$;
@@ -0,0 +1,7 @@
+DROP FUNCTION IF EXISTS brochure_stats(INTEGER);
+DROP FUNCTION IF EXISTS record_brochure_download(UUID, TIMESTAMP WITH TIME ZONE);
+DROP FUNCTION IF EXISTS capture_lead(UUID, VARCHAR, VARCHAR, UUID, TIMESTAMP WITH TIME ZONE);
+DROP TABLE IF EXISTS leads;
+ALTER TABLE brochure_requests
+  DROP COLUMN IF EXISTS last_downloaded_at,
+  DROP COLUMN IF EXISTS download_count;
@@ -0,0 +1,62 @@
+-- Brochure links count their downloads, and every email that asks for a
+-- brochure becomes a lead. Leads are unique by email; capture_lead creates
+-- one or counts another request from a known email.
+ALTER TABLE brochure_requests
+  ADD COLUMN IF NOT EXISTS download_count INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_downloaded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS leads (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  email VARCHAR(255) NOT NULL UNIQUE,
+  source VARCHAR(50) NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  request_count INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_leads_source ON leads (source);
+CREATE INDEX IF NOT EXISTS idx_leads_last_seen_at ON leads (last_seen_at);
+
+CREATE OR REPLACE FUNCTION capture_lead(p_id UUID, p_email VARCHAR, p_source VARCHAR, p_user_id UUID, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF leads
+LANGUAGE sql AS $$
+  INSERT INTO leads (id, email, source, user_id, request_count, created_at, last_seen_at)
+  VALUES (p_id, lower(p_email), p_source, p_user_id, 1, p_at, p_at)
+  ON CONFLICT (email) DO UPDATE SET
+    request_count = leads.request_count + 1,
+    user_id = COALESCE(leads.user_id, EXCLUDED.user_id),
+    last_seen_at = EXCLUDED.last_seen_at
+  RETURNING *;
+$$;
+
+-- record_brochure_download counts one download of a brochure request's link
+CREATE OR REPLACE FUNCTION record_brochure_download(p_id UUID, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF brochure_requests
+LANGUAGE sql AS $$
+  UPDATE brochure_requests SET download_count = download_count + 1, last_downloaded_at = p_at
+  WHERE id = p_id
+  RETURNING *;
+$$;
+
+-- brochure_stats returns requests and downloads per property, most
+-- downloaded first. A NULL limit returns every property.
+CREATE OR REPLACE FUNCTION brochure_stats(p_limit INTEGER)
+RETURNS TABLE (property_id UUID, property_title VARCHAR, requests BIGINT, downloads BIGINT)
+LANGUAGE sql STABLE AS $$
+  SELECT b.property_id, p.title, COUNT(*), COALESCE(SUM(b.download_count), 0)
+  FROM brochure_requests b
+  JOIN properties p ON p.id = b.property_id
+  GROUP BY b.property_id, p.title
+  ORDER BY 4 DESC, 3 DESC, p.title
+  LIMIT p_limit;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    -- Leads are only read by admins through the service key
+    ALTER TABLE leads ENABLE ROW LEVEL SECURITY;
+  END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+-- The admin who handles enquiries about a property. Contact submissions
+-- about the property are emailed to them instead of ADMIN_EMAIL.
+ALTER TABLE properties ADD COLUMN IF NOT EXISTS agent_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_properties_agent_id ON properties (agent_id);
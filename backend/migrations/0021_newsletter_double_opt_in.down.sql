@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_newsletter_subscribers_status;
+ALTER TABLE newsletter_subscribers ALTER COLUMN active SET DEFAULT true;
+ALTER TABLE newsletter_subscribers
+  DROP COLUMN IF EXISTS confirmed_at,
+  DROP COLUMN IF EXISTS status;
@@ -0,0 +1,15 @@
+-- Double opt-in: new subscribers start pending until they follow the
+-- confirmation link. active is kept in step with status.
+ALTER TABLE newsletter_subscribers
+  ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'active', 'unsubscribed')),
+  ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing subscribers signed up before confirmation existed
+UPDATE newsletter_subscribers SET
+  status = CASE WHEN active THEN 'active' ELSE 'unsubscribed' END,
+  confirmed_at = CASE WHEN active THEN created_at END;
+
+ALTER TABLE newsletter_subscribers ALTER COLUMN active SET DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_subscribers_status ON newsletter_subscribers (status);
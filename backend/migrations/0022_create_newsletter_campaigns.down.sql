@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS add_campaign_recipients(UUID, UUID[], VARCHAR[]);
+DROP FUNCTION IF EXISTS transition_campaign(UUID, VARCHAR[], VARCHAR, TIMESTAMP WITH TIME ZONE);
+DROP VIEW IF EXISTS campaign_overview;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS newsletter_campaigns;
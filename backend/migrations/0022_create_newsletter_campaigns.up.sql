@@ -0,0 +1,107 @@
+-- Newsletter campaigns and one row per recipient, so a send interrupted by
+-- a restart carries on with the recipients still pending.
+CREATE TABLE IF NOT EXISTS newsletter_campaigns (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  subject VARCHAR(255) NOT NULL,
+  html_body TEXT NOT NULL,
+  text_body TEXT NOT NULL DEFAULT '',
+  segment VARCHAR(100) NOT NULL DEFAULT 'all',
+  status VARCHAR(20) NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'cancelled')),
+  scheduled_at TIMESTAMP WITH TIME ZONE,
+  started_at TIMESTAMP WITH TIME ZONE,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_campaigns_status ON newsletter_campaigns (status, scheduled_at);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  campaign_id UUID NOT NULL REFERENCES newsletter_campaigns(id) ON DELETE CASCADE,
+  subscriber_id UUID REFERENCES newsletter_subscribers(id) ON DELETE SET NULL,
+  email VARCHAR(255) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'sent', 'failed', 'skipped')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (campaign_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_status ON campaign_recipients (campaign_id, status, attempts);
+
+-- campaign_overview is what the API reads: campaigns with their recipient counts
+CREATE OR REPLACE VIEW campaign_overview AS
+  SELECT c.id, c.subject, c.html_body, c.text_body, c.segment, c.status,
+    c.scheduled_at, c.started_at, c.completed_at, c.created_by, c.created_at, c.updated_at,
+    COALESCE(r.recipient_count, 0) AS recipient_count,
+    COALESCE(r.sent_count, 0) AS sent_count,
+    COALESCE(r.failed_count, 0) AS failed_count,
+    COALESCE(r.skipped_count, 0) AS skipped_count
+  FROM newsletter_campaigns c
+  LEFT JOIN (
+    SELECT campaign_id,
+      COUNT(*) AS recipient_count,
+      COUNT(*) FILTER (WHERE status = 'sent') AS sent_count,
+      COUNT(*) FILTER (WHERE status = 'failed') AS failed_count,
+      COUNT(*) FILTER (WHERE status = 'skipped') AS skipped_count
+    FROM campaign_recipients
+    GROUP BY campaign_id
+  ) r ON r.campaign_id = c.id;
+
+-- transition_campaign moves a campaign between statuses, failing with HV409
+-- when someone else changed its status first
+CREATE OR REPLACE FUNCTION transition_campaign(p_id UUID, p_from VARCHAR[], p_to VARCHAR, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF newsletter_campaigns
+LANGUAGE plpgsql AS $$
+DECLARE
+  moved newsletter_campaigns;
+BEGIN
+  UPDATE newsletter_campaigns SET
+    status = p_to,
+    scheduled_at = CASE p_to WHEN 'scheduled' THEN p_at WHEN 'draft' THEN NULL ELSE scheduled_at END,
+    started_at = CASE WHEN p_to = 'sending' THEN COALESCE(started_at, p_at) ELSE started_at END,
+    completed_at = CASE WHEN p_to IN ('sent', 'cancelled') THEN p_at ELSE completed_at END,
+    updated_at = p_at
+  WHERE id = p_id AND status = ANY(p_from)
+  RETURNING * INTO moved;
+  IF NOT FOUND THEN
+    IF EXISTS (SELECT 1 FROM newsletter_campaigns WHERE id = p_id) THEN
+      RAISE EXCEPTION 'campaign status changed' USING ERRCODE = 'HV409';
+    END IF;
+    RAISE EXCEPTION 'campaign not found' USING ERRCODE = 'HV404';
+  END IF;
+  RETURN NEXT moved;
+END
+$$;
+
+-- add_campaign_recipients queues subscribers for a campaign in one
+-- statement; emails already queued are left alone
+CREATE OR REPLACE FUNCTION add_campaign_recipients(p_campaign_id UUID, p_subscriber_ids UUID[], p_emails VARCHAR[])
+RETURNS INTEGER
+LANGUAGE sql AS $$
+  WITH added AS (
+    INSERT INTO campaign_recipients (campaign_id, subscriber_id, email)
+    SELECT p_campaign_id, t.subscriber_id, lower(t.email)
+    FROM unnest(p_subscriber_ids, p_emails) AS t(subscriber_id, email)
+    ON CONFLICT (campaign_id, email) DO NOTHING
+    RETURNING 1
+  )
+  SELECT COUNT(*)::INTEGER FROM added;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    -- Campaigns are only managed by admins through the service key
+    ALTER TABLE newsletter_campaigns ENABLE ROW LEVEL SECURITY;
+    ALTER TABLE campaign_recipients ENABLE ROW LEVEL SECURITY;
+    -- Views run as their owner and would bypass RLS
+    REVOKE ALL ON campaign_overview FROM anon, authenticated;
+  END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS campaign_top_links(UUID, INTEGER);
+DROP FUNCTION IF EXISTS campaign_event_stats(UUID);
+DROP TABLE IF EXISTS campaign_events;
+ALTER TABLE newsletter_subscribers DROP COLUMN IF EXISTS tracking_opt_out;
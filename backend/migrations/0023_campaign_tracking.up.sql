@@ -0,0 +1,49 @@
+-- Opens and clicks of campaign emails, recorded per recipient. Subscribers
+-- who opt out of tracking get emails without the pixel and tracked links.
+ALTER TABLE newsletter_subscribers ADD COLUMN IF NOT EXISTS tracking_opt_out BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS campaign_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  campaign_id UUID NOT NULL REFERENCES newsletter_campaigns(id) ON DELETE CASCADE,
+  recipient_id UUID NOT NULL REFERENCES campaign_recipients(id) ON DELETE CASCADE,
+  type VARCHAR(10) NOT NULL CHECK (type IN ('open', 'click')),
+  url TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_events_campaign ON campaign_events (campaign_id, type);
+
+-- campaign_event_stats counts opens and clicks; a recipient who clicked has
+-- also opened, even if the tracking pixel was blocked
+CREATE OR REPLACE FUNCTION campaign_event_stats(p_campaign_id UUID)
+RETURNS TABLE (opens BIGINT, unique_opens BIGINT, clicks BIGINT, unique_clicks BIGINT)
+LANGUAGE sql STABLE AS $$
+  SELECT
+    COUNT(*) FILTER (WHERE type = 'open'),
+    COUNT(DISTINCT recipient_id),
+    COUNT(*) FILTER (WHERE type = 'click'),
+    COUNT(DISTINCT recipient_id) FILTER (WHERE type = 'click')
+  FROM campaign_events
+  WHERE campaign_id = p_campaign_id;
+$$;
+
+-- campaign_top_links returns the most clicked links of a campaign
+CREATE OR REPLACE FUNCTION campaign_top_links(p_campaign_id UUID, p_limit INTEGER)
+RETURNS TABLE (url TEXT, clicks BIGINT, unique_clicks BIGINT)
+LANGUAGE sql STABLE AS $$
+  SELECT e.url, COUNT(*), COUNT(DISTINCT e.recipient_id)
+  FROM campaign_events e
+  WHERE e.campaign_id = p_campaign_id AND e.type = 'click'
+  GROUP BY e.url
+  ORDER BY 2 DESC, 3 DESC, e.url
+  LIMIT p_limit;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    -- Events are recorded and read through the service key only
+    ALTER TABLE campaign_events ENABLE ROW LEVEL SECURITY;
+  END IF;
+END
+$$;
@@ -0,0 +1,7 @@
+DROP VIEW IF EXISTS subscriber_profiles;
+DROP INDEX IF EXISTS idx_newsletter_campaigns_segment;
+DROP TABLE IF EXISTS newsletter_segments;
+ALTER TABLE newsletter_subscribers
+  DROP COLUMN IF EXISTS interests,
+  DROP COLUMN IF EXISTS tags,
+  DROP COLUMN IF EXISTS source;
@@ -0,0 +1,46 @@
+-- Subscriber labels for targeting campaigns: the form they signed up
+-- through, admin tags and the blog categories they are interested in.
+ALTER TABLE newsletter_subscribers
+  ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'footer'
+    CHECK (source IN ('footer', 'brochure', 'contact')),
+  ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb,
+  ADD COLUMN IF NOT EXISTS interests JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- Saved segments; filter is evaluated by the API against active subscribers
+CREATE TABLE IF NOT EXISTS newsletter_segments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name VARCHAR(100) UNIQUE NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  filter TEXT NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_campaigns_segment ON newsletter_campaigns (segment);
+
+-- subscriber_profiles is what the API reads: subscribers with the account
+-- of the same email and that account's favorite properties
+CREATE OR REPLACE VIEW subscriber_profiles AS
+  SELECT s.id, s.email, s.name, s.status, s.active, s.created_at, s.confirmed_at, s.unsub_at,
+    s.tracking_opt_out, s.source, s.tags, s.interests,
+    u.id AS user_id,
+    COALESCE(f.property_ids, '[]'::jsonb) AS favorite_property_ids
+  FROM newsletter_subscribers s
+  LEFT JOIN users u ON LOWER(u.email) = LOWER(s.email)
+  LEFT JOIN LATERAL (
+    SELECT jsonb_agg(property_id ORDER BY created_at) AS property_ids
+    FROM favorites
+    WHERE user_id = u.id
+  ) f ON true;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    -- Segments are only managed by admins through the service key
+    ALTER TABLE newsletter_segments ENABLE ROW LEVEL SECURITY;
+    -- Views run as their owner and would bypass RLS
+    REVOKE ALL ON subscriber_profiles FROM anon, authenticated;
+  END IF;
+END
+$$;
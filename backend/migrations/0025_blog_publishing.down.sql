@@ -0,0 +1,9 @@
+DROP FUNCTION IF EXISTS transition_blog_post(UUID, VARCHAR[], VARCHAR, TIMESTAMP WITH TIME ZONE);
+DROP INDEX IF EXISTS idx_blog_posts_published_at;
+DROP INDEX IF EXISTS idx_blog_posts_status;
+ALTER TABLE blog_posts
+  DROP COLUMN IF EXISTS announcement_id,
+  DROP COLUMN IF EXISTS announce,
+  DROP COLUMN IF EXISTS published_at,
+  DROP COLUMN IF EXISTS publish_at,
+  DROP COLUMN IF EXISTS status;
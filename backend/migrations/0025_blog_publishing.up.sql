@@ -0,0 +1,41 @@
+-- Blog posts move between draft, scheduled and published. published is kept
+-- in step with status for the row level security policy and older clients.
+ALTER TABLE blog_posts
+  ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft', 'scheduled', 'published')),
+  ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS announce BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS announcement_id UUID REFERENCES newsletter_campaigns(id) ON DELETE SET NULL;
+
+UPDATE blog_posts SET status = 'published', published_at = created_at WHERE published;
+
+CREATE INDEX IF NOT EXISTS idx_blog_posts_status ON blog_posts (status, publish_at);
+CREATE INDEX IF NOT EXISTS idx_blog_posts_published_at ON blog_posts (published_at);
+
+-- transition_blog_post moves a post between statuses, failing with HV409
+-- when it is in none of the from statuses. published_at keeps the first
+-- publication, so republishing does not move a post up the blog.
+CREATE OR REPLACE FUNCTION transition_blog_post(p_id UUID, p_from VARCHAR[], p_to VARCHAR, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF blog_posts
+LANGUAGE plpgsql AS $$
+DECLARE
+  moved blog_posts;
+BEGIN
+  UPDATE blog_posts SET
+    status = p_to,
+    published = (p_to = 'published'),
+    publish_at = CASE WHEN p_to = 'scheduled' THEN p_at END,
+    published_at = CASE WHEN p_to = 'published' THEN COALESCE(published_at, p_at) ELSE published_at END,
+    updated_at = NOW()
+  WHERE id = p_id AND status = ANY(p_from)
+  RETURNING * INTO moved;
+  IF NOT FOUND THEN
+    IF EXISTS (SELECT 1 FROM blog_posts WHERE id = p_id) THEN
+      RAISE EXCEPTION 'blog post status changed' USING ERRCODE = 'HV409';
+    END IF;
+    RAISE EXCEPTION 'blog post not found' USING ERRCODE = 'HV404';
+  END IF;
+  RETURN NEXT moved;
+END
+$$;
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS add_blog_revision(UUID, UUID, VARCHAR, TEXT, TEXT, VARCHAR, JSONB, TEXT, TEXT, VARCHAR, UUID, VARCHAR, INTEGER, TIMESTAMP WITH TIME ZONE);
+DROP TABLE IF EXISTS blog_revisions;
@@ -0,0 +1,60 @@
+-- Every saved version of a blog post, numbered from 1 per post. Restoring an
+-- old revision adds a new one that records where it came from.
+CREATE TABLE IF NOT EXISTS blog_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  post_id UUID NOT NULL REFERENCES blog_posts(id) ON DELETE CASCADE,
+  revision INTEGER NOT NULL,
+  title VARCHAR(500) NOT NULL,
+  excerpt TEXT NOT NULL DEFAULT '',
+  content TEXT NOT NULL DEFAULT '',
+  category VARCHAR(100) NOT NULL DEFAULT '',
+  tags JSONB NOT NULL DEFAULT '[]'::jsonb,
+  image_url TEXT NOT NULL DEFAULT '',
+  image_alt TEXT NOT NULL DEFAULT '',
+  author VARCHAR(255) NOT NULL DEFAULT '',
+  edited_by UUID, -- admin who saved it; kept if the account is deleted
+  editor_email VARCHAR(255) NOT NULL DEFAULT '',
+  restored_from INTEGER,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (post_id, revision)
+);
+
+-- Existing posts start their history with their current content
+INSERT INTO blog_revisions (post_id, revision, title, excerpt, content, category, tags, image_url, image_alt, author, created_at)
+SELECT id, 1, title, COALESCE(excerpt, ''), COALESCE(content, ''), COALESCE(category, ''), COALESCE(tags, '[]'::jsonb),
+  COALESCE(image_url, ''), COALESCE(image_alt, ''), COALESCE(author, ''), COALESCE(updated_at, created_at, NOW())
+FROM blog_posts
+ON CONFLICT DO NOTHING;
+
+-- add_blog_revision stores a revision as the post's next number. The post
+-- row is locked, so editors saving at the same time get consecutive numbers.
+CREATE OR REPLACE FUNCTION add_blog_revision(
+  p_id UUID, p_post_id UUID, p_title VARCHAR, p_excerpt TEXT, p_content TEXT, p_category VARCHAR,
+  p_tags JSONB, p_image_url TEXT, p_image_alt TEXT, p_author VARCHAR, p_edited_by UUID,
+  p_editor_email VARCHAR, p_restored_from INTEGER, p_created_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF blog_revisions
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM 1 FROM blog_posts WHERE id = p_post_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'blog post not found' USING ERRCODE = 'HV404';
+  END IF;
+  RETURN QUERY
+  INSERT INTO blog_revisions (id, post_id, revision, title, excerpt, content, category, tags,
+    image_url, image_alt, author, edited_by, editor_email, restored_from, created_at)
+  SELECT p_id, p_post_id, COALESCE(MAX(revision), 0) + 1, p_title, p_excerpt, p_content, p_category,
+    COALESCE(p_tags, '[]'::jsonb), p_image_url, p_image_alt, p_author, p_edited_by, p_editor_email,
+    p_restored_from, p_created_at
+  FROM blog_revisions WHERE post_id = p_post_id
+  RETURNING *;
+END
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    -- Revisions are only managed by admins through the service key
+    ALTER TABLE blog_revisions ENABLE ROW LEVEL SECURITY;
+  END IF;
+END
+$$;
@@ -0,0 +1,31 @@
+DROP FUNCTION IF EXISTS add_blog_revision(UUID, UUID, VARCHAR, TEXT, TEXT, VARCHAR, JSONB, TEXT, TEXT, VARCHAR, UUID, VARCHAR, INTEGER, TIMESTAMP WITH TIME ZONE);
+
+ALTER TABLE blog_revisions RENAME COLUMN content_markdown TO content;
+
+ALTER TABLE blog_posts
+  DROP COLUMN IF EXISTS reading_time_minutes,
+  DROP COLUMN IF EXISTS table_of_contents,
+  DROP COLUMN IF EXISTS content_html;
+ALTER TABLE blog_posts RENAME COLUMN content_markdown TO content;
+
+CREATE OR REPLACE FUNCTION add_blog_revision(
+  p_id UUID, p_post_id UUID, p_title VARCHAR, p_excerpt TEXT, p_content TEXT, p_category VARCHAR,
+  p_tags JSONB, p_image_url TEXT, p_image_alt TEXT, p_author VARCHAR, p_edited_by UUID,
+  p_editor_email VARCHAR, p_restored_from INTEGER, p_created_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF blog_revisions
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM 1 FROM blog_posts WHERE id = p_post_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'blog post not found' USING ERRCODE = 'HV404';
+  END IF;
+  RETURN QUERY
+  INSERT INTO blog_revisions (id, post_id, revision, title, excerpt, content, category, tags,
+    image_url, image_alt, author, edited_by, editor_email, restored_from, created_at)
+  SELECT p_id, p_post_id, COALESCE(MAX(revision), 0) + 1, p_title, p_excerpt, p_content, p_category,
+    COALESCE(p_tags, '[]'::jsonb), p_image_url, p_image_alt, p_author, p_edited_by, p_editor_email,
+    p_restored_from, p_created_at
+  FROM blog_revisions WHERE post_id = p_post_id
+  RETURNING *;
+END
+$$;
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS convert_reservation(UUID, JSONB);
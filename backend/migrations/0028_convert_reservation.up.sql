@@ -0,0 +1,18 @@
+-- convert_reservation sells the plots of an active reservation and stores
+-- the buyer's payment schedule in the same transaction, so a sale never
+-- exists without its schedule. p_schedule is a payment_schedules row as
+-- JSON; errors are those of complete_reservation.
+CREATE OR REPLACE FUNCTION convert_reservation(p_id UUID, p_schedule JSONB)
+RETURNS SETOF reservations
+LANGUAGE plpgsql AS $$
+DECLARE
+  converted reservations;
+BEGIN
+  SELECT * INTO converted FROM complete_reservation(p_id, 'converted');
+
+  INSERT INTO payment_schedules
+  SELECT * FROM jsonb_populate_record(NULL::payment_schedules, p_schedule);
+
+  RETURN NEXT converted;
+END
+$$;
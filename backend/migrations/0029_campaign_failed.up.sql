@@ -0,0 +1,31 @@
+-- Campaigns whose audience cannot be resolved when they are due, such as
+-- one addressed to a deleted segment, are marked failed instead of blocking
+-- the sender. They can be edited and scheduled again.
+ALTER TABLE newsletter_campaigns DROP CONSTRAINT IF EXISTS newsletter_campaigns_status_check;
+ALTER TABLE newsletter_campaigns ADD CONSTRAINT newsletter_campaigns_status_check
+  CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'cancelled', 'failed'));
+
+CREATE OR REPLACE FUNCTION transition_campaign(p_id UUID, p_from VARCHAR[], p_to VARCHAR, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS SETOF newsletter_campaigns
+LANGUAGE plpgsql AS $$
+DECLARE
+  moved newsletter_campaigns;
+BEGIN
+  UPDATE newsletter_campaigns SET
+    status = p_to,
+    scheduled_at = CASE p_to WHEN 'scheduled' THEN p_at WHEN 'draft' THEN NULL ELSE scheduled_at END,
+    started_at = CASE WHEN p_to = 'sending' THEN COALESCE(started_at, p_at) ELSE started_at END,
+    completed_at = CASE WHEN p_to IN ('sent', 'cancelled', 'failed') THEN p_at
+      WHEN p_to = 'scheduled' THEN NULL ELSE completed_at END,
+    updated_at = p_at
+  WHERE id = p_id AND status = ANY(p_from)
+  RETURNING * INTO moved;
+  IF NOT FOUND THEN
+    IF EXISTS (SELECT 1 FROM newsletter_campaigns WHERE id = p_id) THEN
+      RAISE EXCEPTION 'campaign status changed' USING ERRCODE = 'HV409';
+    END IF;
+    RAISE EXCEPTION 'campaign not found' USING ERRCODE = 'HV404';
+  END IF;
+  RETURN NEXT moved;
+END
+$$;
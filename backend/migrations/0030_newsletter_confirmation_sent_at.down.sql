@@ -0,0 +1,24 @@
+DROP VIEW IF EXISTS subscriber_profiles;
+
+CREATE VIEW subscriber_profiles AS
+  SELECT s.id, s.email, s.name, s.status, s.active, s.created_at, s.confirmed_at, s.unsub_at,
+    s.tracking_opt_out, s.source, s.tags, s.interests,
+    u.id AS user_id,
+    COALESCE(f.property_ids, '[]'::jsonb) AS favorite_property_ids
+  FROM newsletter_subscribers s
+  LEFT JOIN users u ON LOWER(u.email) = LOWER(s.email)
+  LEFT JOIN LATERAL (
+    SELECT jsonb_agg(property_id ORDER BY created_at) AS property_ids
+    FROM favorites
+    WHERE user_id = u.id
+  ) f ON true;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth') THEN
+    REVOKE ALL ON subscriber_profiles FROM anon, authenticated;
+  END IF;
+END
+$$;
+
+ALTER TABLE newsletter_subscribers DROP COLUMN IF EXISTS confirmation_sent_at;
@@ -0,0 +1,17 @@
+-- When the last confirmation email went to a subscriber, so repeated
+-- subscribe requests for the same address do not each send another one
+ALTER TABLE newsletter_subscribers ADD COLUMN IF NOT EXISTS confirmation_sent_at TIMESTAMP WITH TIME ZONE;
+
+CREATE OR REPLACE VIEW subscriber_profiles AS
+  SELECT s.id, s.email, s.name, s.status, s.active, s.created_at, s.confirmed_at, s.unsub_at,
+    s.tracking_opt_out, s.source, s.tags, s.interests,
+    u.id AS user_id,
+    COALESCE(f.property_ids, '[]'::jsonb) AS favorite_property_ids,
+    s.confirmation_sent_at
+  FROM newsletter_subscribers s
+  LEFT JOIN users u ON LOWER(u.email) = LOWER(s.email)
+  LEFT JOIN LATERAL (
+    SELECT jsonb_agg(property_id ORDER BY created_at) AS property_ids
+    FROM favorites
+    WHERE user_id = u.id
+  ) f ON true;
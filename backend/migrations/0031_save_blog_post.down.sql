@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS save_blog_post(JSONB, JSONB, JSONB);
+DROP FUNCTION IF EXISTS create_blog_post(JSONB, JSONB);
+DROP FUNCTION IF EXISTS add_blog_revision_json(JSONB);
@@ -0,0 +1,65 @@
+-- create_blog_post and save_blog_post write a blog post together with its
+-- revision, and a renamed post's old slug redirect, in one transaction, so
+-- a post never changes without a matching revision. p_post, p_redirect and
+-- p_revision are blog_posts, slug_redirects and blog_revisions rows as
+-- JSON; p_redirect is NULL when the slug did not change. Both return the
+-- stored revision with its number.
+CREATE OR REPLACE FUNCTION add_blog_revision_json(p_revision JSONB)
+RETURNS SETOF blog_revisions
+LANGUAGE plpgsql AS $$
+DECLARE
+  r blog_revisions;
+BEGIN
+  r := jsonb_populate_record(NULL::blog_revisions, p_revision);
+  RETURN QUERY SELECT * FROM add_blog_revision(r.id, r.post_id, r.title, r.excerpt, r.content_markdown,
+    r.category, r.tags, r.image_url, r.image_alt, r.author, r.edited_by, r.editor_email, r.restored_from,
+    r.created_at);
+END
+$$;
+
+CREATE OR REPLACE FUNCTION create_blog_post(p_post JSONB, p_revision JSONB)
+RETURNS SETOF blog_revisions
+LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO blog_posts (id, title, slug, excerpt, content_markdown, content_html, table_of_contents,
+    reading_time_minutes, category, tags, image_url, image_alt, author, published, status, publish_at,
+    published_at, announce, created_at, updated_at)
+  SELECT id, title, slug, excerpt, content_markdown, content_html, COALESCE(table_of_contents, '[]'::jsonb),
+    reading_time_minutes, category, tags, image_url, image_alt, author, published, status, publish_at,
+    published_at, announce, created_at, updated_at
+  FROM jsonb_populate_record(NULL::blog_posts, p_post);
+
+  RETURN QUERY SELECT * FROM add_blog_revision_json(p_revision);
+END
+$$;
+
+-- save_blog_post leaves the status columns alone; they only change through
+-- transition_blog_post
+CREATE OR REPLACE FUNCTION save_blog_post(p_post JSONB, p_redirect JSONB, p_revision JSONB)
+RETURNS SETOF blog_revisions
+LANGUAGE plpgsql AS $$
+DECLARE
+  p blog_posts;
+BEGIN
+  p := jsonb_populate_record(NULL::blog_posts, p_post);
+  UPDATE blog_posts SET
+    title = p.title, slug = p.slug, excerpt = p.excerpt, content_markdown = p.content_markdown,
+    content_html = p.content_html, table_of_contents = COALESCE(p.table_of_contents, '[]'::jsonb),
+    reading_time_minutes = p.reading_time_minutes, category = p.category, tags = p.tags,
+    image_url = p.image_url, image_alt = p.image_alt, author = p.author, announce = p.announce,
+    updated_at = p.updated_at
+  WHERE id = p.id;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'blog post not found' USING ERRCODE = 'HV404';
+  END IF;
+
+  IF p_redirect IS NOT NULL AND p_redirect <> 'null'::jsonb THEN
+    INSERT INTO slug_redirects (id, entity_type, old_slug, entity_id, created_at)
+    SELECT id, entity_type, old_slug, entity_id, created_at
+    FROM jsonb_populate_record(NULL::slug_redirects, p_redirect)
+    ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = EXCLUDED.created_at;
+  END IF;
+
+  RETURN QUERY SELECT * FROM add_blog_revision_json(p_revision);
+END
+$$;
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS save_property(JSONB, JSONB);
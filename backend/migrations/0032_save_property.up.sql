@@ -0,0 +1,34 @@
+-- save_property writes new details of a property and, when it was renamed,
+-- the redirect from its old slug in one transaction, so a renamed property
+-- never loses its old address. p_property and p_redirect are properties and
+-- slug_redirects rows as JSON; p_redirect is NULL when the slug did not
+-- change. Returns the saved property.
+CREATE OR REPLACE FUNCTION save_property(p_property JSONB, p_redirect JSONB)
+RETURNS SETOF properties
+LANGUAGE plpgsql AS $$
+DECLARE
+  p properties;
+  saved properties;
+BEGIN
+  p := jsonb_populate_record(NULL::properties, p_property);
+  UPDATE properties SET
+    title = p.title, slug = p.slug, description = p.description, location = p.location, price = p.price,
+    status = p.status, units = p.units, acres = p.acres, features = COALESCE(p.features, '[]'::jsonb),
+    image_url = p.image_url, image_alt = p.image_alt, latitude = p.latitude, longitude = p.longitude,
+    boundary = p.boundary, agent_id = p.agent_id, updated_at = p.updated_at
+  WHERE id = p.id
+  RETURNING * INTO saved;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'property not found' USING ERRCODE = 'HV404';
+  END IF;
+
+  IF p_redirect IS NOT NULL AND p_redirect <> 'null'::jsonb THEN
+    INSERT INTO slug_redirects (id, entity_type, old_slug, entity_id, created_at)
+    SELECT id, entity_type, old_slug, entity_id, created_at
+    FROM jsonb_populate_record(NULL::slug_redirects, p_redirect)
+    ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = EXCLUDED.created_at;
+  END IF;
+
+  RETURN NEXT saved;
+END
+$$;
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS record_payment(JSONB);
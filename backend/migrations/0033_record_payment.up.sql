@@ -0,0 +1,33 @@
+-- record_payment inserts a payment unless it would take the schedule past
+-- its total. The schedule row is locked first, so two admins recording
+-- payments against the same schedule at once cannot overpay it between
+-- them. p_payment is a payments row as JSON; the receipt number comes from
+-- the column default. Returns the stored payment.
+CREATE OR REPLACE FUNCTION record_payment(p_payment JSONB)
+RETURNS SETOF payments
+LANGUAGE plpgsql AS $$
+DECLARE
+  p payments;
+  total DECIMAL(14, 2);
+  paid DECIMAL(14, 2);
+BEGIN
+  p := jsonb_populate_record(NULL::payments, p_payment);
+  SELECT total_amount INTO total FROM payment_schedules WHERE id = p.schedule_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'payment schedule not found' USING ERRCODE = 'HV404';
+  END IF;
+
+  SELECT COALESCE(SUM(amount), 0) INTO paid
+  FROM payments WHERE schedule_id = p.schedule_id AND status = 'recorded';
+  IF paid + p.amount > total THEN
+    RAISE EXCEPTION 'payment exceeds the outstanding balance' USING ERRCODE = 'HV409';
+  END IF;
+
+  RETURN QUERY
+  INSERT INTO payments (id, schedule_id, user_id, property_id, amount, method, reference,
+    received_at, status, recorded_by, created_at)
+  VALUES (p.id, p.schedule_id, p.user_id, p.property_id, p.amount, p.method, COALESCE(p.reference, ''),
+    p.received_at, p.status, p.recorded_by, p.created_at)
+  RETURNING *;
+END
+$$;
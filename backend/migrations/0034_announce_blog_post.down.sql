@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS announce_blog_post(UUID, JSONB);
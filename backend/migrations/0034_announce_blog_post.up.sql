@@ -0,0 +1,31 @@
+-- announce_blog_post stores the campaign announcing a blog post and links it
+-- to the post in one transaction. The post row is locked first, so two
+-- publishers racing on the same post create one campaign between them; the
+-- second fails with HV409. p_campaign is a newsletter_campaigns row as JSON.
+-- Returns the stored campaign.
+CREATE OR REPLACE FUNCTION announce_blog_post(p_post_id UUID, p_campaign JSONB)
+RETURNS SETOF newsletter_campaigns
+LANGUAGE plpgsql AS $$
+DECLARE
+  announced UUID;
+  c newsletter_campaigns;
+BEGIN
+  SELECT announcement_id INTO announced FROM blog_posts WHERE id = p_post_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'blog post not found' USING ERRCODE = 'HV404';
+  END IF;
+  IF announced IS NOT NULL THEN
+    RAISE EXCEPTION 'blog post already announced' USING ERRCODE = 'HV409';
+  END IF;
+
+  c := jsonb_populate_record(NULL::newsletter_campaigns, p_campaign);
+  INSERT INTO newsletter_campaigns (id, subject, html_body, text_body, segment, status, scheduled_at,
+    created_by, created_at, updated_at)
+  VALUES (c.id, c.subject, c.html_body, COALESCE(c.text_body, ''), c.segment, c.status, c.scheduled_at,
+    c.created_by, c.created_at, c.updated_at)
+  RETURNING * INTO c;
+  UPDATE blog_posts SET announcement_id = c.id WHERE id = p_post_id;
+
+  RETURN NEXT c;
+END
+$$;
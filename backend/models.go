@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -19,60 +20,257 @@ type User struct {
 
 // Property represents a real estate property
 type Property struct {
-	ID          string    `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Slug        string    `json:"slug" db:"slug"`
-	Description string    `json:"description" db:"description"`
-	Location    string    `json:"location" db:"location"`
-	Price       float64   `json:"price" db:"price"`
-	Status      string    `json:"status" db:"status"` // available, sold, pending
-	Units       int       `json:"units" db:"units"`
-	Acres       float64   `json:"acres" db:"acres"`
-	Features    []string  `json:"features" db:"features"`
-	ImageURL    string    `json:"image_url" db:"image_url"`
-	ImageAlt    string    `json:"image_alt" db:"image_alt"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          string          `json:"id" db:"id"`
+	Title       string          `json:"title" db:"title" validate:"required,max=255"`
+	Slug        string          `json:"slug" db:"slug"`
+	Description string          `json:"description" db:"description"`
+	Location    string          `json:"location" db:"location" validate:"max=255"`
+	Latitude    *float64        `json:"latitude" db:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude   *float64        `json:"longitude" db:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+	Boundary    json.RawMessage `json:"boundary,omitempty" db:"boundary" validate:"omitempty,geojson_polygon"` // GeoJSON Polygon or MultiPolygon
+	Price       float64         `json:"price" db:"price" validate:"gte=0"`
+	Status      string          `json:"status" db:"status" validate:"omitempty,oneof=available sold pending"`
+	Units       int             `json:"units" db:"units" validate:"gte=0"`
+	Acres       float64         `json:"acres" db:"acres" validate:"gte=0"`
+	Features    []string        `json:"features" db:"features"`
+	ImageURL    string          `json:"image_url" db:"image_url" validate:"max=2048"`
+	ImageAlt    string          `json:"image_alt" db:"image_alt"`
+	AgentID     *string         `json:"agent_id" db:"agent_id" validate:"omitempty,uuid"` // admin who handles enquiries
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
 }
 
-// BlogPost represents a blog article
+// BlogPost represents a blog article. A post is a draft until published,
+// either straight away or by the publisher once PublishAt has passed while
+// it is scheduled. Only published posts are public.
 type BlogPost struct {
-	ID        string    `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Slug      string    `json:"slug" db:"slug"`
-	Excerpt   string    `json:"excerpt" db:"excerpt"`
-	Content   string    `json:"content" db:"content"`
-	Category  string    `json:"category" db:"category"` // Land, Homes, Construction, Investment
-	Tags      []string  `json:"tags" db:"tags"`
-	ImageURL  string    `json:"image_url" db:"image_url"`
-	ImageAlt  string    `json:"image_alt" db:"image_alt"`
-	Author    string    `json:"author" db:"author"`
-	Published bool      `json:"published" db:"published"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID      string `json:"id" db:"id"`
+	Title   string `json:"title" db:"title" validate:"required,max=255"`
+	Slug    string `json:"slug" db:"slug"`
+	Excerpt string `json:"excerpt" db:"excerpt"`
+	// ContentMarkdown is the post as written; ContentHTML, TableOfContents
+	// and ReadingTimeMinutes are rendered from it whenever it is saved
+	ContentMarkdown    string        `json:"content_markdown" db:"content_markdown" validate:"required,max=100000"`
+	ContentHTML        string        `json:"content_html" db:"content_html"`
+	TableOfContents    []BlogHeading `json:"table_of_contents" db:"table_of_contents"`
+	ReadingTimeMinutes int           `json:"reading_time_minutes" db:"reading_time_minutes"`
+	// Content is the former name of ContentMarkdown, still accepted on writes
+	Content   string   `json:"content,omitempty" db:"-"`
+	Category  string   `json:"category" db:"category" validate:"max=100"` // Land, Homes, Construction, Investment
+	Tags      []string `json:"tags" db:"tags"`
+	ImageURL  string   `json:"image_url" db:"image_url" validate:"max=2048"`
+	ImageAlt  string   `json:"image_alt" db:"image_alt"`
+	Author    string   `json:"author" db:"author"`
+	Published bool     `json:"published" db:"published"` // mirrors Status == BlogPublished
+	Status    string   `json:"status" db:"status"`
+	// PublishAt is when a scheduled post goes live
+	PublishAt *time.Time `json:"publish_at" db:"publish_at"`
+	// PublishedAt is when the post first went live; republishing keeps it
+	PublishedAt *time.Time `json:"published_at" db:"published_at"`
+	// Announce emails subscribers a newsletter campaign when the post is
+	// published; AnnouncementID is that campaign once created
+	Announce       bool      `json:"announce" db:"announce"`
+	AnnouncementID *string   `json:"announcement_id" db:"announcement_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BlogHeading is an entry of a blog post's table of contents. ID is the
+// anchor of the heading in the post's HTML.
+type BlogHeading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+// BlogRevision is a saved version of a blog post's content. A revision is
+// stored when a post is created and on every update, numbered from 1;
+// restoring an old revision adds a new one with RestoredFrom set.
+type BlogRevision struct {
+	ID              string    `json:"id" db:"id"`
+	PostID          string    `json:"post_id" db:"post_id"`
+	Revision        int       `json:"revision" db:"revision"`
+	Title           string    `json:"title" db:"title"`
+	Excerpt         string    `json:"excerpt" db:"excerpt"`
+	ContentMarkdown string    `json:"content_markdown" db:"content_markdown"`
+	Category        string    `json:"category" db:"category"`
+	Tags            []string  `json:"tags" db:"tags"`
+	ImageURL        string    `json:"image_url" db:"image_url"`
+	ImageAlt        string    `json:"image_alt" db:"image_alt"`
+	Author          string    `json:"author" db:"author"`
+	EditedBy        *string   `json:"edited_by" db:"edited_by"`
+	EditorEmail     string    `json:"editor_email" db:"editor_email"`
+	RestoredFrom    *int      `json:"restored_from" db:"restored_from"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// Blog post statuses
+const (
+	BlogDraft     = "draft"
+	BlogScheduled = "scheduled"
+	BlogPublished = "published"
+)
+
+// Plot is one saleable plot of a land estate
+type Plot struct {
+	ID            string    `json:"id" db:"id"`
+	PropertyID    string    `json:"property_id" db:"property_id"`
+	PlotNumber    string    `json:"plot_number" db:"plot_number"`
+	SizeSqm       float64   `json:"size_sqm" db:"size_sqm"`
+	Price         float64   `json:"price" db:"price"`
+	IsCorner      bool      `json:"is_corner" db:"is_corner"`
+	Status        string    `json:"status" db:"status"`                           // available, reserved, sold
+	ReservationID *string   `json:"reservation_id,omitempty" db:"reservation_id"` // holding or buying reservation
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Reservation is a buyer's time-limited hold on one or more plots
+type Reservation struct {
+	ID         string    `json:"id" db:"id"`
+	PropertyID string    `json:"property_id" db:"property_id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	PlotIDs    []string  `json:"plot_ids" db:"plot_ids"`
+	Status     string    `json:"status" db:"status"` // active, converted, cancelled, expired
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentPlan is a way of paying for a property's plots. A plan with zero
+// months is an outright purchase.
+type PaymentPlan struct {
+	ID             string    `json:"id" db:"id"`
+	PropertyID     string    `json:"property_id" db:"property_id"`
+	Name           string    `json:"name" db:"name"`
+	DepositPercent float64   `json:"deposit_percent" db:"deposit_percent"`
+	Months         int       `json:"months" db:"months"`
+	MarkupPercent  float64   `json:"markup_percent" db:"markup_percent"`
+	IsActive       bool      `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Installment is one amount due under a payment schedule. Number 0 is the
+// deposit, or the single payment of an outright purchase.
+type Installment struct {
+	Number  int       `json:"number"`
+	DueDate time.Time `json:"due_date"`
+	Amount  float64   `json:"amount"`
+}
+
+// PaymentSchedule is what a buyer owes and when. Quotes are computed on the
+// fly; schedules stored for a converted reservation have an ID and a buyer.
+// The plan terms are copied so later plan edits do not change the schedule.
+type PaymentSchedule struct {
+	ID             string        `json:"id,omitempty" db:"id"`
+	UserID         string        `json:"user_id,omitempty" db:"user_id"`
+	PropertyID     string        `json:"property_id" db:"property_id"`
+	ReservationID  *string       `json:"reservation_id,omitempty" db:"reservation_id"`
+	PaymentPlanID  *string       `json:"payment_plan_id,omitempty" db:"payment_plan_id"`
+	PlanName       string        `json:"plan_name" db:"plan_name"`
+	BasePrice      float64       `json:"base_price" db:"base_price"`
+	DepositPercent float64       `json:"deposit_percent" db:"deposit_percent"`
+	Months         int           `json:"months" db:"months"`
+	MarkupPercent  float64       `json:"markup_percent" db:"markup_percent"`
+	TotalAmount    float64       `json:"total_amount" db:"total_amount"`
+	Installments   []Installment `json:"installments" db:"installments"`
+	StartDate      time.Time     `json:"start_date" db:"start_date"`
+	CreatedAt      time.Time     `json:"created_at,omitempty" db:"created_at"`
+}
+
+// Payment is money received from a buyer against a payment schedule.
+// Payments are never deleted; a mistaken one is reversed and no longer
+// counts towards the balance.
+type Payment struct {
+	ID             string     `json:"id" db:"id"`
+	ScheduleID     string     `json:"schedule_id" db:"schedule_id"`
+	UserID         string     `json:"user_id" db:"user_id"`
+	PropertyID     string     `json:"property_id" db:"property_id"`
+	Amount         float64    `json:"amount" db:"amount"`
+	Method         string     `json:"method" db:"method"` // bank_transfer, card, cash, cheque
+	Reference      string     `json:"reference" db:"reference"`
+	ReceivedAt     time.Time  `json:"received_at" db:"received_at"`
+	ReceiptNumber  string     `json:"receipt_number,omitempty" db:"receipt_number"`
+	Status         string     `json:"status" db:"status"` // recorded, reversed
+	ReversedAt     *time.Time `json:"reversed_at,omitempty" db:"reversed_at"`
+	ReversalReason string     `json:"reversal_reason,omitempty" db:"reversal_reason"`
+	RecordedBy     string     `json:"recorded_by" db:"recorded_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SlugRedirect maps a slug an entity used to have onto the entity, so old
+// links can be redirected to its current slug
+type SlugRedirect struct {
+	ID         string    `json:"id" db:"id"`
+	EntityType string    `json:"entity_type" db:"entity_type"` // property, blog_post
+	OldSlug    string    `json:"old_slug" db:"old_slug"`
+	EntityID   string    `json:"entity_id" db:"entity_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
 // ContactSubmission represents a contact form submission
 type ContactSubmission struct {
-	ID          string    `json:"id" db:"id"`
-	FirstName   string    `json:"first_name" db:"first_name"`
-	LastName    string    `json:"last_name" db:"last_name"`
-	Email       string    `json:"email" db:"email"`
-	Phone       string    `json:"phone" db:"phone"`
-	Message     string    `json:"message" db:"message"`
-	PropertyID  *string   `json:"property_id" db:"property_id"`
-	IsRead      bool      `json:"is_read" db:"is_read"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID         string    `json:"id" db:"id"`
+	FirstName  string    `json:"first_name" db:"first_name"`
+	LastName   string    `json:"last_name" db:"last_name"`
+	Email      string    `json:"email" db:"email"`
+	Phone      string    `json:"phone" db:"phone"`
+	Message    string    `json:"message" db:"message"`
+	PropertyID *string   `json:"property_id" db:"property_id"`
+	IsRead     bool      `json:"is_read" db:"is_read"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
 // NewsletterSubscriber represents a newsletter subscription
 type NewsletterSubscriber struct {
-	ID        string    `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Name      string    `json:"name" db:"name"`
-	Active    bool      `json:"active" db:"active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UnsubAt   *time.Time `json:"unsub_at" db:"unsub_at"`
+	ID          string     `json:"id" db:"id"`
+	Email       string     `json:"email" db:"email"`
+	Name        string     `json:"name" db:"name"`
+	Status      string     `json:"status" db:"status"`
+	Active      bool       `json:"active" db:"active"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	UnsubAt     *time.Time `json:"unsub_at" db:"unsub_at"`
+	// ConfirmationSentAt is when the last confirmation email was sent
+	ConfirmationSentAt *time.Time `json:"confirmation_sent_at" db:"confirmation_sent_at"`
+	// TrackingOptOut stops campaign emails tracking opens and clicks
+	TrackingOptOut bool     `json:"tracking_opt_out" db:"tracking_opt_out"`
+	Source         string   `json:"source" db:"source"`       // form they first subscribed through
+	Tags           []string `json:"tags" db:"tags"`           // set by admins
+	Interests      []string `json:"interests" db:"interests"` // blog categories
+	// UserID and FavoritePropertyIDs come from the account with the same
+	// email, if there is one; they cannot be written
+	UserID              *string  `json:"user_id" db:"user_id"`
+	FavoritePropertyIDs []string `json:"favorite_property_ids" db:"favorite_property_ids"`
+}
+
+// Subscriber statuses. Only active subscribers receive the newsletter;
+// Active mirrors Status == SubscriberActive for older clients.
+const (
+	SubscriberPending      = "pending"
+	SubscriberActive       = "active"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// Subscriber sources: the footer signup form, or the newsletter box of the
+// brochure and contact forms
+const (
+	SubscriberSourceFooter   = "footer"
+	SubscriberSourceBrochure = "brochure"
+	SubscriberSourceContact  = "contact"
+)
+
+// Segment is a saved audience for campaigns: the active subscribers that
+// match Filter. See parseSegmentFilter for the filter syntax.
+type Segment struct {
+	ID          string    `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Filter      string    `json:"filter" db:"filter"`
+	CreatedBy   *string   `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // Review represents a user review
@@ -96,11 +294,123 @@ type Favorite struct {
 
 // BrochureRequest represents a brochure download request
 type BrochureRequest struct {
-	ID         string    `json:"id" db:"id"`
-	UserID     *string   `json:"user_id" db:"user_id"`
-	Email      string    `json:"email" db:"email"`
-	PropertyID string    `json:"property_id" db:"property_id"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID               string     `json:"id" db:"id"`
+	UserID           *string    `json:"user_id" db:"user_id"`
+	Email            string     `json:"email" db:"email"`
+	PropertyID       string     `json:"property_id" db:"property_id"`
+	DownloadCount    int        `json:"download_count" db:"download_count"`
+	LastDownloadedAt *time.Time `json:"last_downloaded_at" db:"last_downloaded_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Lead is a prospective buyer known by email, e.g. from a brochure request
+type Lead struct {
+	ID     string  `json:"id" db:"id"`
+	Email  string  `json:"email" db:"email"`
+	Source string  `json:"source" db:"source"` // where the lead first came from
+	UserID *string `json:"user_id" db:"user_id"`
+	// RequestCount is how many times the lead has asked for something
+	RequestCount int       `json:"request_count" db:"request_count"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// Campaign is a newsletter email to an audience of active subscribers. A
+// draft is scheduled, the sender moves it to sending once ScheduledAt has
+// passed and to sent when every recipient has been tried. The counts are
+// rolled up from its recipients.
+type Campaign struct {
+	ID       string `json:"id" db:"id"`
+	Subject  string `json:"subject" db:"subject"`
+	HTMLBody string `json:"html_body" db:"html_body"`
+	// TextBody is the plain text alternative; empty derives it from HTMLBody
+	TextBody       string     `json:"text_body" db:"text_body"`
+	Segment        string     `json:"segment" db:"segment"` // "all" active subscribers or a segment ID
+	Status         string     `json:"status" db:"status"`
+	ScheduledAt    *time.Time `json:"scheduled_at" db:"scheduled_at"`
+	StartedAt      *time.Time `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at" db:"completed_at"`
+	RecipientCount int        `json:"recipient_count" db:"recipient_count"`
+	SentCount      int        `json:"sent_count" db:"sent_count"`
+	FailedCount    int        `json:"failed_count" db:"failed_count"`
+	SkippedCount   int        `json:"skipped_count" db:"skipped_count"`
+	CreatedBy      *string    `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Campaign statuses
+const (
+	CampaignDraft     = "draft"
+	CampaignScheduled = "scheduled"
+	CampaignSending   = "sending"
+	CampaignSent      = "sent"
+	CampaignCancelled = "cancelled"
+	CampaignFailed    = "failed"
+)
+
+// CampaignRecipient is the delivery of a campaign to one subscriber.
+// Recipients are pending until sent, failed after too many attempts, or
+// skipped when the subscriber left before their turn.
+type CampaignRecipient struct {
+	ID           string     `json:"id" db:"id"`
+	CampaignID   string     `json:"campaign_id" db:"campaign_id"`
+	SubscriberID *string    `json:"subscriber_id" db:"subscriber_id"`
+	Email        string     `json:"email" db:"email"`
+	Status       string     `json:"status" db:"status"` // pending, sent, failed, skipped
+	Attempts     int        `json:"attempts" db:"attempts"`
+	Error        string     `json:"error" db:"error"`
+	SentAt       *time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CampaignEvent is an open or a link click by a campaign recipient
+type CampaignEvent struct {
+	ID          string    `json:"id" db:"id"`
+	CampaignID  string    `json:"campaign_id" db:"campaign_id"`
+	RecipientID string    `json:"recipient_id" db:"recipient_id"`
+	Type        string    `json:"type" db:"type"` // open, click
+	URL         string    `json:"url" db:"url"`   // the link clicked
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// CampaignLinkStats counts the clicks on one link of a campaign
+type CampaignLinkStats struct {
+	URL          string `json:"url" db:"url"`
+	Clicks       int    `json:"clicks" db:"clicks"`
+	UniqueClicks int    `json:"unique_clicks" db:"unique_clicks"`
+}
+
+// CampaignStats summarises how recipients engaged with a campaign. A click
+// counts as an open too, since images are often blocked. Rates are
+// percentages of the emails sent.
+type CampaignStats struct {
+	CampaignID   string              `json:"campaign_id" db:"-"`
+	Sent         int                 `json:"sent" db:"-"`
+	Opens        int                 `json:"opens" db:"opens"`
+	UniqueOpens  int                 `json:"unique_opens" db:"unique_opens"`
+	Clicks       int                 `json:"clicks" db:"clicks"`
+	UniqueClicks int                 `json:"unique_clicks" db:"unique_clicks"`
+	OpenRate     float64             `json:"open_rate" db:"-"`
+	ClickRate    float64             `json:"click_rate" db:"-"`
+	TopLinks     []CampaignLinkStats `json:"top_links" db:"-"`
+}
+
+// Session is one refresh token family. Only the refresh token whose jti
+// matches RefreshJTI may be redeemed; presenting an older one revokes the session.
+type Session struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     string     `json:"user_id" db:"user_id"`
+	RefreshJTI string     `json:"refresh_jti" db:"refresh_jti"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Active reports whether the session can still be used at time now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
 }
 
 // ============ REQUEST/RESPONSE TYPES ============
@@ -129,25 +439,305 @@ type AuthResponse struct {
 
 // ContactFormRequest for contact submissions
 type ContactFormRequest struct {
-	FirstName  string `json:"first_name" validate:"required"`
-	LastName   string `json:"last_name" validate:"required"`
-	Email      string `json:"email" validate:"required,email"`
-	Phone      string `json:"phone" validate:"required"`
-	Message    string `json:"message" validate:"required,min=10"`
-	PropertyID *string `json:"property_id"`
+	FirstName  string  `json:"first_name" validate:"required"`
+	LastName   string  `json:"last_name" validate:"required"`
+	Email      string  `json:"email" validate:"required,email"`
+	Phone      string  `json:"phone" validate:"required"`
+	Message    string  `json:"message" validate:"required,min=10"`
+	PropertyID *string `json:"property_id" validate:"omitempty,uuid"`
+	// SubscribeNewsletter also signs the sender up for the newsletter
+	SubscribeNewsletter bool `json:"subscribe_newsletter"`
 }
 
 // NewsletterRequest for newsletter signup
 type NewsletterRequest struct {
-	Email string `json:"email" validate:"required,email"`
-	Name  string `json:"name"`
+	Email     string   `json:"email" validate:"required,email"`
+	Name      string   `json:"name" validate:"max=255"`
+	Interests []string `json:"interests" validate:"max=4,unique,dive,oneof=Land Homes Construction Investment"`
+}
+
+// SubscriberRequest for admins labelling a subscriber
+type SubscriberRequest struct {
+	Tags      []string `json:"tags" validate:"max=20,dive,required,max=50"`
+	Interests []string `json:"interests" validate:"max=4,unique,dive,oneof=Land Homes Construction Investment"`
 }
 
 // ReviewRequest for creating reviews
 type ReviewRequest struct {
-	PropertyID string `json:"property_id" validate:"required"`
+	PropertyID string `json:"property_id" validate:"required,uuid"`
 	Rating     int    `json:"rating" validate:"required,min=1,max=5"`
-	Comment    string `json:"comment"`
+	Comment    string `json:"comment" validate:"max=2000"`
+}
+
+// PropertySearchRequest holds the query parameters of GET /properties
+type PropertySearchRequest struct {
+	Status   string   `query:"status" validate:"omitempty,oneof=available sold pending"`
+	Location string   `query:"location" validate:"max=255"`
+	MinPrice *float64 `query:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice *float64 `query:"max_price" validate:"omitempty,gte=0"`
+	MinUnits *int     `query:"min_units" validate:"omitempty,gte=0"`
+	MaxUnits *int     `query:"max_units" validate:"omitempty,gte=0"`
+	MinAcres *float64 `query:"min_acres" validate:"omitempty,gte=0"`
+	MaxAcres *float64 `query:"max_acres" validate:"omitempty,gte=0"`
+	Features string   `query:"features"` // comma-separated
+	Q        string   `query:"q" validate:"max=200"`
+	Near     string   `query:"near"` // lat,lng
+	RadiusKm *float64 `query:"radius_km" validate:"omitempty,gt=0,lte=20000"`
+	BBox     string   `query:"bbox"` // min_lng,min_lat,max_lng,max_lat
+	Sort     string   `query:"sort" validate:"omitempty,oneof=price created_at acres"`
+	Order    string   `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// PlotRequest describes one plot in a bulk create
+type PlotRequest struct {
+	PlotNumber string  `json:"plot_number" validate:"required,max=50"`
+	SizeSqm    float64 `json:"size_sqm" validate:"gt=0"`
+	Price      float64 `json:"price" validate:"gte=0"`
+	IsCorner   bool    `json:"is_corner"`
+}
+
+// BulkCreatePlotsRequest adds plots to a property in one call
+type BulkCreatePlotsRequest struct {
+	Plots []PlotRequest `json:"plots" validate:"required,min=1,max=500,unique=PlotNumber,dive"`
+}
+
+// CreateReservationRequest holds plots of a property for the signed-in buyer
+type CreateReservationRequest struct {
+	PlotIDs []string `json:"plot_ids" validate:"required,min=1,max=20,unique,dive,uuid"`
+}
+
+// ReservationListRequest filters the admin reservation listing
+type ReservationListRequest struct {
+	Status     string `query:"status" validate:"omitempty,oneof=active converted cancelled expired"`
+	PropertyID string `query:"property_id" validate:"omitempty,uuid"`
+}
+
+// PaymentPlanRequest creates or replaces a payment plan
+type PaymentPlanRequest struct {
+	Name           string  `json:"name" validate:"required,max=100"`
+	DepositPercent float64 `json:"deposit_percent" validate:"gte=0,lte=100"`
+	Months         int     `json:"months" validate:"gte=0,lte=120"`
+	MarkupPercent  float64 `json:"markup_percent" validate:"gte=0,lte=500"`
+	IsActive       *bool   `json:"is_active"`
+}
+
+// ScheduleQuoteRequest holds the query parameters of a schedule quote
+type ScheduleQuoteRequest struct {
+	PlotID    string `query:"plot_id" validate:"required,uuid"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ConvertReservationRequest picks the payment plan of a sale. Without one
+// the sale is recorded as an outright purchase.
+type ConvertReservationRequest struct {
+	PaymentPlanID string `json:"payment_plan_id" validate:"omitempty,uuid"`
+}
+
+// PaymentScheduleListRequest filters the admin payment schedule listing
+type PaymentScheduleListRequest struct {
+	UserID     string `query:"user_id" validate:"omitempty,uuid"`
+	PropertyID string `query:"property_id" validate:"omitempty,uuid"`
+}
+
+// RecordPaymentRequest for admins entering a payment received from a buyer
+type RecordPaymentRequest struct {
+	ScheduleID string    `json:"schedule_id" validate:"required,uuid"`
+	Amount     float64   `json:"amount" validate:"gt=0"`
+	Method     string    `json:"method" validate:"required,oneof=bank_transfer card cash cheque"`
+	Reference  string    `json:"reference" validate:"max=100"`
+	ReceivedAt time.Time `json:"received_at" validate:"required"`
+}
+
+// ReversePaymentRequest explains why a payment is reversed
+type ReversePaymentRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// PaymentListRequest filters the admin payment listing
+type PaymentListRequest struct {
+	UserID     string `query:"user_id" validate:"omitempty,uuid"`
+	PropertyID string `query:"property_id" validate:"omitempty,uuid"`
+	ScheduleID string `query:"schedule_id" validate:"omitempty,uuid"`
+}
+
+// DocumentLinkRequest asks for a signed download link to a PDF document
+type DocumentLinkRequest struct {
+	Type string `json:"type" validate:"required,oneof=brochure receipt schedule"`
+	ID   string `json:"id" validate:"required,uuid"`
+}
+
+// LeadListRequest filters the admin lead listing
+type LeadListRequest struct {
+	Source string `query:"source" validate:"omitempty,max=50"`
+	Search string `query:"search" validate:"omitempty,max=255"`
+}
+
+// CampaignRequest creates or edits a newsletter campaign
+type CampaignRequest struct {
+	Subject  string `json:"subject" validate:"required,max=255"`
+	HTMLBody string `json:"html_body" validate:"required"`
+	TextBody string `json:"text_body"`
+	Segment  string `json:"segment" validate:"omitempty,max=100"` // "all" or a segment ID
+}
+
+// SegmentRequest creates or replaces a saved segment
+type SegmentRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	Filter      string `json:"filter" validate:"required,max=2000"`
+}
+
+// ScheduleCampaignRequest sets when a campaign goes out; without send_at it
+// is sent straight away
+type ScheduleCampaignRequest struct {
+	SendAt *time.Time `json:"send_at"`
+}
+
+// PublishBlogPostRequest sets when a post goes live; without publish_at, or
+// with a time that has passed, it is published straight away
+type PublishBlogPostRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// BlogListRequest filters the admin blog listing
+type BlogListRequest struct {
+	Status   string `query:"status" validate:"omitempty,oneof=draft scheduled published"`
+	Category string `query:"category" validate:"omitempty,max=100"`
+}
+
+// RevisionDiffRequest picks the two revisions of a post to compare
+type RevisionDiffRequest struct {
+	From int `query:"from" validate:"required,min=1"`
+	To   int `query:"to" validate:"required,min=1"`
+}
+
+// RevisionDiff is the line-based difference between two revisions of a blog
+// post. Fields lists only the fields that changed; Unified is the same
+// difference in unified diff format.
+type RevisionDiff struct {
+	PostID  string      `json:"post_id"`
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	Fields  []FieldDiff `json:"fields"`
+	Unified string      `json:"unified"`
+}
+
+// FieldDiff is the difference in one field of a blog post
+type FieldDiff struct {
+	Field string     `json:"field"`
+	Hunks []DiffHunk `json:"hunks"`
+}
+
+// DiffHunk is a run of changed lines with up to three unchanged lines of
+// context around it. Starts are 1-based line numbers.
+type DiffHunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is one line of a hunk. Op is equal, delete or insert; deleted
+// lines have no NewLine and inserted lines no OldLine.
+type DiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// CampaignListRequest filters the admin campaign listing
+type CampaignListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=draft scheduled sending sent cancelled failed"`
+}
+
+// CampaignRecipientListRequest filters the recipients of a campaign
+type CampaignRecipientListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending sent failed skipped"`
+}
+
+// UpdatePlotRequest for admins editing a plot
+type UpdatePlotRequest struct {
+	SizeSqm  *float64 `json:"size_sqm" validate:"omitempty,gt=0"`
+	Price    *float64 `json:"price" validate:"omitempty,gte=0"`
+	IsCorner *bool    `json:"is_corner"`
+	Status   *string  `json:"status" validate:"omitempty,oneof=available reserved sold"`
+}
+
+// UpdateProfileRequest for users editing their own profile
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,min=1,max=255"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=1,max=255"`
+}
+
+// UpdateUserRequest for admins editing a user account
+type UpdateUserRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,min=1,max=255"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=1,max=255"`
+	Role      *string `json:"role" validate:"omitempty,oneof=admin user"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+// ReservationConversion is the result of converting a reservation into a sale
+type ReservationConversion struct {
+	Reservation Reservation     `json:"reservation"`
+	Schedule    PaymentSchedule `json:"schedule"`
+}
+
+// Purchase is a buyer's view of one payment schedule and its payments
+type Purchase struct {
+	Schedule      PaymentSchedule `json:"schedule"`
+	PropertyTitle string          `json:"property_title"`
+	PropertySlug  string          `json:"property_slug"`
+	Balance
+	Payments []Payment `json:"payments"`
+}
+
+// Balance is how much of a schedule is paid and what is due next
+type Balance struct {
+	TotalAmount   float64    `json:"total_amount"`
+	AmountPaid    float64    `json:"amount_paid"`
+	Outstanding   float64    `json:"outstanding"`
+	OverdueAmount float64    `json:"overdue_amount"`
+	NextDueDate   *time.Time `json:"next_due_date"`
+	NextDueAmount float64    `json:"next_due_amount"`
+}
+
+// Receipt acknowledges one payment
+type Receipt struct {
+	ReceiptNumber string    `json:"receipt_number"`
+	IssuedAt      time.Time `json:"issued_at"`
+	BuyerName     string    `json:"buyer_name"`
+	BuyerEmail    string    `json:"buyer_email"`
+	PropertyTitle string    `json:"property_title"`
+	PlanName      string    `json:"plan_name"`
+	Payment       Payment   `json:"payment"`
+	// BalanceAfter is what remained outstanding once this payment was counted
+	BalanceAfter float64 `json:"balance_after"`
+}
+
+// BrochureStats counts the brochure requests and downloads of one property
+type BrochureStats struct {
+	PropertyID    string `json:"property_id" db:"property_id"`
+	PropertyTitle string `json:"property_title" db:"property_title"`
+	Requests      int    `json:"requests" db:"requests"`
+	Downloads     int    `json:"downloads" db:"downloads"`
+}
+
+// DocumentLink is a download URL that works without signing in until it expires
+type DocumentLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SlugRedirectResponse tells clients that a slug has moved
+type SlugRedirectResponse struct {
+	Redirect bool   `json:"redirect"`
+	Slug     string `json:"slug"`
+	Location string `json:"location"`
 }
 
 // ErrorResponse for API errors
@@ -155,6 +745,16 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// Fields lists per-field validation failures on 422 responses
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes one request field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
 }
 
 // SuccessResponse for successful API responses
@@ -166,16 +766,18 @@ type SuccessResponse struct {
 
 // DashboardStats for admin dashboard
 type DashboardStats struct {
-	TotalProperties         int       `json:"total_properties"`
-	TotalBlogPosts          int       `json:"total_blog_posts"`
-	TotalContacts           int       `json:"total_contacts"`
-	UnreadContacts          int       `json:"unread_contacts"`
-	NewsletterSubscribers   int       `json:"newsletter_subscribers"`
-	RegisteredUsers         int       `json:"registered_users"`
-	RecentProperties        []Property    `json:"recent_properties,omitempty"`
-	RecentBlogPosts         []BlogPost    `json:"recent_blog_posts,omitempty"`
-	RecentContacts          []ContactSubmission `json:"recent_contacts,omitempty"`
-	LastUpdated             time.Time `json:"last_updated"`
+	TotalProperties       int                 `json:"total_properties"`
+	TotalBlogPosts        int                 `json:"total_blog_posts"`
+	TotalContacts         int                 `json:"total_contacts"`
+	UnreadContacts        int                 `json:"unread_contacts"`
+	NewsletterSubscribers int                 `json:"newsletter_subscribers"`
+	RegisteredUsers       int                 `json:"registered_users"`
+	TotalLeads            int                 `json:"total_leads"`
+	TopBrochures          []BrochureStats     `json:"top_brochures,omitempty"`
+	RecentProperties      []Property          `json:"recent_properties,omitempty"`
+	RecentBlogPosts       []BlogPost          `json:"recent_blog_posts,omitempty"`
+	RecentContacts        []ContactSubmission `json:"recent_contacts,omitempty"`
+	LastUpdated           time.Time           `json:"last_updated"`
 }
 
 // PaginationParams for list endpoints
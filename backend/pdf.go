@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// maxBrochureImageBytes caps the property image downloaded into a brochure
+const maxBrochureImageBytes = 5 << 20
+
+// pdfDocument is an A4 page layout shared by every generated document: the
+// company name and document title at the top, page numbers at the bottom.
+// Text goes through a translator because the core PDF fonts are cp1252.
+type pdfDocument struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+}
+
+func newPDFDocument(title string) *pdfDocument {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	d := &pdfDocument{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor("Haven Communities", true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(21, 94, 239)
+		pdf.CellFormat(0, 6, "HAVEN COMMUNITIES", "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 6, d.tr(title), "", 1, "R", false, 0, "")
+		pdf.Line(20, pdf.GetY()+2, 190, pdf.GetY()+2)
+		pdf.Ln(8)
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	return d
+}
+
+func (d *pdfDocument) heading(text string) {
+	d.pdf.SetFont("Helvetica", "B", 18)
+	d.pdf.MultiCell(0, 9, d.tr(text), "", "L", false)
+	d.pdf.Ln(2)
+}
+
+func (d *pdfDocument) subheading(text string) {
+	d.pdf.Ln(3)
+	d.pdf.SetFont("Helvetica", "B", 12)
+	d.pdf.CellFormat(0, 8, d.tr(text), "", 1, "L", false, 0, "")
+}
+
+func (d *pdfDocument) paragraph(text string) {
+	d.pdf.SetFont("Helvetica", "", 10)
+	d.pdf.MultiCell(0, 5, d.tr(text), "", "L", false)
+	d.pdf.Ln(2)
+}
+
+// fields prints label/value pairs in two columns, skipping empty values
+func (d *pdfDocument) fields(rows [][2]string) {
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		d.pdf.SetFont("Helvetica", "B", 10)
+		d.pdf.CellFormat(50, 6, d.tr(row[0]), "", 0, "L", false, 0, "")
+		d.pdf.SetFont("Helvetica", "", 10)
+		d.pdf.MultiCell(0, 6, d.tr(row[1]), "", "L", false)
+	}
+}
+
+// table prints a header row and bordered rows; numeric columns align right
+func (d *pdfDocument) table(headers []string, widths []float64, rightAligned map[int]bool, rows [][]string) {
+	align := func(i int) string {
+		if rightAligned[i] {
+			return "R"
+		}
+		return "L"
+	}
+	d.pdf.SetFont("Helvetica", "B", 10)
+	d.pdf.SetFillColor(235, 240, 250)
+	for i, h := range headers {
+		d.pdf.CellFormat(widths[i], 7, d.tr(h), "1", 0, align(i), true, 0, "")
+	}
+	d.pdf.Ln(-1)
+	d.pdf.SetFont("Helvetica", "", 10)
+	for _, row := range rows {
+		for i, cell := range row {
+			d.pdf.CellFormat(widths[i], 7, d.tr(cell), "1", 0, align(i), false, 0, "")
+		}
+		d.pdf.Ln(-1)
+	}
+}
+
+// image places a JPEG, PNG or GIF at full content width, keeping its ratio
+func (d *pdfDocument) image(data []byte, imageType string) {
+	opts := fpdf.ImageOptions{ImageType: imageType, ReadDpi: true}
+	info := d.pdf.RegisterImageOptionsReader("image", opts, bytes.NewReader(data))
+	if d.pdf.Err() {
+		// An unreadable image must not fail the whole document
+		d.pdf.ClearError()
+		return
+	}
+	width := 170.0
+	height := width * info.Height() / info.Width()
+	d.pdf.ImageOptions("image", 20, d.pdf.GetY(), width, height, true, opts, 0, "")
+	d.pdf.Ln(4)
+}
+
+func (d *pdfDocument) write(w io.Writer) error {
+	return d.pdf.Output(w)
+}
+
+// RenderBrochure writes a property brochure with its image, features, price
+// and the payment plans on offer
+func RenderBrochure(w io.Writer, property *Property, plans []PaymentPlan, image []byte, imageType string) error {
+	d := newPDFDocument("Property Brochure")
+	d.heading(property.Title)
+	if image != nil {
+		d.image(image, imageType)
+	}
+
+	facts := [][2]string{
+		{"Location", property.Location},
+		{"Price", formatMoney(property.Price)},
+		{"Status", titleCase(property.Status)},
+	}
+	if property.Units > 0 {
+		facts = append(facts, [2]string{"Units available", strconv.Itoa(property.Units)})
+	}
+	if property.Acres > 0 {
+		facts = append(facts, [2]string{"Size", strconv.FormatFloat(property.Acres, 'f', -1, 64) + " acres"})
+	}
+	d.fields(facts)
+
+	if property.Description != "" {
+		d.subheading("About this property")
+		d.paragraph(property.Description)
+	}
+	if len(property.Features) > 0 {
+		d.subheading("Features")
+		for _, feature := range property.Features {
+			d.paragraph("- " + feature)
+		}
+	}
+	if len(plans) > 0 {
+		d.subheading("Payment plans")
+		rows := make([][]string, 0, len(plans))
+		for _, plan := range plans {
+			rows = append(rows, []string{plan.Name, formatPercent(plan.DepositPercent), planDuration(plan.Months), formatPercent(plan.MarkupPercent)})
+		}
+		d.table([]string{"Plan", "Deposit", "Duration", "Markup"}, []float64{70, 30, 40, 30}, map[int]bool{1: true, 3: true}, rows)
+	}
+	return d.write(w)
+}
+
+// RenderReceipt writes the receipt of one payment
+func RenderReceipt(w io.Writer, receipt *Receipt) error {
+	d := newPDFDocument("Payment Receipt")
+	d.heading("Receipt " + receipt.ReceiptNumber)
+	if receipt.Payment.Status == "reversed" {
+		d.pdf.SetTextColor(200, 30, 30)
+		d.paragraph("This payment was reversed and does not count towards the balance.")
+		d.pdf.SetTextColor(0, 0, 0)
+	}
+	d.fields([][2]string{
+		{"Issued", formatDate(receipt.IssuedAt)},
+		{"Received from", receipt.BuyerName},
+		{"Email", receipt.BuyerEmail},
+		{"Property", receipt.PropertyTitle},
+		{"Payment plan", receipt.PlanName},
+	})
+	d.subheading("Payment")
+	d.fields([][2]string{
+		{"Amount", formatMoney(receipt.Payment.Amount)},
+		{"Method", titleCase(strings.ReplaceAll(receipt.Payment.Method, "_", " "))},
+		{"Reference", receipt.Payment.Reference},
+		{"Date received", formatDate(receipt.Payment.ReceivedAt)},
+		{"Balance after payment", formatMoney(receipt.BalanceAfter)},
+	})
+	return d.write(w)
+}
+
+// RenderSchedule writes an installment schedule and how much of it is paid
+func RenderSchedule(w io.Writer, schedule *PaymentSchedule, balance Balance, propertyTitle, buyerName string) error {
+	d := newPDFDocument("Payment Schedule")
+	d.heading(propertyTitle)
+	d.fields([][2]string{
+		{"Buyer", buyerName},
+		{"Payment plan", schedule.PlanName},
+		{"Price", formatMoney(schedule.BasePrice)},
+		{"Markup", formatPercent(schedule.MarkupPercent)},
+		{"Total payable", formatMoney(schedule.TotalAmount)},
+		{"Paid to date", formatMoney(balance.AmountPaid)},
+		{"Outstanding", formatMoney(balance.Outstanding)},
+	})
+
+	d.subheading("Installments")
+	rows := make([][]string, 0, len(schedule.Installments))
+	for _, inst := range schedule.Installments {
+		label := "Installment " + strconv.Itoa(inst.Number)
+		if inst.Number == 0 {
+			label = "Deposit"
+			if schedule.Months == 0 {
+				label = "Full payment"
+			}
+		}
+		rows = append(rows, []string{label, formatDate(inst.DueDate), formatMoney(inst.Amount)})
+	}
+	d.table([]string{"", "Due date", "Amount"}, []float64{60, 50, 60}, map[int]bool{2: true}, rows)
+	return d.write(w)
+}
+
+// fetchImage downloads an image for embedding and reports its fpdf type.
+// Formats fpdf cannot embed are rejected.
+func fetchImage(ctx context.Context, url string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("image request returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBrochureImageBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxBrochureImageBytes {
+		return nil, "", fmt.Errorf("image is larger than %d bytes", maxBrochureImageBytes)
+	}
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return data, "JPG", nil
+	case "image/png":
+		return data, "PNG", nil
+	case "image/gif":
+		return data, "GIF", nil
+	}
+	return nil, "", fmt.Errorf("unsupported image type")
+}
+
+// formatMoney renders an amount with thousands separators and the CURRENCY
+// code, e.g. "USD 1,250,000.00"
+func formatMoney(amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, cents, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, ch := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+
+	currency := os.Getenv("CURRENCY")
+	if currency == "" {
+		currency = "USD"
+	}
+	return currency + " " + sign + b.String() + "." + cents
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 January 2006")
+}
+
+func planDuration(months int) string {
+	switch months {
+	case 0:
+		return "Outright"
+	case 1:
+		return "1 month"
+	}
+	return strconv.Itoa(months) + " months"
+}
+
+// titleCase capitalises the first letter of each word
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SQLSTATE codes raised by the database functions in migrations, mapped by
+// the postgres and supabase stores onto ErrNotFound and ErrConflict
+const (
+	sqlStateNotFound = "HV404"
+	sqlStateConflict = "HV409"
+)
+
+var (
+	// ErrNotFound is returned by repositories when a record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned by repositories when a write violates a unique constraint
+	ErrConflict = errors.New("record already exists")
+)
+
+// Offset returns the number of rows to skip for the current page
+func (p PaginationParams) Offset() int {
+	if p.Page < 1 || p.Limit < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// PropertyFilter narrows and orders a property listing. Nil bounds are unset.
+type PropertyFilter struct {
+	PaginationParams
+	Status   string
+	Location string // case-insensitive substring
+	MinPrice *float64
+	MaxPrice *float64
+	MinUnits *int
+	MaxUnits *int
+	MinAcres *float64
+	MaxAcres *float64
+	Features []string  // every listed feature must be present
+	Query    string    // full-text search over title and description
+	Near     *GeoPoint // with RadiusKm, only properties within that distance
+	RadiusKm float64
+	BBox     *BoundingBox
+	SortBy   string // price, acres or created_at (default)
+	SortAsc  bool
+}
+
+// BlogFilter narrows a blog post listing
+type BlogFilter struct {
+	PaginationParams
+	Category      string
+	Status        string
+	PublishedOnly bool // newest publication first
+}
+
+// ContactFilter narrows a contact submission listing
+type ContactFilter struct {
+	PaginationParams
+	UnreadOnly bool
+}
+
+// NewsletterFilter narrows a subscriber listing
+type NewsletterFilter struct {
+	PaginationParams
+	ActiveOnly bool
+	Status     string
+}
+
+// CampaignFilter narrows a campaign listing
+type CampaignFilter struct {
+	PaginationParams
+	Status  string
+	Segment string
+}
+
+// CampaignRecipientFilter narrows the recipients of a campaign
+type CampaignRecipientFilter struct {
+	PaginationParams
+	CampaignID string
+	Status     string
+}
+
+// LeadFilter narrows a lead listing. Search matches part of the email.
+type LeadFilter struct {
+	PaginationParams
+	Source string
+	Search string
+}
+
+// ReservationFilter narrows a reservation listing
+type ReservationFilter struct {
+	PaginationParams
+	Status     string
+	PropertyID string
+	UserID     string
+}
+
+// PaymentScheduleFilter narrows a payment schedule listing
+type PaymentScheduleFilter struct {
+	PaginationParams
+	UserID     string
+	PropertyID string
+}
+
+// PaymentFilter narrows a payment listing
+type PaymentFilter struct {
+	PaginationParams
+	UserID     string
+	PropertyID string
+	ScheduleID string
+}
+
+// PropertyRepo persists properties
+type PropertyRepo interface {
+	List(ctx context.Context, filter PropertyFilter) ([]Property, int, error)
+	GetByID(ctx context.Context, id string) (*Property, error)
+	GetBySlug(ctx context.Context, slug string) (*Property, error)
+	Create(ctx context.Context, property *Property) error
+	// Save stores new details of a property together with, when redirect is
+	// not nil, the redirect from its old slug. Either both are stored or
+	// neither is.
+	Save(ctx context.Context, property *Property, redirect *SlugRedirect) error
+	// SetInventory stores the unit count and status derived from the plots
+	SetInventory(ctx context.Context, id string, units int, status string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PlotRepo persists the plots of land estates
+type PlotRepo interface {
+	// ListByProperty returns the plots of a property, optionally only those with status
+	ListByProperty(ctx context.Context, propertyID, status string) ([]Plot, error)
+	GetByID(ctx context.Context, id string) (*Plot, error)
+	// CreateBatch inserts all plots or none, returning ErrConflict on a duplicate plot number
+	CreateBatch(ctx context.Context, plots []Plot) error
+	// Update saves a plot's size, price, corner flag and status. It returns
+	// ErrConflict if the status changes while a reservation holds the plot.
+	Update(ctx context.Context, plot *Plot) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ReservationRepo persists plot reservations. Plot statuses change together
+// with the reservation in one atomic step, so two buyers can never hold the
+// same plot.
+type ReservationRepo interface {
+	// Create holds every plot in reservation.PlotIDs, returning ErrConflict
+	// if any of them is not an available plot of reservation.PropertyID
+	Create(ctx context.Context, reservation *Reservation) error
+	GetByID(ctx context.Context, id string) (*Reservation, error)
+	List(ctx context.Context, filter ReservationFilter) ([]Reservation, int, error)
+	// Complete ends an active reservation as converted (plots sold) or as
+	// cancelled or expired (plots released), returning ErrConflict if it is
+	// no longer active
+	Complete(ctx context.Context, id, status string) (*Reservation, error)
+	// Convert completes an active reservation as converted and stores the
+	// buyer's payment schedule in the same step; neither is kept if the
+	// other fails
+	Convert(ctx context.Context, id string, schedule *PaymentSchedule) (*Reservation, error)
+	// ExpireDue ends every active reservation that expired at or before now
+	ExpireDue(ctx context.Context, now time.Time) ([]Reservation, error)
+}
+
+// PaymentPlanRepo persists the payment plans offered for properties
+type PaymentPlanRepo interface {
+	ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]PaymentPlan, error)
+	GetByID(ctx context.Context, id string) (*PaymentPlan, error)
+	Create(ctx context.Context, plan *PaymentPlan) error
+	Update(ctx context.Context, plan *PaymentPlan) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PaymentScheduleRepo persists the schedules of buyers
+type PaymentScheduleRepo interface {
+	List(ctx context.Context, filter PaymentScheduleFilter) ([]PaymentSchedule, int, error)
+	GetByID(ctx context.Context, id string) (*PaymentSchedule, error)
+	Create(ctx context.Context, schedule *PaymentSchedule) error
+}
+
+// PaymentRepo persists the payment ledger
+type PaymentRepo interface {
+	List(ctx context.Context, filter PaymentFilter) ([]Payment, int, error)
+	// ListBySchedule returns every payment of a schedule, oldest first
+	ListBySchedule(ctx context.Context, scheduleID string) ([]Payment, error)
+	GetByID(ctx context.Context, id string) (*Payment, error)
+	// Create stores a payment and assigns its sequential receipt number. It
+	// returns ErrConflict if the payment would take the recorded payments of
+	// its schedule past the schedule total, checked atomically with the insert.
+	Create(ctx context.Context, payment *Payment) error
+	// Reverse marks a recorded payment as reversed, returning ErrConflict
+	// if it already was
+	Reverse(ctx context.Context, id, reason string, at time.Time) (*Payment, error)
+}
+
+// BlogRepo persists blog posts
+type BlogRepo interface {
+	List(ctx context.Context, filter BlogFilter) ([]BlogPost, int, error)
+	GetByID(ctx context.Context, id string) (*BlogPost, error)
+	GetBySlug(ctx context.Context, slug string) (*BlogPost, error)
+	// Create stores a new post and its first revision together, numbering
+	// the revision
+	Create(ctx context.Context, post *BlogPost, revision *BlogRevision) error
+	// Save stores new content for a post together with its next revision
+	// and, when redirect is not nil, the redirect from its old slug. Either
+	// all three are stored or none is.
+	Save(ctx context.Context, post *BlogPost, redirect *SlugRedirect, revision *BlogRevision) error
+	// Update saves the content of a post and its announce flag without a
+	// revision; status only changes through Transition
+	Update(ctx context.Context, post *BlogPost) error
+	Delete(ctx context.Context, id string) error
+	// Transition moves a post in one of the from statuses to status to. at is
+	// the publish time of a scheduled post and the publication time of a
+	// published one. It returns ErrConflict if the post is in another status.
+	Transition(ctx context.Context, id string, from []string, to string, at time.Time) error
+	// Due returns scheduled posts whose publish time is at or before now
+	Due(ctx context.Context, now time.Time) ([]BlogPost, error)
+	// Announce stores campaign and records it as the announcement of a post
+	// in one step, returning ErrConflict if the post already has one
+	Announce(ctx context.Context, id string, campaign *Campaign) error
+}
+
+// BlogRevisionRepo persists the saved versions of blog posts
+type BlogRevisionRepo interface {
+	// List returns the revisions of a post, newest first
+	List(ctx context.Context, postID string, page PaginationParams) ([]BlogRevision, int, error)
+	// Get returns revision number of a post
+	Get(ctx context.Context, postID string, number int) (*BlogRevision, error)
+	// Create stores revision as the post's next number, filling in its
+	// Revision. It returns ErrNotFound if the post does not exist.
+	Create(ctx context.Context, revision *BlogRevision) error
+}
+
+// ContactRepo persists contact form submissions
+type ContactRepo interface {
+	List(ctx context.Context, filter ContactFilter) ([]ContactSubmission, int, error)
+	GetByID(ctx context.Context, id string) (*ContactSubmission, error)
+	Create(ctx context.Context, submission *ContactSubmission) error
+	MarkRead(ctx context.Context, id string) error
+}
+
+// NewsletterRepo persists newsletter subscribers
+type NewsletterRepo interface {
+	List(ctx context.Context, filter NewsletterFilter) ([]NewsletterSubscriber, int, error)
+	GetByEmail(ctx context.Context, email string) (*NewsletterSubscriber, error)
+	Create(ctx context.Context, subscriber *NewsletterSubscriber) error
+	Update(ctx context.Context, subscriber *NewsletterSubscriber) error
+}
+
+// UserRepo persists user accounts
+type UserRepo interface {
+	List(ctx context.Context, page PaginationParams) ([]User, int, error)
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	Create(ctx context.Context, user *User) error
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ReviewRepo persists property reviews
+type ReviewRepo interface {
+	ListByUser(ctx context.Context, userID string) ([]Review, error)
+	ListByProperty(ctx context.Context, propertyID string) ([]Review, error)
+	Create(ctx context.Context, review *Review) error
+}
+
+// FavoriteRepo persists user favorites
+type FavoriteRepo interface {
+	ListByUser(ctx context.Context, userID string) ([]Favorite, error)
+	Add(ctx context.Context, favorite *Favorite) error
+	Remove(ctx context.Context, userID, propertyID string) error
+}
+
+// BrochureRepo persists brochure download requests
+type BrochureRepo interface {
+	Create(ctx context.Context, request *BrochureRequest) error
+	GetByID(ctx context.Context, id string) (*BrochureRequest, error)
+	// RecordDownload counts one download of the brochure sent for a request
+	RecordDownload(ctx context.Context, id string, at time.Time) error
+	// Stats returns request and download counts per property, most
+	// downloaded first. A limit of 0 returns every property.
+	Stats(ctx context.Context, limit int) ([]BrochureStats, error)
+}
+
+// LeadRepo persists leads, one per email address
+type LeadRepo interface {
+	List(ctx context.Context, filter LeadFilter) ([]Lead, int, error)
+	// Capture stores a new lead or, when the email is already known, counts
+	// another request and updates LastSeenAt. lead is replaced by the stored row.
+	Capture(ctx context.Context, lead *Lead) error
+}
+
+// SegmentRepo persists saved subscriber segments; names are unique
+type SegmentRepo interface {
+	List(ctx context.Context, page PaginationParams) ([]Segment, int, error)
+	GetByID(ctx context.Context, id string) (*Segment, error)
+	Create(ctx context.Context, segment *Segment) error
+	Update(ctx context.Context, segment *Segment) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CampaignRepo persists newsletter campaigns and their recipients
+type CampaignRepo interface {
+	List(ctx context.Context, filter CampaignFilter) ([]Campaign, int, error)
+	GetByID(ctx context.Context, id string) (*Campaign, error)
+	Create(ctx context.Context, campaign *Campaign) error
+	// Update saves the subject, bodies and segment; status only changes
+	// through Transition
+	Update(ctx context.Context, campaign *Campaign) error
+	Delete(ctx context.Context, id string) error
+	// Transition moves a campaign in one of the from statuses to status to,
+	// stamping the matching time with at. It returns ErrConflict if the
+	// campaign is in another status.
+	Transition(ctx context.Context, id string, from []string, to string, at time.Time) error
+	// Due returns campaigns that are sending or scheduled at or before now
+	Due(ctx context.Context, now time.Time) ([]Campaign, error)
+	// AddRecipients queues recipients, skipping emails the campaign already has
+	AddRecipients(ctx context.Context, campaignID string, recipients []CampaignRecipient) error
+	// PendingRecipients returns up to limit unsent recipients, fewest attempts first
+	PendingRecipients(ctx context.Context, campaignID string, limit int) ([]CampaignRecipient, error)
+	GetRecipient(ctx context.Context, id string) (*CampaignRecipient, error)
+	UpdateRecipient(ctx context.Context, recipient *CampaignRecipient) error
+	ListRecipients(ctx context.Context, filter CampaignRecipientFilter) ([]CampaignRecipient, int, error)
+	RecordEvent(ctx context.Context, event *CampaignEvent) error
+	// EventStats counts the opens and clicks of a campaign
+	EventStats(ctx context.Context, campaignID string) (*CampaignStats, error)
+	// TopLinks returns up to limit links of a campaign, most clicked first
+	TopLinks(ctx context.Context, campaignID string, limit int) ([]CampaignLinkStats, error)
+}
+
+// SessionRepo persists refresh token families
+type SessionRepo interface {
+	Create(ctx context.Context, session *Session) error
+	GetByID(ctx context.Context, id string) (*Session, error)
+	// Rotate replaces the current refresh jti, returning ErrNotFound unless
+	// oldJTI is still current and the session has not been revoked
+	Rotate(ctx context.Context, id, oldJTI, newJTI string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id string) error
+	RevokeByUser(ctx context.Context, userID string) error
+}
+
+// SlugRedirectRepo persists the slugs entities used to have
+type SlugRedirectRepo interface {
+	// Save records the redirect, repointing an existing one for the same slug
+	Save(ctx context.Context, redirect *SlugRedirect) error
+	Get(ctx context.Context, entityType, slug string) (*SlugRedirect, error)
+}
+
+// Store bundles the repositories the handlers depend on
+type Store struct {
+	Properties    PropertyRepo
+	Plots         PlotRepo
+	Reservations  ReservationRepo
+	PaymentPlans  PaymentPlanRepo
+	Schedules     PaymentScheduleRepo
+	Payments      PaymentRepo
+	Blog          BlogRepo
+	BlogRevisions BlogRevisionRepo
+	Contacts      ContactRepo
+	Newsletter    NewsletterRepo
+	Segments      SegmentRepo
+	Campaigns     CampaignRepo
+	Users         UserRepo
+	Reviews       ReviewRepo
+	Favorites     FavoriteRepo
+	Brochures     BrochureRepo
+	Leads         LeadRepo
+	Sessions      SessionRepo
+	SlugRedirects SlugRedirectRepo
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// outrightPlan is used when a sale is converted without a payment plan
+var outrightPlan = PaymentPlan{Name: "Outright", DepositPercent: 100}
+
+// BuildSchedule splits the price of plots under a plan into a deposit due on
+// start and equal monthly installments due on the same day of each following
+// month. The markup is added to the base price first. Amounts are worked out
+// in minor units so they always add up to the total; the last installment
+// absorbs the rounding.
+func BuildSchedule(plan PaymentPlan, basePrice float64, start time.Time) PaymentSchedule {
+	start = dateOnly(start)
+	total := int64(math.Round(basePrice * (100 + plan.MarkupPercent)))
+
+	var installments []Installment
+	if plan.Months == 0 {
+		installments = append(installments, Installment{Number: 0, DueDate: start, Amount: fromMinor(total)})
+	} else {
+		deposit := int64(math.Round(float64(total) * plan.DepositPercent / 100))
+		if deposit > 0 {
+			installments = append(installments, Installment{Number: 0, DueDate: start, Amount: fromMinor(deposit)})
+		}
+		rest := total - deposit
+		monthly := rest / int64(plan.Months)
+		for i := 1; i <= plan.Months; i++ {
+			amount := monthly
+			if i == plan.Months {
+				amount = rest - monthly*int64(plan.Months-1)
+			}
+			installments = append(installments, Installment{Number: i, DueDate: addMonths(start, i), Amount: fromMinor(amount)})
+		}
+	}
+
+	schedule := PaymentSchedule{
+		PlanName:       plan.Name,
+		BasePrice:      basePrice,
+		DepositPercent: plan.DepositPercent,
+		Months:         plan.Months,
+		MarkupPercent:  plan.MarkupPercent,
+		TotalAmount:    fromMinor(total),
+		Installments:   installments,
+		StartDate:      start,
+	}
+	if plan.Months == 0 {
+		schedule.DepositPercent = 100
+	}
+	if plan.ID != "" {
+		planID := plan.ID
+		schedule.PaymentPlanID = &planID
+		schedule.PropertyID = plan.PropertyID
+	}
+	return schedule
+}
+
+// fromMinor converts an amount in kobo or cents to the main currency unit
+func fromMinor(amount int64) float64 {
+	return float64(amount) / 100
+}
+
+// dateOnly truncates t to midnight UTC of its calendar day
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths moves t forward by n months, clamping to the last day of the
+// month so that 31 January is followed by 28 or 29 February
+func addMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuildScheduleAddsUpToTotal(t *testing.T) {
+	start := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		plan        PaymentPlan
+		basePrice   float64
+		wantTotal   float64
+		wantCount   int
+		wantLast    float64
+		wantEarly   float64 // every installment before the last
+		wantDeposit float64
+	}{
+		{
+			name:      "outright",
+			plan:      PaymentPlan{DepositPercent: 100},
+			basePrice: 1000000, wantTotal: 1000000, wantCount: 1, wantLast: 1000000, wantDeposit: 1000000,
+		},
+		{
+			name:      "even split",
+			plan:      PaymentPlan{DepositPercent: 20, Months: 4},
+			basePrice: 1000000, wantTotal: 1000000, wantCount: 5,
+			wantDeposit: 200000, wantEarly: 200000, wantLast: 200000,
+		},
+		{
+			name:      "last installment takes the remainder",
+			plan:      PaymentPlan{DepositPercent: 0, Months: 3},
+			basePrice: 100, wantTotal: 100, wantCount: 3,
+			wantEarly: 33.33, wantLast: 33.34,
+		},
+		{
+			name:      "markup",
+			plan:      PaymentPlan{DepositPercent: 30, Months: 6, MarkupPercent: 15},
+			basePrice: 2500000.01, wantTotal: 2875000.01, wantCount: 7,
+			wantDeposit: 862500, wantEarly: 335416.66, wantLast: 335416.71,
+		},
+		{
+			name:      "odd kobo",
+			plan:      PaymentPlan{DepositPercent: 12.5, Months: 7, MarkupPercent: 3.3},
+			basePrice: 999999.99, wantTotal: 1032999.99, wantCount: 8,
+			wantDeposit: 129125, wantEarly: 129124.99, wantLast: 129125.05,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := BuildSchedule(tt.plan, tt.basePrice, start)
+			if s.TotalAmount != tt.wantTotal {
+				t.Errorf("TotalAmount = %v, want %v", s.TotalAmount, tt.wantTotal)
+			}
+			if len(s.Installments) != tt.wantCount {
+				t.Fatalf("got %d installments, want %d", len(s.Installments), tt.wantCount)
+			}
+
+			var sum int64
+			for _, in := range s.Installments {
+				sum += int64(math.Round(in.Amount * 100))
+			}
+			if want := int64(math.Round(s.TotalAmount * 100)); sum != want {
+				t.Errorf("installments add up to %d minor units, want %d", sum, want)
+			}
+
+			first := s.Installments[0]
+			if tt.wantDeposit > 0 && (first.Number != 0 || first.Amount != tt.wantDeposit) {
+				t.Errorf("deposit = #%d %v, want #0 %v", first.Number, first.Amount, tt.wantDeposit)
+			}
+			last := s.Installments[len(s.Installments)-1]
+			if last.Amount != tt.wantLast {
+				t.Errorf("last installment = %v, want %v", last.Amount, tt.wantLast)
+			}
+			for _, in := range s.Installments {
+				if in.Number > 0 && in.Number < tt.plan.Months && in.Amount != tt.wantEarly {
+					t.Errorf("installment %d = %v, want %v", in.Number, in.Amount, tt.wantEarly)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildScheduleDueDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		want  []string // deposit first, then each month
+	}{
+		{
+			name:  "mid month",
+			start: time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			want:  []string{"2024-01-15", "2024-02-15", "2024-03-15", "2024-04-15"},
+		},
+		{
+			name:  "31st clamps to shorter months",
+			start: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2023-12-31", "2024-01-31", "2024-02-29", "2024-03-31"},
+		},
+		{
+			name:  "30th in a non-leap year",
+			start: time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2025-01-30", "2025-02-28", "2025-03-30", "2025-04-30"},
+		},
+		{
+			name:  "year boundary",
+			start: time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2024-11-30", "2024-12-30", "2025-01-30", "2025-02-28"},
+		},
+	}
+	plan := PaymentPlan{DepositPercent: 10, Months: 3}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := BuildSchedule(plan, 300000, tt.start)
+			if len(s.Installments) != len(tt.want) {
+				t.Fatalf("got %d installments, want %d", len(s.Installments), len(tt.want))
+			}
+			for i, in := range s.Installments {
+				if got := in.DueDate.Format("2006-01-02"); got != tt.want[i] {
+					t.Errorf("installment %d due %s, want %s", in.Number, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		from string
+		n    int
+		want string
+	}{
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-01-31", 2, "2024-03-31"},
+		{"2024-03-31", 1, "2024-04-30"},
+		{"2024-08-31", 4, "2024-12-31"},
+		{"2024-12-15", 1, "2025-01-15"},
+		{"2024-05-31", 21, "2026-02-28"},
+	}
+	for _, tt := range tests {
+		from, _ := time.Parse("2006-01-02", tt.from)
+		if got := addMonths(from, tt.n).Format("2006-01-02"); got != tt.want {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.from, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+-- ============================================
+-- Haven Communities demo content
+-- ============================================
+-- Optional sample data for local development.
+-- Apply after `go run . migrate up`, e.g. `make db-seed`.
+-- ============================================
+
+INSERT INTO properties (title, slug, description, location, price, status, units, acres, image_url)
+VALUES
+  ('Modern Apartment', 'modern-apartment', 'Beautiful modern apartment in downtown area', 'Downtown', 350000, 'available', 1, 0.25, 'https://via.placeholder.com/400x300?text=Apartment'),
+  ('Luxury Villa', 'luxury-villa', 'Spacious luxury villa with modern amenities', 'Riverside', 850000, 'available', 5, 2.5, 'https://via.placeholder.com/400x300?text=Villa'),
+  ('Family Home', 'family-home', 'Cozy family home perfect for investors', 'Suburban', 450000, 'pending', 3, 1.2, 'https://via.placeholder.com/400x300?text=Home')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO blog_posts (title, slug, excerpt, content_markdown, category, author, published, status, published_at)
+VALUES
+  ('Top Real Estate Trends 2024', 'top-real-estate-trends-2024', 'Discover the latest real estate market trends...', 'Full blog content here...', 'Investment', 'John Doe', true, 'published', NOW()),
+  ('How to Build Your Dream Home', 'how-to-build-dream-home', 'Step-by-step guide to building your perfect home...', 'Full blog content here...', 'Homes', 'Jane Smith', true, 'published', NOW()),
+  ('Investment Opportunities in Land', 'investment-opportunities-land', 'Explore lucrative land investment opportunities...', 'Full blog content here...', 'Land', 'Mike Johnson', true, 'published', NOW())
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// blogCategories are the blog's categories, which subscribers pick their
+// interests from
+var blogCategories = []string{"Land", "Homes", "Construction", "Investment"}
+
+// subscriberSources are the forms a subscriber can sign up through
+var subscriberSources = []string{SubscriberSourceFooter, SubscriberSourceBrochure, SubscriberSourceContact}
+
+// segmentExpr is a parsed segment filter
+type segmentExpr interface {
+	match(s *NewsletterSubscriber) bool
+}
+
+// Segment filter fields
+const (
+	segmentFieldTag      = "tag"
+	segmentFieldSource   = "source"
+	segmentFieldInterest = "interest"
+	segmentFieldFavorite = "favorite"
+)
+
+type (
+	segmentAll  []segmentExpr // every expression matches
+	segmentAny  []segmentExpr // at least one expression matches
+	segmentNot  struct{ expr segmentExpr }
+	segmentTerm struct{ field, value string }
+)
+
+func (e segmentAll) match(s *NewsletterSubscriber) bool {
+	for _, expr := range e {
+		if !expr.match(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e segmentAny) match(s *NewsletterSubscriber) bool {
+	for _, expr := range e {
+		if expr.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e segmentNot) match(s *NewsletterSubscriber) bool {
+	return !e.expr.match(s)
+}
+
+func (e *segmentTerm) match(s *NewsletterSubscriber) bool {
+	switch e.field {
+	case segmentFieldTag:
+		return containsFold(s.Tags, e.value)
+	case segmentFieldSource:
+		return strings.EqualFold(s.Source, e.value)
+	case segmentFieldInterest:
+		return containsFold(s.Interests, e.value)
+	case segmentFieldFavorite:
+		return containsFold(s.FavoritePropertyIDs, e.value)
+	}
+	return false
+}
+
+// containsFold reports whether list holds value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentTerms calls fn for every field:value term of expr
+func segmentTerms(expr segmentExpr, fn func(term *segmentTerm)) {
+	switch e := expr.(type) {
+	case segmentAll:
+		for _, sub := range e {
+			segmentTerms(sub, fn)
+		}
+	case segmentAny:
+		for _, sub := range e {
+			segmentTerms(sub, fn)
+		}
+	case segmentNot:
+		segmentTerms(e.expr, fn)
+	case *segmentTerm:
+		fn(e)
+	}
+}
+
+// segmentToken is a word of a filter; quoted words are never operators
+type segmentToken struct {
+	text   string
+	quoted bool
+	pos    int
+}
+
+// parseSegmentFilter parses a segment filter expression. Terms are
+// field:value pairs, where field is one of
+//
+//	tag       an admin tag
+//	source    footer, brochure or contact
+//	interest  a blog category, e.g. Land
+//	favorite  the ID or slug of a property the subscriber's account favorited
+//
+// Terms combine with AND, OR, NOT and parentheses; terms next to each other
+// must all match. Values with spaces are quoted, and matching ignores case:
+//
+//	interest:Land AND (tag:vip OR favorite:lekki-gardens) NOT source:brochure
+func parseSegmentFilter(filter string) (segmentExpr, error) {
+	tokens, err := lexSegmentFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &segmentParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return expr, nil
+}
+
+// lexSegmentFilter splits a filter into words and parentheses
+func lexSegmentFilter(filter string) ([]segmentToken, error) {
+	var tokens []segmentToken
+	for i := 0; i < len(filter); {
+		switch ch := filter[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, segmentToken{text: string(ch), pos: i})
+			i++
+		default:
+			tok := segmentToken{pos: i}
+			var word strings.Builder
+			for i < len(filter) && !strings.ContainsRune(" \t\n\r()", rune(filter[i])) {
+				if filter[i] != '"' {
+					word.WriteByte(filter[i])
+					i++
+					continue
+				}
+				end := strings.IndexByte(filter[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("unclosed quote at position %d", i+1)
+				}
+				word.WriteString(filter[i+1 : i+1+end])
+				tok.quoted = true
+				i += end + 2
+			}
+			tok.text = word.String()
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+type segmentParser struct {
+	tokens []segmentToken
+	next   int
+}
+
+func (p *segmentParser) peek() (segmentToken, bool) {
+	if p.next >= len(p.tokens) {
+		return segmentToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+// peekOperator reports whether the next token is the keyword op
+func (p *segmentParser) peekOperator(op string) bool {
+	tok, ok := p.peek()
+	return ok && !tok.quoted && strings.EqualFold(tok.text, op)
+}
+
+func (p *segmentParser) parseOr() (segmentExpr, error) {
+	alternatives := segmentAny{}
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, expr)
+		if !p.peekOperator("OR") {
+			break
+		}
+		p.next++
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return alternatives, nil
+}
+
+func (p *segmentParser) parseAnd() (segmentExpr, error) {
+	all := segmentAll{}
+	for {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, expr)
+		if p.peekOperator("AND") {
+			p.next++
+			continue
+		}
+		if tok, ok := p.peek(); !ok || p.peekOperator("OR") || (tok.text == ")" && !tok.quoted) {
+			break
+		}
+	}
+	if len(all) == 1 {
+		return all[0], nil
+	}
+	return all, nil
+}
+
+func (p *segmentParser) parseNot() (segmentExpr, error) {
+	if p.peekOperator("NOT") {
+		p.next++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return segmentNot{expr}, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *segmentParser) parseTerm() (segmentExpr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	p.next++
+
+	if !tok.quoted && tok.text == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.quoted || closing.text != ")" {
+			return nil, fmt.Errorf("missing ) for ( at position %d", tok.pos+1)
+		}
+		p.next++
+		return expr, nil
+	}
+
+	field, value, ok := strings.Cut(tok.text, ":")
+	if !ok {
+		return nil, fmt.Errorf("expected field:value at position %d, got %q", tok.pos+1, tok.text)
+	}
+	field = strings.ToLower(field)
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s at position %d", field, tok.pos+1)
+	}
+	switch field {
+	case segmentFieldTag, segmentFieldFavorite:
+	case segmentFieldSource:
+		if !containsFold(subscriberSources, value) {
+			return nil, fmt.Errorf("unknown source %q at position %d; use %s", value, tok.pos+1, strings.Join(subscriberSources, ", "))
+		}
+	case segmentFieldInterest:
+		if !containsFold(blogCategories, value) {
+			return nil, fmt.Errorf("unknown interest %q at position %d; use %s", value, tok.pos+1, strings.Join(blogCategories, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("unknown field %q at position %d; use tag, source, interest or favorite", field, tok.pos+1)
+	}
+	return &segmentTerm{field: field, value: value}, nil
+}
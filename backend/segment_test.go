@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// formatSegment writes expr in prefix form, e.g. (or tag:a (and tag:b tag:c))
+func formatSegment(expr segmentExpr) string {
+	join := func(op string, exprs []segmentExpr) string {
+		parts := []string{op}
+		for _, e := range exprs {
+			parts = append(parts, formatSegment(e))
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	}
+	switch e := expr.(type) {
+	case segmentAll:
+		return join("and", e)
+	case segmentAny:
+		return join("or", e)
+	case segmentNot:
+		return "(not " + formatSegment(e.expr) + ")"
+	case *segmentTerm:
+		return e.field + ":" + e.value
+	}
+	return "?"
+}
+
+func TestParseSegmentFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"tag:vip", "tag:vip"},
+		{"TAG:vip", "tag:vip"},
+		{"tag:a AND tag:b", "(and tag:a tag:b)"},
+		{"tag:a tag:b", "(and tag:a tag:b)"},
+		{"tag:a OR tag:b", "(or tag:a tag:b)"},
+		{"tag:a or tag:b and tag:c", "(or tag:a (and tag:b tag:c))"},
+		{"tag:a AND tag:b OR tag:c", "(or (and tag:a tag:b) tag:c)"},
+		{"(tag:a OR tag:b) AND tag:c", "(and (or tag:a tag:b) tag:c)"},
+		{"NOT tag:a AND tag:b", "(and (not tag:a) tag:b)"},
+		{"NOT (tag:a OR tag:b)", "(not (or tag:a tag:b))"},
+		{"not not tag:a", "(not (not tag:a))"},
+		{"tag:a NOT tag:b", "(and tag:a (not tag:b))"},
+		{
+			"interest:Land AND (tag:vip OR favorite:lekki-gardens) NOT source:brochure",
+			"(and interest:Land (or tag:vip favorite:lekki-gardens) (not source:brochure))",
+		},
+		{`tag:"big spender"`, "tag:big spender"},
+		{`tag:"OR"`, "tag:OR"},
+		{`"tag:a"`, "tag:a"},
+		{"((tag:a))", "tag:a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expr, err := parseSegmentFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("parseSegmentFilter(%q): %v", tt.filter, err)
+			}
+			if got := formatSegment(expr); got != tt.want {
+				t.Errorf("parseSegmentFilter(%q) = %s, want %s", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSegmentFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"", "unexpected end of filter"},
+		{"tag:a AND", "unexpected end of filter"},
+		{"NOT", "unexpected end of filter"},
+		{"(tag:a", "missing ) for ( at position 1"},
+		{"tag:a)", `unexpected ")" at position 6`},
+		{`tag:"vip`, "unclosed quote at position 5"},
+		{"vip", `expected field:value at position 1, got "vip"`},
+		{"tag:a OR OR", `expected field:value at position 10, got "OR"`},
+		{"tag:", "missing value for tag at position 1"},
+		{"color:red", `unknown field "color" at position 1`},
+		{"source:radio", `unknown source "radio" at position 1`},
+		{"tag:a interest:Gardening", `unknown interest "Gardening" at position 7`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := parseSegmentFilter(tt.filter)
+			if err == nil {
+				t.Fatalf("parseSegmentFilter(%q) succeeded, want error %q", tt.filter, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseSegmentFilter(%q) error = %q, want %q", tt.filter, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentMatch(t *testing.T) {
+	subscriber := &NewsletterSubscriber{
+		Tags:                []string{"VIP", "big spender"},
+		Source:              SubscriberSourceBrochure,
+		Interests:           []string{"Land"},
+		FavoritePropertyIDs: []string{"lekki-gardens"},
+	}
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"tag:vip", true},
+		{`tag:"big spender"`, true},
+		{"tag:new", false},
+		{"source:brochure", true},
+		{"source:footer", false},
+		{"interest:land", true},
+		{"favorite:lekki-gardens", true},
+		{"tag:new OR interest:Land", true},
+		{"tag:vip AND NOT source:brochure", false},
+		{"NOT (tag:new OR source:footer)", true},
+	}
+	for _, tt := range tests {
+		expr, err := parseSegmentFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("parseSegmentFilter(%q): %v", tt.filter, err)
+		}
+		if got := expr.match(subscriber); got != tt.want {
+			t.Errorf("%q matched %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestExportSegmentEscapesFormulas(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+	if err := h.store.Newsletter.Create(context.Background(), &NewsletterSubscriber{
+		ID:        uuid.New().String(),
+		Email:     "=1+1@example.com",
+		Name:      "\t=HYPERLINK(\"https://example.com\")",
+		Source:    "+footer",
+		Tags:      []string{"-vip", "vip"},
+		Interests: []string{"@SUM(A1)"},
+		Status:    SubscriberActive,
+		Active:    true,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var segment Segment
+	if status := call(t, app, "POST", "/api/v1/admin/newsletter/segments", token, SegmentRequest{Name: "VIP", Filter: "tag:vip"}, &segment); status != fiber.StatusCreated {
+		t.Fatalf("create segment status = %d, want 201", status)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/newsletter/segments/"+segment.ID+"/export", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	rows, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("export: %d rows, err %v\n%s", len(rows), err, body)
+	}
+	for i, column := range rows[0][:5] {
+		if cell := rows[1][i]; !strings.HasPrefix(cell, "'") {
+			t.Errorf("%s = %q, want it escaped with a quote", column, cell)
+		}
+	}
+}
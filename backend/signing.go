@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signingKeyFromEnv returns SIGNING_SECRET, falling back to JWT_SECRET so a
+// single secret is enough for small deployments. An empty key would let
+// anyone forge links and tokens, so one of them must be set.
+func signingKeyFromEnv() ([]byte, error) {
+	return secretFromEnv("SIGNING_SECRET", "JWT_SECRET")
+}
+
+// publicURLFromEnv returns the externally reachable base URL of the API
+func publicURLFromEnv() string {
+	if base := os.Getenv("API_PUBLIC_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8101"
+	}
+	return "http://localhost:" + port
+}
+
+// sign returns the URL-safe HMAC-SHA256 of message
+func sign(key []byte, message string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature reports whether signature was produced by sign for message
+func validSignature(key []byte, message, signature string) bool {
+	expected := sign(key, message)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// signedURL returns an absolute API URL for path that stops working at expires.
+// path is relative to /api/v1, e.g. /documents/receipt/<id>.
+func (h *Handler) signedURL(path string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{
+		"expires":   {exp},
+		"signature": {sign(h.signingKey, path+"\n"+exp)},
+	}
+	return h.publicURL + "/api/v1" + path + "?" + query.Encode()
+}
+
+// verifySignedURL checks the expires and signature query values of a signed path
+func (h *Handler) verifySignedURL(path, expires, signature string, now time.Time) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return false
+	}
+	return validSignature(h.signingKey, path+"\n"+expires, signature)
+}
+
+// signToken returns an opaque URL-safe token carrying value for purpose.
+// A zero expires produces a token that never expires.
+func (h *Handler) signToken(purpose, value string, expires time.Time) string {
+	exp := "0"
+	if !expires.IsZero() {
+		exp = strconv.FormatInt(expires.Unix(), 10)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return encoded + "." + exp + "." + sign(h.signingKey, purpose+"\n"+value+"\n"+exp)
+}
+
+// parseToken verifies a token made by signToken for purpose and returns its value
+func (h *Handler) parseToken(purpose, token string, now time.Time) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || (exp != 0 && now.Unix() > exp) {
+		return "", false
+	}
+	if !validSignature(h.signingKey, purpose+"\n"+string(value)+"\n"+parts[1], parts[2]) {
+		return "", false
+	}
+	return string(value), true
+}
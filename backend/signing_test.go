@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// tamper changes the first character of a signature to a different one
+func tamper(signature string) string {
+	replacement := "A"
+	if signature[0] == 'A' {
+		replacement = "B"
+	}
+	return replacement + signature[1:]
+}
+
+func TestSigningKeyFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		signing string
+		jwt     string
+		want    string
+		wantErr bool
+	}{
+		{"signing secret", "sign", "jwt", "sign", false},
+		{"falls back to the JWT secret", "", "jwt", "jwt", false},
+		{"neither set", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SIGNING_SECRET", tt.signing)
+			t.Setenv("JWT_SECRET", tt.jwt)
+			key, err := signingKeyFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if string(key) != tt.want {
+				t.Errorf("key = %q, want %q", key, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignedURL(t *testing.T) {
+	h := &Handler{signingKey: []byte("test-key"), publicURL: "https://api.example.com"}
+	now := time.Unix(1700000000, 0)
+	path := "/documents/receipt/abc"
+
+	signed, err := url.Parse(h.signedURL(path, now.Add(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Path != "/api/v1"+path {
+		t.Fatalf("signed path = %q", signed.Path)
+	}
+	expires := signed.Query().Get("expires")
+	signature := signed.Query().Get("signature")
+
+	tests := []struct {
+		name      string
+		key       string
+		path      string
+		expires   string
+		signature string
+		now       time.Time
+		want      bool
+	}{
+		{"valid", "test-key", path, expires, signature, now, true},
+		{"at expiry", "test-key", path, expires, signature, now.Add(time.Hour), true},
+		{"expired", "test-key", path, expires, signature, now.Add(time.Hour + time.Second), false},
+		{"other path", "test-key", "/documents/receipt/abd", expires, signature, now, false},
+		{"extended expiry", "test-key", path, "1800000000", signature, now, false},
+		{"malformed expiry", "test-key", path, "soon", signature, now, false},
+		{"tampered signature", "test-key", path, expires, tamper(signature), now, false},
+		{"missing signature", "test-key", path, expires, "", now, false},
+		{"other key", "other-key", path, expires, signature, now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &Handler{signingKey: []byte(tt.key)}
+			if got := verifier.verifySignedURL(tt.path, tt.expires, tt.signature, tt.now); got != tt.want {
+				t.Errorf("verifySignedURL = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentLinks(t *testing.T) {
+	app, h := newTestApp(t)
+	ctx := context.Background()
+	buyer := createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	createUser(t, h, "other@example.com", "other-pass", "user", true)
+
+	schedule := testSchedule(time.Now())
+	schedule.UserID = buyer.ID
+	if err := h.store.Schedules.Create(ctx, &schedule); err != nil {
+		t.Fatal(err)
+	}
+	payment := Payment{
+		ID: uuid.New().String(), ScheduleID: schedule.ID, UserID: buyer.ID, PropertyID: schedule.PropertyID,
+		Amount: 500, Method: "cash", ReceivedAt: time.Now(), Status: "recorded", CreatedAt: time.Now(),
+	}
+	if err := h.store.Payments.Create(ctx, &payment); err != nil {
+		t.Fatal(err)
+	}
+
+	request := DocumentLinkRequest{Type: documentReceipt, ID: payment.ID}
+	other := loginAs(t, app, "other@example.com", "other-pass")
+	if status := call(t, app, "POST", "/api/v1/me/document-links", other.AccessToken, request, nil); status != fiber.StatusNotFound {
+		t.Errorf("link to another buyer's receipt: status %d, want 404", status)
+	}
+
+	var link DocumentLink
+	owner := loginAs(t, app, "buyer@example.com", "buyer-pass")
+	if status := call(t, app, "POST", "/api/v1/me/document-links", owner.AccessToken, request, &link); status != fiber.StatusCreated {
+		t.Fatalf("create link status = %d, want 201", status)
+	}
+	signed, err := url.Parse(link.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(query url.Values) (int, string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", signed.Path+"?"+query.Encode(), nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType)
+	}
+
+	query := signed.Query()
+	if status, contentType := get(query); status != fiber.StatusOK || contentType != "application/pdf" {
+		t.Errorf("signed download: status %d, %s", status, contentType)
+	}
+	query.Set("signature", tamper(query.Get("signature")))
+	if status, _ := get(query); status != fiber.StatusForbidden {
+		t.Errorf("tampered link: status %d, want 403", status)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	h := &Handler{signingKey: []byte("test-key")}
+	now := time.Unix(1700000000, 0)
+	expiring := h.signToken("confirm", "a@example.com", now.Add(time.Hour))
+	permanent := h.signToken("unsubscribe", "a@example.com", time.Time{})
+	parts := strings.Split(expiring, ".")
+
+	tests := []struct {
+		name    string
+		purpose string
+		token   string
+		now     time.Time
+		want    string
+		ok      bool
+	}{
+		{"valid", "confirm", expiring, now, "a@example.com", true},
+		{"expired", "confirm", expiring, now.Add(2 * time.Hour), "", false},
+		{"never expires", "unsubscribe", permanent, now.Add(10 * 365 * 24 * time.Hour), "a@example.com", true},
+		{"other purpose", "unsubscribe", expiring, now, "", false},
+		{"other value", "confirm", "Yi5jb20." + parts[1] + "." + parts[2], now, "", false},
+		{"extended expiry", "confirm", parts[0] + ".0." + parts[2], now, "", false},
+		{"tampered signature", "confirm", parts[0] + "." + parts[1] + ".AAAA", now, "", false},
+		{"bad encoding", "confirm", "!!." + parts[1] + "." + parts[2], now, "", false},
+		{"too few parts", "confirm", parts[0] + "." + parts[1], now, "", false},
+		{"empty", "confirm", "", now, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := h.parseToken(tt.purpose, tt.token, tt.now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseToken = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
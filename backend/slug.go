@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	slugEntityProperty = "property"
+	slugEntityBlogPost = "blog_post"
+
+	// maxSlugLength leaves room for a numeric suffix within VARCHAR(255)
+	maxSlugLength = 80
+)
+
+// slugReplacements transliterates letters that do not decompose into
+// an ASCII base letter plus combining marks
+var slugReplacements = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ł': "l", 'ħ': "h", 'ı': "i", '&': " and ",
+}
+
+// Slugify turns a title into a lowercase, hyphen-separated ASCII slug,
+// e.g. "Lekki Gardens Phase Ⅱ — Ọ̀gbà" becomes "lekki-gardens-phase-ii-ogba"
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if s, ok := slugReplacements[r]; ok {
+			for _, sr := range s {
+				dash = writeSlugRune(&b, sr, dash)
+			}
+			continue
+		}
+		dash = writeSlugRune(&b, r, dash)
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// writeSlugRune appends ASCII letters and digits, collapsing anything else
+// into a single hyphen. It returns whether the builder now ends in a hyphen.
+func writeSlugRune(b *strings.Builder, r rune, dash bool) bool {
+	if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+		b.WriteRune(r)
+		return false
+	}
+	if !dash && b.Len() > 0 {
+		b.WriteByte('-')
+	}
+	return true
+}
+
+// assignSlug builds a slug from title that no other record of the entity
+// type uses, live or as a redirect, appending -2, -3, ... as needed
+func (h *Handler) assignSlug(ctx context.Context, entityType, selfID, title string) (string, error) {
+	base := Slugify(title)
+	if base == "" {
+		base = strings.ReplaceAll(entityType, "_", "-")
+	}
+
+	for n := 1; ; n++ {
+		slug := base
+		if n > 1 {
+			slug = base + "-" + strconv.Itoa(n)
+		}
+		ownerID, err := h.slugOwner(ctx, entityType, slug)
+		if errors.Is(err, ErrNotFound) || (err == nil && ownerID == selfID) {
+			return slug, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// slugOwner returns the ID of the record that holds slug, either as its
+// current slug or through a redirect
+func (h *Handler) slugOwner(ctx context.Context, entityType, slug string) (string, error) {
+	var ownerID string
+	var err error
+	switch entityType {
+	case slugEntityProperty:
+		var p *Property
+		if p, err = h.store.Properties.GetBySlug(ctx, slug); err == nil {
+			ownerID = p.ID
+		}
+	case slugEntityBlogPost:
+		var post *BlogPost
+		if post, err = h.store.Blog.GetBySlug(ctx, slug); err == nil {
+			ownerID = post.ID
+		}
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return ownerID, err
+	}
+
+	redirect, err := h.store.SlugRedirects.Get(ctx, entityType, slug)
+	if err != nil {
+		return "", err
+	}
+	return redirect.EntityID, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Lekki Gardens Phase Ⅱ — Ọ̀gbà", "lekki-gardens-phase-ii-ogba"},
+		{"Crème brûlée", "creme-brulee"},
+		{"Straße & Smørrebrød", "strasse-and-smorrebrod"},
+		{"Łódź", "lodz"},
+		{"3 Bedroom, 2 Bath!!", "3-bedroom-2-bath"},
+		{"a---b___c", "a-b-c"},
+		{"!!!", ""},
+		{"", ""},
+		{strings.Repeat("ab ", 40), strings.Repeat("ab-", 26) + "ab"},
+		{strings.Repeat("x", 79) + " y", strings.Repeat("x", 79)},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+		if got := Slugify(tt.title); len(got) > maxSlugLength {
+			t.Errorf("Slugify(%q) is %d bytes, longer than %d", tt.title, len(got), maxSlugLength)
+		}
+	}
+}
+
+func TestAssignSlug(t *testing.T) {
+	ctx := context.Background()
+	h := &Handler{store: NewMemoryStore()}
+	now := time.Now()
+	for _, p := range []Property{
+		{ID: "p1", Title: "Sunset Villas", Slug: "sunset-villas", CreatedAt: now, UpdatedAt: now},
+		{ID: "p2", Title: "Sunset Villas", Slug: "sunset-villas-2", CreatedAt: now, UpdatedAt: now},
+	} {
+		p := p
+		if err := h.store.Properties.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.store.SlugRedirects.Save(ctx, &SlugRedirect{
+		ID: "r1", EntityType: slugEntityProperty, OldSlug: "old-park", EntityID: "p1", CreatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		entityType string
+		selfID     string
+		title      string
+		want       string
+	}{
+		{"unused", slugEntityProperty, "new", "Palm Court", "palm-court"},
+		{"taken twice", slugEntityProperty, "new", "Sunset Villas", "sunset-villas-3"},
+		{"own slug", slugEntityProperty, "p1", "Sunset Villas", "sunset-villas"},
+		{"held by a redirect", slugEntityProperty, "new", "Old Park", "old-park-2"},
+		{"own redirect", slugEntityProperty, "p1", "Old Park", "old-park"},
+		{"other entity type", slugEntityBlogPost, "new", "Sunset Villas", "sunset-villas"},
+		{"no letters", slugEntityBlogPost, "new", "???", "blog-post"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.assignSlug(ctx, tt.entityType, tt.selfID, tt.title)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("assignSlug(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertySlugRedirect(t *testing.T) {
+	app, h := newTestApp(t)
+	token := adminToken(t, app, h)
+
+	var property Property
+	if status := call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Palm Court"}, &property); status != fiber.StatusCreated {
+		t.Fatalf("create status = %d, want 201", status)
+	}
+	if property.Slug != "palm-court" {
+		t.Fatalf("slug = %q, want palm-court", property.Slug)
+	}
+
+	property.Title = "Palm Court West"
+	if status := call(t, app, "PUT", "/api/v1/admin/properties/"+property.ID, token, property, &property); status != fiber.StatusOK {
+		t.Fatalf("rename status = %d, want 200", status)
+	}
+	if property.Slug != "palm-court-west" {
+		t.Fatalf("renamed slug = %q, want palm-court-west", property.Slug)
+	}
+
+	var redirect SlugRedirectResponse
+	if status := call(t, app, "GET", "/api/v1/properties/slug/palm-court", "", nil, &redirect); status != fiber.StatusMovedPermanently {
+		t.Fatalf("old slug status = %d, want 301", status)
+	}
+	if redirect.Slug != "palm-court-west" || redirect.Location != "/api/v1/properties/slug/palm-court-west" {
+		t.Errorf("old slug redirects to %q (%s)", redirect.Slug, redirect.Location)
+	}
+
+	// The old slug stays reserved for the renamed property
+	var other Property
+	call(t, app, "POST", "/api/v1/admin/properties", token, Property{Title: "Palm Court"}, &other)
+	if other.Slug != "palm-court-2" {
+		t.Errorf("a new property took slug %q, want palm-court-2", other.Slug)
+	}
+
+	if status := call(t, app, "GET", "/api/v1/properties/slug/no-such-place", "", nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("unknown slug status = %d, want 404", status)
+	}
+}
@@ -0,0 +1,1895 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewMemoryStore returns a Store backed by thread-safe in-memory maps.
+// It is used for local development and tests that run without Supabase.
+func NewMemoryStore() *Store {
+	redirects := &memorySlugRedirectRepo{items: map[string]SlugRedirect{}}
+	properties := &memoryPropertyRepo{items: map[string]Property{}, redirects: redirects}
+	plots := &memoryPlotRepo{items: map[string]Plot{}}
+	schedules := &memoryScheduleRepo{items: map[string]PaymentSchedule{}}
+	users := &memoryUserRepo{items: map[string]User{}}
+	favorites := &memoryFavoriteRepo{items: map[string]Favorite{}}
+	campaigns := &memoryCampaignRepo{
+		items:      map[string]Campaign{},
+		recipients: map[string][]CampaignRecipient{},
+		events:     map[string][]CampaignEvent{},
+	}
+	blog := &memoryBlogRepo{items: map[string]BlogPost{}, redirects: redirects, campaigns: campaigns}
+	blog.revisions = &memoryBlogRevisionRepo{items: map[string][]BlogRevision{}, posts: blog}
+	return &Store{
+		Properties:    properties,
+		Plots:         plots,
+		Reservations:  &memoryReservationRepo{items: map[string]Reservation{}, plots: plots, schedules: schedules},
+		PaymentPlans:  &memoryPaymentPlanRepo{items: map[string]PaymentPlan{}},
+		Schedules:     schedules,
+		Payments:      &memoryPaymentRepo{items: map[string]Payment{}, schedules: schedules},
+		Blog:          blog,
+		BlogRevisions: blog.revisions,
+		Contacts:      &memoryContactRepo{items: map[string]ContactSubmission{}},
+		Newsletter:    &memoryNewsletterRepo{items: map[string]NewsletterSubscriber{}, users: users, favorites: favorites},
+		Segments:      &memorySegmentRepo{items: map[string]Segment{}},
+		Campaigns:     campaigns,
+		Users:         users,
+		Reviews:       &memoryReviewRepo{items: map[string]Review{}},
+		Favorites:     favorites,
+		Brochures:     &memoryBrochureRepo{items: map[string]BrochureRequest{}, properties: properties},
+		Leads:         &memoryLeadRepo{items: map[string]Lead{}},
+		Sessions:      &memorySessionRepo{items: map[string]Session{}},
+		SlugRedirects: redirects,
+	}
+}
+
+// paginate returns the slice of items for the requested page
+func paginate[T any](items []T, page PaginationParams) []T {
+	offset := page.Offset()
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if page.Limit > 0 && page.Limit < len(items) {
+		items = items[:page.Limit]
+	}
+	return items
+}
+
+// ============ PROPERTIES ============
+
+type memoryPropertyRepo struct {
+	mu        sync.RWMutex
+	items     map[string]Property
+	redirects *memorySlugRedirectRepo
+}
+
+func (r *memoryPropertyRepo) List(ctx context.Context, filter PropertyFilter) ([]Property, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	properties := make([]Property, 0, len(r.items))
+	for _, p := range r.items {
+		if propertyMatches(p, filter) {
+			properties = append(properties, p)
+		}
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		a, b := properties[i], properties[j]
+		if !filter.SortAsc {
+			a, b = b, a
+		}
+		switch filter.SortBy {
+		case "price":
+			return a.Price < b.Price
+		case "acres":
+			return a.Acres < b.Acres
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return paginate(properties, filter.PaginationParams), len(properties), nil
+}
+
+// propertyMatches applies a PropertyFilter to a single property. The text
+// query matches when every word appears in the title or description.
+func propertyMatches(p Property, filter PropertyFilter) bool {
+	switch {
+	case filter.Status != "" && p.Status != filter.Status,
+		filter.Location != "" && !strings.Contains(strings.ToLower(p.Location), strings.ToLower(filter.Location)),
+		filter.MinPrice != nil && p.Price < *filter.MinPrice,
+		filter.MaxPrice != nil && p.Price > *filter.MaxPrice,
+		filter.MinUnits != nil && p.Units < *filter.MinUnits,
+		filter.MaxUnits != nil && p.Units > *filter.MaxUnits,
+		filter.MinAcres != nil && p.Acres < *filter.MinAcres,
+		filter.MaxAcres != nil && p.Acres > *filter.MaxAcres:
+		return false
+	}
+
+	for _, want := range filter.Features {
+		found := false
+		for _, feature := range p.Features {
+			if strings.EqualFold(feature, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if filter.Near != nil || filter.BBox != nil {
+		if p.Latitude == nil || p.Longitude == nil {
+			return false
+		}
+		if filter.Near != nil && HaversineKm(*filter.Near, GeoPoint{Lat: *p.Latitude, Lng: *p.Longitude}) > filter.RadiusKm {
+			return false
+		}
+		if filter.BBox != nil && !filter.BBox.Contains(*p.Latitude, *p.Longitude) {
+			return false
+		}
+	}
+
+	text := strings.ToLower(p.Title + " " + p.Description)
+	for _, word := range strings.Fields(strings.ToLower(filter.Query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryPropertyRepo) GetByID(ctx context.Context, id string) (*Property, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPropertyRepo) GetBySlug(ctx context.Context, slug string) (*Property, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.items {
+		if p.Slug == slug {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPropertyRepo) Create(ctx context.Context, property *Property) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[property.ID]; ok {
+		return ErrConflict
+	}
+	for _, p := range r.items {
+		if p.Slug == property.Slug {
+			return ErrConflict
+		}
+	}
+	r.items[property.ID] = *property
+	return nil
+}
+
+// Save stores the redirect while holding the property lock; saving a
+// memory redirect cannot fail, so nothing needs undoing
+func (r *memoryPropertyRepo) Save(ctx context.Context, property *Property, redirect *SlugRedirect) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[property.ID]; !ok {
+		return ErrNotFound
+	}
+	for _, p := range r.items {
+		if p.ID != property.ID && p.Slug == property.Slug {
+			return ErrConflict
+		}
+	}
+	if redirect != nil {
+		if err := r.redirects.Save(ctx, redirect); err != nil {
+			return err
+		}
+	}
+	r.items[property.ID] = *property
+	return nil
+}
+
+func (r *memoryPropertyRepo) SetInventory(ctx context.Context, id string, units int, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	p.Units = units
+	p.Status = status
+	p.UpdatedAt = time.Now()
+	r.items[id] = p
+	return nil
+}
+
+func (r *memoryPropertyRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ============ PLOTS ============
+
+type memoryPlotRepo struct {
+	mu    sync.RWMutex
+	items map[string]Plot
+}
+
+func (r *memoryPlotRepo) ListByProperty(ctx context.Context, propertyID, status string) ([]Plot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plots := []Plot{}
+	for _, p := range r.items {
+		if p.PropertyID == propertyID && (status == "" || p.Status == status) {
+			plots = append(plots, p)
+		}
+	}
+	return plots, nil
+}
+
+func (r *memoryPlotRepo) GetByID(ctx context.Context, id string) (*Plot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPlotRepo) CreateBatch(ctx context.Context, plots []Plot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := map[string]bool{}
+	for _, p := range r.items {
+		taken[p.PropertyID+"/"+p.PlotNumber] = true
+	}
+	for _, p := range plots {
+		key := p.PropertyID + "/" + p.PlotNumber
+		if _, ok := r.items[p.ID]; ok || taken[key] {
+			return ErrConflict
+		}
+		taken[key] = true
+	}
+	for _, p := range plots {
+		r.items[p.ID] = p
+	}
+	return nil
+}
+
+func (r *memoryPlotRepo) Update(ctx context.Context, plot *Plot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.items[plot.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if existing.ReservationID != nil && plot.Status != existing.Status {
+		return ErrConflict
+	}
+	saved := *plot
+	saved.ReservationID = existing.ReservationID
+	r.items[plot.ID] = saved
+	return nil
+}
+
+func (r *memoryPlotRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ============ RESERVATIONS ============
+
+// memoryReservationRepo shares the plot repo so that holding plots and
+// recording the reservation happen under the plot lock
+type memoryReservationRepo struct {
+	mu        sync.RWMutex
+	items     map[string]Reservation
+	plots     *memoryPlotRepo
+	schedules *memoryScheduleRepo
+}
+
+func (r *memoryReservationRepo) Create(ctx context.Context, reservation *Reservation) error {
+	r.plots.mu.Lock()
+	defer r.plots.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[reservation.ID]; ok {
+		return ErrConflict
+	}
+	for _, id := range reservation.PlotIDs {
+		p, ok := r.plots.items[id]
+		if !ok || p.PropertyID != reservation.PropertyID || p.Status != "available" {
+			return ErrConflict
+		}
+	}
+	reservationID := reservation.ID
+	for _, id := range reservation.PlotIDs {
+		p := r.plots.items[id]
+		p.Status = "reserved"
+		p.ReservationID = &reservationID
+		p.UpdatedAt = reservation.CreatedAt
+		r.plots.items[id] = p
+	}
+	r.items[reservation.ID] = *reservation
+	return nil
+}
+
+func (r *memoryReservationRepo) GetByID(ctx context.Context, id string) (*Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &res, nil
+}
+
+func (r *memoryReservationRepo) List(ctx context.Context, filter ReservationFilter) ([]Reservation, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservations := []Reservation{}
+	for _, res := range r.items {
+		if (filter.Status == "" || res.Status == filter.Status) &&
+			(filter.PropertyID == "" || res.PropertyID == filter.PropertyID) &&
+			(filter.UserID == "" || res.UserID == filter.UserID) {
+			reservations = append(reservations, res)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].CreatedAt.After(reservations[j].CreatedAt)
+	})
+	return paginate(reservations, filter.PaginationParams), len(reservations), nil
+}
+
+func (r *memoryReservationRepo) Complete(ctx context.Context, id, status string) (*Reservation, error) {
+	r.plots.mu.Lock()
+	defer r.plots.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if res.Status != "active" {
+		return nil, ErrConflict
+	}
+	r.complete(&res, status, time.Now())
+	return &res, nil
+}
+
+// Convert stores the schedule first and removes it again when the
+// reservation cannot be converted
+func (r *memoryReservationRepo) Convert(ctx context.Context, id string, schedule *PaymentSchedule) (*Reservation, error) {
+	if err := r.schedules.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+	converted, err := r.Complete(ctx, id, "converted")
+	if err != nil {
+		r.schedules.mu.Lock()
+		delete(r.schedules.items, schedule.ID)
+		r.schedules.mu.Unlock()
+		return nil, err
+	}
+	return converted, nil
+}
+
+func (r *memoryReservationRepo) ExpireDue(ctx context.Context, now time.Time) ([]Reservation, error) {
+	r.plots.mu.Lock()
+	defer r.plots.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := []Reservation{}
+	for _, res := range r.items {
+		if res.Status == "active" && !res.ExpiresAt.After(now) {
+			r.complete(&res, "expired", now)
+			expired = append(expired, res)
+		}
+	}
+	return expired, nil
+}
+
+// complete moves res and its plots to the final status; callers hold both locks
+func (r *memoryReservationRepo) complete(res *Reservation, status string, now time.Time) {
+	for id, p := range r.plots.items {
+		if p.ReservationID == nil || *p.ReservationID != res.ID {
+			continue
+		}
+		if status == "converted" {
+			p.Status = "sold"
+		} else {
+			p.Status = "available"
+			p.ReservationID = nil
+		}
+		p.UpdatedAt = now
+		r.plots.items[id] = p
+	}
+	res.Status = status
+	res.UpdatedAt = now
+	r.items[res.ID] = *res
+}
+
+// ============ PAYMENT PLANS ============
+
+type memoryPaymentPlanRepo struct {
+	mu    sync.RWMutex
+	items map[string]PaymentPlan
+}
+
+func (r *memoryPaymentPlanRepo) ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]PaymentPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plans := []PaymentPlan{}
+	for _, p := range r.items {
+		if p.PropertyID == propertyID && (!activeOnly || p.IsActive) {
+			plans = append(plans, p)
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if plans[i].Months != plans[j].Months {
+			return plans[i].Months < plans[j].Months
+		}
+		return plans[i].CreatedAt.Before(plans[j].CreatedAt)
+	})
+	return plans, nil
+}
+
+func (r *memoryPaymentPlanRepo) GetByID(ctx context.Context, id string) (*PaymentPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPaymentPlanRepo) Create(ctx context.Context, plan *PaymentPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[plan.ID]; ok {
+		return ErrConflict
+	}
+	r.items[plan.ID] = *plan
+	return nil
+}
+
+func (r *memoryPaymentPlanRepo) Update(ctx context.Context, plan *PaymentPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[plan.ID]; !ok {
+		return ErrNotFound
+	}
+	r.items[plan.ID] = *plan
+	return nil
+}
+
+func (r *memoryPaymentPlanRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ============ PAYMENT SCHEDULES ============
+
+type memoryScheduleRepo struct {
+	mu    sync.RWMutex
+	items map[string]PaymentSchedule
+}
+
+func (r *memoryScheduleRepo) List(ctx context.Context, filter PaymentScheduleFilter) ([]PaymentSchedule, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := []PaymentSchedule{}
+	for _, s := range r.items {
+		if (filter.UserID == "" || s.UserID == filter.UserID) &&
+			(filter.PropertyID == "" || s.PropertyID == filter.PropertyID) {
+			schedules = append(schedules, s)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.After(schedules[j].CreatedAt)
+	})
+	return paginate(schedules, filter.PaginationParams), len(schedules), nil
+}
+
+func (r *memoryScheduleRepo) GetByID(ctx context.Context, id string) (*PaymentSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memoryScheduleRepo) Create(ctx context.Context, schedule *PaymentSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[schedule.ID]; ok {
+		return ErrConflict
+	}
+	r.items[schedule.ID] = *schedule
+	return nil
+}
+
+// ============ PAYMENTS ============
+
+type memoryPaymentRepo struct {
+	mu        sync.RWMutex
+	items     map[string]Payment
+	receipts  int64
+	schedules *memoryScheduleRepo
+}
+
+func (r *memoryPaymentRepo) List(ctx context.Context, filter PaymentFilter) ([]Payment, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payments := []Payment{}
+	for _, p := range r.items {
+		if (filter.UserID == "" || p.UserID == filter.UserID) &&
+			(filter.PropertyID == "" || p.PropertyID == filter.PropertyID) &&
+			(filter.ScheduleID == "" || p.ScheduleID == filter.ScheduleID) {
+			payments = append(payments, p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.After(payments[j].CreatedAt)
+	})
+	return paginate(payments, filter.PaginationParams), len(payments), nil
+}
+
+func (r *memoryPaymentRepo) ListBySchedule(ctx context.Context, scheduleID string) ([]Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payments := []Payment{}
+	for _, p := range r.items {
+		if p.ScheduleID == scheduleID {
+			payments = append(payments, p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.Before(payments[j].CreatedAt)
+	})
+	return payments, nil
+}
+
+func (r *memoryPaymentRepo) GetByID(ctx context.Context, id string) (*Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPaymentRepo) Create(ctx context.Context, payment *Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[payment.ID]; ok {
+		return ErrConflict
+	}
+	schedule, err := r.schedules.GetByID(ctx, payment.ScheduleID)
+	if err != nil {
+		return err
+	}
+	paid := toMinor(payment.Amount)
+	for _, p := range r.items {
+		if p.ScheduleID == schedule.ID && p.Status == "recorded" {
+			paid += toMinor(p.Amount)
+		}
+	}
+	if paid > toMinor(schedule.TotalAmount) {
+		return ErrConflict
+	}
+	r.receipts++
+	payment.ReceiptNumber = formatReceiptNumber(r.receipts)
+	r.items[payment.ID] = *payment
+	return nil
+}
+
+func (r *memoryPaymentRepo) Reverse(ctx context.Context, id, reason string, at time.Time) (*Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if p.Status != "recorded" {
+		return nil, ErrConflict
+	}
+	p.Status = "reversed"
+	p.ReversedAt = &at
+	p.ReversalReason = reason
+	r.items[id] = p
+	return &p, nil
+}
+
+// ============ BLOG POSTS ============
+
+type memoryBlogRepo struct {
+	mu        sync.RWMutex
+	items     map[string]BlogPost
+	revisions *memoryBlogRevisionRepo
+	redirects *memorySlugRedirectRepo
+	campaigns *memoryCampaignRepo
+}
+
+func (r *memoryBlogRepo) List(ctx context.Context, filter BlogFilter) ([]BlogPost, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := make([]BlogPost, 0, len(r.items))
+	for _, p := range r.items {
+		if filter.PublishedOnly && p.Status != BlogPublished {
+			continue
+		}
+		if filter.Status != "" && p.Status != filter.Status {
+			continue
+		}
+		if filter.Category != "" && !strings.EqualFold(p.Category, filter.Category) {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if filter.PublishedOnly && !posts[i].PublishedAt.Equal(*posts[j].PublishedAt) {
+			return posts[i].PublishedAt.After(*posts[j].PublishedAt)
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+	return paginate(posts, filter.PaginationParams), len(posts), nil
+}
+
+func (r *memoryBlogRepo) GetByID(ctx context.Context, id string) (*BlogPost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryBlogRepo) GetBySlug(ctx context.Context, slug string) (*BlogPost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.items {
+		if p.Slug == slug {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Create stores the post first and removes it again when its revision
+// cannot be stored
+func (r *memoryBlogRepo) Create(ctx context.Context, post *BlogPost, revision *BlogRevision) error {
+	r.mu.Lock()
+	if _, ok := r.items[post.ID]; ok {
+		r.mu.Unlock()
+		return ErrConflict
+	}
+	for _, p := range r.items {
+		if p.Slug == post.Slug {
+			r.mu.Unlock()
+			return ErrConflict
+		}
+	}
+	r.items[post.ID] = *post
+	r.mu.Unlock()
+
+	if err := r.revisions.Create(ctx, revision); err != nil {
+		r.mu.Lock()
+		delete(r.items, post.ID)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Save restores the previous content and redirect when the revision cannot
+// be stored
+func (r *memoryBlogRepo) Save(ctx context.Context, post *BlogPost, redirect *SlugRedirect, revision *BlogRevision) error {
+	previous, err := r.GetByID(ctx, post.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.Update(ctx, post); err != nil {
+		return err
+	}
+	var replaced *SlugRedirect
+	if redirect != nil {
+		replaced, _ = r.redirects.Get(ctx, redirect.EntityType, redirect.OldSlug)
+		if err := r.redirects.Save(ctx, redirect); err != nil {
+			r.restore(previous)
+			return err
+		}
+	}
+	if err := r.revisions.Create(ctx, revision); err != nil {
+		r.restore(previous)
+		if redirect != nil {
+			r.redirects.mu.Lock()
+			delete(r.redirects.items, redirect.EntityType+"/"+redirect.OldSlug)
+			if replaced != nil {
+				r.redirects.items[redirect.EntityType+"/"+redirect.OldSlug] = *replaced
+			}
+			r.redirects.mu.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+// restore puts back the content a failed Save overwrote, unless the post
+// was deleted in the meantime
+func (r *memoryBlogRepo) restore(previous *BlogPost) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[previous.ID]; ok {
+		r.items[previous.ID] = *previous
+	}
+}
+
+func (r *memoryBlogRepo) Update(ctx context.Context, post *BlogPost) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.items[post.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, p := range r.items {
+		if p.ID != post.ID && p.Slug == post.Slug {
+			return ErrConflict
+		}
+	}
+	updated := *post
+	updated.Published = existing.Published
+	updated.Status = existing.Status
+	updated.PublishAt = existing.PublishAt
+	updated.PublishedAt = existing.PublishedAt
+	updated.AnnouncementID = existing.AnnouncementID
+	updated.CreatedAt = existing.CreatedAt
+	r.items[post.ID] = updated
+	return nil
+}
+
+func (r *memoryBlogRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+func (r *memoryBlogRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	allowed := false
+	for _, status := range from {
+		allowed = allowed || p.Status == status
+	}
+	if !allowed {
+		return ErrConflict
+	}
+
+	p.Status = to
+	p.Published = to == BlogPublished
+	p.PublishAt = nil
+	switch to {
+	case BlogScheduled:
+		p.PublishAt = &at
+	case BlogPublished:
+		if p.PublishedAt == nil {
+			p.PublishedAt = &at
+		}
+	}
+	p.UpdatedAt = time.Now()
+	r.items[id] = p
+	return nil
+}
+
+func (r *memoryBlogRepo) Due(ctx context.Context, now time.Time) ([]BlogPost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := []BlogPost{}
+	for _, p := range r.items {
+		if p.Status == BlogScheduled && !p.PublishAt.After(now) {
+			due = append(due, p)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].PublishAt.Before(*due[j].PublishAt)
+	})
+	return due, nil
+}
+
+func (r *memoryBlogRepo) Announce(ctx context.Context, id string, campaign *Campaign) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if p.AnnouncementID != nil {
+		return ErrConflict
+	}
+	if err := r.campaigns.Create(ctx, campaign); err != nil {
+		return err
+	}
+	campaignID := campaign.ID
+	p.AnnouncementID = &campaignID
+	r.items[id] = p
+	return nil
+}
+
+// ============ BLOG REVISIONS ============
+
+type memoryBlogRevisionRepo struct {
+	mu    sync.RWMutex
+	items map[string][]BlogRevision // by post ID, oldest first
+	posts *memoryBlogRepo
+}
+
+func (r *memoryBlogRevisionRepo) List(ctx context.Context, postID string, page PaginationParams) ([]BlogRevision, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.items[postID]
+	revisions := make([]BlogRevision, len(stored))
+	for i, revision := range stored {
+		revisions[len(stored)-1-i] = revision
+	}
+	return paginate(revisions, page), len(revisions), nil
+}
+
+func (r *memoryBlogRevisionRepo) Get(ctx context.Context, postID string, number int) (*BlogRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.items[postID]
+	if number < 1 || number > len(stored) {
+		return nil, ErrNotFound
+	}
+	revision := stored[number-1]
+	return &revision, nil
+}
+
+func (r *memoryBlogRevisionRepo) Create(ctx context.Context, revision *BlogRevision) error {
+	if _, err := r.posts.GetByID(ctx, revision.PostID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision.Revision = len(r.items[revision.PostID]) + 1
+	r.items[revision.PostID] = append(r.items[revision.PostID], *revision)
+	return nil
+}
+
+// ============ CONTACT SUBMISSIONS ============
+
+type memoryContactRepo struct {
+	mu    sync.RWMutex
+	items map[string]ContactSubmission
+}
+
+func (r *memoryContactRepo) List(ctx context.Context, filter ContactFilter) ([]ContactSubmission, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	submissions := make([]ContactSubmission, 0, len(r.items))
+	for _, s := range r.items {
+		if filter.UnreadOnly && s.IsRead {
+			continue
+		}
+		submissions = append(submissions, s)
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].CreatedAt.After(submissions[j].CreatedAt)
+	})
+	return paginate(submissions, filter.PaginationParams), len(submissions), nil
+}
+
+func (r *memoryContactRepo) GetByID(ctx context.Context, id string) (*ContactSubmission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memoryContactRepo) Create(ctx context.Context, submission *ContactSubmission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[submission.ID]; ok {
+		return ErrConflict
+	}
+	r.items[submission.ID] = *submission
+	return nil
+}
+
+func (r *memoryContactRepo) MarkRead(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.IsRead = true
+	r.items[id] = s
+	return nil
+}
+
+// ============ NEWSLETTER SUBSCRIBERS ============
+
+// memoryNewsletterRepo keys subscribers by lower-cased email. Like the
+// subscriber_profiles view, reads add the account with the same email and
+// its favorites.
+type memoryNewsletterRepo struct {
+	mu        sync.RWMutex
+	items     map[string]NewsletterSubscriber
+	users     *memoryUserRepo
+	favorites *memoryFavoriteRepo
+}
+
+// withProfile links s to the account with the same email
+func (r *memoryNewsletterRepo) withProfile(ctx context.Context, s NewsletterSubscriber) NewsletterSubscriber {
+	s.UserID = nil
+	s.FavoritePropertyIDs = []string{}
+	user, err := r.users.GetByEmail(ctx, s.Email)
+	if err != nil {
+		return s
+	}
+	s.UserID = &user.ID
+	favorites, _ := r.favorites.ListByUser(ctx, user.ID)
+	// Oldest first, as in the view
+	for i := len(favorites) - 1; i >= 0; i-- {
+		s.FavoritePropertyIDs = append(s.FavoritePropertyIDs, favorites[i].PropertyID)
+	}
+	return s
+}
+
+func (r *memoryNewsletterRepo) List(ctx context.Context, filter NewsletterFilter) ([]NewsletterSubscriber, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscribers := make([]NewsletterSubscriber, 0, len(r.items))
+	for _, s := range r.items {
+		if filter.ActiveOnly && !s.Active {
+			continue
+		}
+		if filter.Status != "" && s.Status != filter.Status {
+			continue
+		}
+		subscribers = append(subscribers, s)
+	}
+	sort.Slice(subscribers, func(i, j int) bool {
+		return subscribers[i].CreatedAt.After(subscribers[j].CreatedAt)
+	})
+	subscribers, total := paginate(subscribers, filter.PaginationParams), len(subscribers)
+	for i := range subscribers {
+		subscribers[i] = r.withProfile(ctx, subscribers[i])
+	}
+	return subscribers, total, nil
+}
+
+func (r *memoryNewsletterRepo) GetByEmail(ctx context.Context, email string) (*NewsletterSubscriber, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.items[strings.ToLower(email)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s = r.withProfile(ctx, s)
+	return &s, nil
+}
+
+func (r *memoryNewsletterRepo) Create(ctx context.Context, subscriber *NewsletterSubscriber) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(subscriber.Email)
+	if _, ok := r.items[key]; ok {
+		return ErrConflict
+	}
+	r.items[key] = *subscriber
+	return nil
+}
+
+func (r *memoryNewsletterRepo) Update(ctx context.Context, subscriber *NewsletterSubscriber) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(subscriber.Email)
+	if _, ok := r.items[key]; !ok {
+		return ErrNotFound
+	}
+	r.items[key] = *subscriber
+	return nil
+}
+
+// ============ NEWSLETTER SEGMENTS ============
+
+type memorySegmentRepo struct {
+	mu    sync.RWMutex
+	items map[string]Segment
+}
+
+func (r *memorySegmentRepo) List(ctx context.Context, page PaginationParams) ([]Segment, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	segments := make([]Segment, 0, len(r.items))
+	for _, s := range r.items {
+		segments = append(segments, s)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Name < segments[j].Name
+	})
+	return paginate(segments, page), len(segments), nil
+}
+
+func (r *memorySegmentRepo) GetByID(ctx context.Context, id string) (*Segment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+// nameTaken reports whether another segment than id is called name
+func (r *memorySegmentRepo) nameTaken(id, name string) bool {
+	for _, s := range r.items {
+		if s.ID != id && s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memorySegmentRepo) Create(ctx context.Context, segment *Segment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[segment.ID]; ok || r.nameTaken(segment.ID, segment.Name) {
+		return ErrConflict
+	}
+	r.items[segment.ID] = *segment
+	return nil
+}
+
+func (r *memorySegmentRepo) Update(ctx context.Context, segment *Segment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[segment.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.nameTaken(segment.ID, segment.Name) {
+		return ErrConflict
+	}
+	r.items[segment.ID] = *segment
+	return nil
+}
+
+func (r *memorySegmentRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ============ NEWSLETTER CAMPAIGNS ============
+
+// memoryCampaignRepo keeps each campaign's recipients in the order they
+// were added, and its events, by campaign ID
+type memoryCampaignRepo struct {
+	mu         sync.RWMutex
+	items      map[string]Campaign
+	recipients map[string][]CampaignRecipient
+	events     map[string][]CampaignEvent
+}
+
+// withCounts fills in the recipient counts of c; the caller holds r.mu
+func (r *memoryCampaignRepo) withCounts(c Campaign) Campaign {
+	c.RecipientCount, c.SentCount, c.FailedCount, c.SkippedCount = 0, 0, 0, 0
+	for _, recipient := range r.recipients[c.ID] {
+		c.RecipientCount++
+		switch recipient.Status {
+		case "sent":
+			c.SentCount++
+		case "failed":
+			c.FailedCount++
+		case "skipped":
+			c.SkippedCount++
+		}
+	}
+	return c
+}
+
+func (r *memoryCampaignRepo) List(ctx context.Context, filter CampaignFilter) ([]Campaign, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	campaigns := make([]Campaign, 0, len(r.items))
+	for _, c := range r.items {
+		if filter.Status != "" && c.Status != filter.Status {
+			continue
+		}
+		if filter.Segment != "" && c.Segment != filter.Segment {
+			continue
+		}
+		campaigns = append(campaigns, r.withCounts(c))
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+	})
+	return paginate(campaigns, filter.PaginationParams), len(campaigns), nil
+}
+
+func (r *memoryCampaignRepo) GetByID(ctx context.Context, id string) (*Campaign, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c = r.withCounts(c)
+	return &c, nil
+}
+
+func (r *memoryCampaignRepo) Create(ctx context.Context, campaign *Campaign) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[campaign.ID]; ok {
+		return ErrConflict
+	}
+	r.items[campaign.ID] = *campaign
+	return nil
+}
+
+func (r *memoryCampaignRepo) Update(ctx context.Context, campaign *Campaign) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.items[campaign.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.Subject = campaign.Subject
+	c.HTMLBody = campaign.HTMLBody
+	c.TextBody = campaign.TextBody
+	c.Segment = campaign.Segment
+	c.UpdatedAt = campaign.UpdatedAt
+	r.items[c.ID] = c
+	return nil
+}
+
+func (r *memoryCampaignRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	delete(r.recipients, id)
+	delete(r.events, id)
+	return nil
+}
+
+func (r *memoryCampaignRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	allowed := false
+	for _, status := range from {
+		allowed = allowed || c.Status == status
+	}
+	if !allowed {
+		return ErrConflict
+	}
+
+	c.Status = to
+	switch to {
+	case CampaignScheduled:
+		c.ScheduledAt = &at
+		c.CompletedAt = nil
+	case CampaignDraft:
+		c.ScheduledAt = nil
+	case CampaignSending:
+		if c.StartedAt == nil {
+			c.StartedAt = &at
+		}
+	case CampaignSent, CampaignCancelled, CampaignFailed:
+		c.CompletedAt = &at
+	}
+	c.UpdatedAt = at
+	r.items[id] = c
+	return nil
+}
+
+func (r *memoryCampaignRepo) Due(ctx context.Context, now time.Time) ([]Campaign, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := []Campaign{}
+	for _, c := range r.items {
+		if c.Status == CampaignSending || (c.Status == CampaignScheduled && !c.ScheduledAt.After(now)) {
+			due = append(due, r.withCounts(c))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ScheduledAt.Before(*due[j].ScheduledAt)
+	})
+	return due, nil
+}
+
+func (r *memoryCampaignRepo) AddRecipients(ctx context.Context, campaignID string, recipients []CampaignRecipient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queued := map[string]bool{}
+	for _, recipient := range r.recipients[campaignID] {
+		queued[recipient.Email] = true
+	}
+	for _, recipient := range recipients {
+		recipient.Email = strings.ToLower(recipient.Email)
+		if queued[recipient.Email] {
+			continue
+		}
+		queued[recipient.Email] = true
+		recipient.ID = uuid.New().String()
+		recipient.CampaignID = campaignID
+		recipient.Status = "pending"
+		recipient.CreatedAt = time.Now()
+		r.recipients[campaignID] = append(r.recipients[campaignID], recipient)
+	}
+	return nil
+}
+
+func (r *memoryCampaignRepo) PendingRecipients(ctx context.Context, campaignID string, limit int) ([]CampaignRecipient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pending := []CampaignRecipient{}
+	for _, recipient := range r.recipients[campaignID] {
+		if recipient.Status == "pending" {
+			pending = append(pending, recipient)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Attempts != pending[j].Attempts {
+			return pending[i].Attempts < pending[j].Attempts
+		}
+		return pending[i].Email < pending[j].Email
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (r *memoryCampaignRepo) GetRecipient(ctx context.Context, id string) (*CampaignRecipient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, recipients := range r.recipients {
+		for _, recipient := range recipients {
+			if recipient.ID == id {
+				return &recipient, nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryCampaignRepo) UpdateRecipient(ctx context.Context, recipient *CampaignRecipient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recipients := r.recipients[recipient.CampaignID]
+	for i := range recipients {
+		if recipients[i].ID == recipient.ID {
+			recipients[i].Status = recipient.Status
+			recipients[i].Attempts = recipient.Attempts
+			recipients[i].Error = recipient.Error
+			recipients[i].SentAt = recipient.SentAt
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *memoryCampaignRepo) ListRecipients(ctx context.Context, filter CampaignRecipientFilter) ([]CampaignRecipient, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recipients := []CampaignRecipient{}
+	for _, recipient := range r.recipients[filter.CampaignID] {
+		if filter.Status != "" && recipient.Status != filter.Status {
+			continue
+		}
+		recipients = append(recipients, recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		return recipients[i].Email < recipients[j].Email
+	})
+	return paginate(recipients, filter.PaginationParams), len(recipients), nil
+}
+
+func (r *memoryCampaignRepo) RecordEvent(ctx context.Context, event *CampaignEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[event.CampaignID]; !ok {
+		return ErrNotFound
+	}
+	r.events[event.CampaignID] = append(r.events[event.CampaignID], *event)
+	return nil
+}
+
+func (r *memoryCampaignRepo) EventStats(ctx context.Context, campaignID string) (*CampaignStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &CampaignStats{}
+	opened, clicked := map[string]bool{}, map[string]bool{}
+	for _, event := range r.events[campaignID] {
+		opened[event.RecipientID] = true
+		if event.Type == "click" {
+			stats.Clicks++
+			clicked[event.RecipientID] = true
+		} else {
+			stats.Opens++
+		}
+	}
+	stats.UniqueOpens, stats.UniqueClicks = len(opened), len(clicked)
+	return stats, nil
+}
+
+func (r *memoryCampaignRepo) TopLinks(ctx context.Context, campaignID string, limit int) ([]CampaignLinkStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := map[string]*CampaignLinkStats{}
+	clickers := map[string]map[string]bool{}
+	for _, event := range r.events[campaignID] {
+		if event.Type != "click" {
+			continue
+		}
+		link, ok := byURL[event.URL]
+		if !ok {
+			link = &CampaignLinkStats{URL: event.URL}
+			byURL[event.URL] = link
+			clickers[event.URL] = map[string]bool{}
+		}
+		link.Clicks++
+		clickers[event.URL][event.RecipientID] = true
+	}
+
+	links := make([]CampaignLinkStats, 0, len(byURL))
+	for url, link := range byURL {
+		link.UniqueClicks = len(clickers[url])
+		links = append(links, *link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Clicks != links[j].Clicks {
+			return links[i].Clicks > links[j].Clicks
+		}
+		if links[i].UniqueClicks != links[j].UniqueClicks {
+			return links[i].UniqueClicks > links[j].UniqueClicks
+		}
+		return links[i].URL < links[j].URL
+	})
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links, nil
+}
+
+// ============ USERS ============
+
+type memoryUserRepo struct {
+	mu    sync.RWMutex
+	items map[string]User
+}
+
+func (r *memoryUserRepo) List(ctx context.Context, page PaginationParams) ([]User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]User, 0, len(r.items))
+	for _, u := range r.items {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+	return paginate(users, page), len(users), nil
+}
+
+func (r *memoryUserRepo) GetByID(ctx context.Context, id string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepo) GetByEmail(ctx context.Context, email string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.items {
+		if strings.EqualFold(u.Email, email) {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[user.ID]; ok {
+		return ErrConflict
+	}
+	for _, u := range r.items {
+		if strings.EqualFold(u.Email, user.Email) {
+			return ErrConflict
+		}
+	}
+	r.items[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[user.ID]; !ok {
+		return ErrNotFound
+	}
+	for _, u := range r.items {
+		if u.ID != user.ID && strings.EqualFold(u.Email, user.Email) {
+			return ErrConflict
+		}
+	}
+	r.items[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ============ REVIEWS ============
+
+type memoryReviewRepo struct {
+	mu    sync.RWMutex
+	items map[string]Review
+}
+
+func (r *memoryReviewRepo) list(match func(Review) bool) []Review {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reviews := []Review{}
+	for _, rv := range r.items {
+		if match(rv) {
+			reviews = append(reviews, rv)
+		}
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		return reviews[i].CreatedAt.After(reviews[j].CreatedAt)
+	})
+	return reviews
+}
+
+func (r *memoryReviewRepo) ListByUser(ctx context.Context, userID string) ([]Review, error) {
+	return r.list(func(rv Review) bool { return rv.UserID == userID }), nil
+}
+
+func (r *memoryReviewRepo) ListByProperty(ctx context.Context, propertyID string) ([]Review, error) {
+	return r.list(func(rv Review) bool { return rv.PropertyID == propertyID }), nil
+}
+
+func (r *memoryReviewRepo) Create(ctx context.Context, review *Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rv := range r.items {
+		if rv.ID == review.ID || (rv.UserID == review.UserID && rv.PropertyID == review.PropertyID) {
+			return ErrConflict
+		}
+	}
+	r.items[review.ID] = *review
+	return nil
+}
+
+// ============ FAVORITES ============
+
+type memoryFavoriteRepo struct {
+	mu    sync.RWMutex
+	items map[string]Favorite
+}
+
+func (r *memoryFavoriteRepo) ListByUser(ctx context.Context, userID string) ([]Favorite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	favorites := []Favorite{}
+	for _, f := range r.items {
+		if f.UserID == userID {
+			favorites = append(favorites, f)
+		}
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].CreatedAt.After(favorites[j].CreatedAt)
+	})
+	return favorites, nil
+}
+
+func (r *memoryFavoriteRepo) Add(ctx context.Context, favorite *Favorite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.items {
+		if f.ID == favorite.ID || (f.UserID == favorite.UserID && f.PropertyID == favorite.PropertyID) {
+			return ErrConflict
+		}
+	}
+	r.items[favorite.ID] = *favorite
+	return nil
+}
+
+func (r *memoryFavoriteRepo) Remove(ctx context.Context, userID, propertyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, f := range r.items {
+		if f.UserID == userID && f.PropertyID == propertyID {
+			delete(r.items, id)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// ============ BROCHURE REQUESTS ============
+
+type memoryBrochureRepo struct {
+	mu    sync.RWMutex
+	items map[string]BrochureRequest
+	// properties supplies the titles in Stats
+	properties *memoryPropertyRepo
+}
+
+func (r *memoryBrochureRepo) Create(ctx context.Context, request *BrochureRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[request.ID]; ok {
+		return ErrConflict
+	}
+	r.items[request.ID] = *request
+	return nil
+}
+
+func (r *memoryBrochureRepo) GetByID(ctx context.Context, id string) (*BrochureRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	request, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &request, nil
+}
+
+func (r *memoryBrochureRepo) RecordDownload(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	request.DownloadCount++
+	request.LastDownloadedAt = &at
+	r.items[id] = request
+	return nil
+}
+
+func (r *memoryBrochureRepo) Stats(ctx context.Context, limit int) ([]BrochureStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.properties.mu.RLock()
+	defer r.properties.mu.RUnlock()
+
+	byProperty := map[string]*BrochureStats{}
+	for _, request := range r.items {
+		property, ok := r.properties.items[request.PropertyID]
+		if !ok {
+			continue
+		}
+		stats, ok := byProperty[property.ID]
+		if !ok {
+			stats = &BrochureStats{PropertyID: property.ID, PropertyTitle: property.Title}
+			byProperty[property.ID] = stats
+		}
+		stats.Requests++
+		stats.Downloads += request.DownloadCount
+	}
+
+	all := make([]BrochureStats, 0, len(byProperty))
+	for _, stats := range byProperty {
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Downloads != all[j].Downloads {
+			return all[i].Downloads > all[j].Downloads
+		}
+		if all[i].Requests != all[j].Requests {
+			return all[i].Requests > all[j].Requests
+		}
+		return all[i].PropertyTitle < all[j].PropertyTitle
+	})
+	if limit > 0 && limit < len(all) {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// ============ LEADS ============
+
+type memoryLeadRepo struct {
+	mu    sync.RWMutex
+	items map[string]Lead // keyed by lowercase email
+}
+
+func (r *memoryLeadRepo) List(ctx context.Context, filter LeadFilter) ([]Lead, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	search := strings.ToLower(filter.Search)
+	leads := make([]Lead, 0, len(r.items))
+	for _, l := range r.items {
+		if filter.Source != "" && l.Source != filter.Source {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(l.Email), search) {
+			continue
+		}
+		leads = append(leads, l)
+	}
+	sort.Slice(leads, func(i, j int) bool {
+		return leads[i].LastSeenAt.After(leads[j].LastSeenAt)
+	})
+	return paginate(leads, filter.PaginationParams), len(leads), nil
+}
+
+func (r *memoryLeadRepo) Capture(ctx context.Context, lead *Lead) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(lead.Email)
+	existing, ok := r.items[key]
+	if !ok {
+		lead.RequestCount = 1
+		r.items[key] = *lead
+		return nil
+	}
+	existing.RequestCount++
+	existing.LastSeenAt = lead.LastSeenAt
+	if existing.UserID == nil {
+		existing.UserID = lead.UserID
+	}
+	r.items[key] = existing
+	*lead = existing
+	return nil
+}
+
+// ============ SESSIONS ============
+
+type memorySessionRepo struct {
+	mu    sync.RWMutex
+	items map[string]Session
+}
+
+func (r *memorySessionRepo) Create(ctx context.Context, session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[session.ID]; ok {
+		return ErrConflict
+	}
+	r.items[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessionRepo) GetByID(ctx context.Context, id string) (*Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memorySessionRepo) Rotate(ctx context.Context, id, oldJTI, newJTI string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.items[id]
+	if !ok || s.RevokedAt != nil || s.RefreshJTI != oldJTI {
+		return ErrNotFound
+	}
+	s.RefreshJTI = newJTI
+	s.ExpiresAt = expiresAt
+	s.UpdatedAt = time.Now()
+	r.items[id] = s
+	return nil
+}
+
+func (r *memorySessionRepo) Revoke(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.items[id]
+	if ok && s.RevokedAt == nil {
+		now := time.Now()
+		s.RevokedAt = &now
+		s.UpdatedAt = now
+		r.items[id] = s
+	}
+	return nil
+}
+
+func (r *memorySessionRepo) RevokeByUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range r.items {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+			s.UpdatedAt = now
+			r.items[id] = s
+		}
+	}
+	return nil
+}
+
+// ============ SLUG REDIRECTS ============
+
+type memorySlugRedirectRepo struct {
+	mu    sync.RWMutex
+	items map[string]SlugRedirect // keyed by entity type and old slug
+}
+
+func (r *memorySlugRedirectRepo) Save(ctx context.Context, redirect *SlugRedirect) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items[redirect.EntityType+"/"+redirect.OldSlug] = *redirect
+	return nil
+}
+
+func (r *memorySlugRedirectRepo) Get(ctx context.Context, entityType, slug string) (*SlugRedirect, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	redirect, ok := r.items[entityType+"/"+slug]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &redirect, nil
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryPropertySearch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, p := range []Property{
+		{ID: "lakeside", Slug: "lakeside-acres", Title: "Lakeside Acres", Description: "Waterfront lots near the marina", Location: "Austin, TX", Price: 250000, Status: "available", Units: 12, Acres: 40, Features: []string{"Water", "Gated"}},
+		{ID: "hilltop", Slug: "hilltop-homes", Title: "Hilltop Homes", Description: "Ready-built family homes", Location: "Dallas, TX", Price: 400000, Status: "sold", Units: 30, Acres: 15, Features: []string{"gated"}},
+		{ID: "prairie", Slug: "prairie-ranch", Title: "Prairie Ranch", Description: "Open land with water rights", Location: "Amarillo, TX", Price: 90000, Status: "available", Units: 4, Acres: 120},
+	} {
+		p.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		if err := store.Properties.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	min, max := 100000.0, 300000.0
+	units := 10
+	tests := []struct {
+		name   string
+		filter PropertyFilter
+		want   []string
+	}{
+		{"newest first by default", PropertyFilter{}, []string{"prairie", "hilltop", "lakeside"}},
+		{"status", PropertyFilter{Status: "available"}, []string{"prairie", "lakeside"}},
+		{"location is a case-insensitive substring", PropertyFilter{Location: "dallas"}, []string{"hilltop"}},
+		{"price range", PropertyFilter{MinPrice: &min, MaxPrice: &max}, []string{"lakeside"}},
+		{"minimum units", PropertyFilter{MinUnits: &units}, []string{"hilltop", "lakeside"}},
+		{"features must all be present", PropertyFilter{Features: []string{"GATED", "water"}}, []string{"lakeside"}},
+		{"every query word must match", PropertyFilter{Query: "water lots"}, []string{"lakeside"}},
+		{"query searches descriptions", PropertyFilter{Query: "Water"}, []string{"prairie", "lakeside"}},
+		{"price ascending", PropertyFilter{SortBy: "price", SortAsc: true}, []string{"prairie", "lakeside", "hilltop"}},
+		{"acres descending", PropertyFilter{SortBy: "acres"}, []string{"prairie", "lakeside", "hilltop"}},
+		{"nothing matches", PropertyFilter{Status: "pending"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.PaginationParams = PaginationParams{Page: 1, Limit: 10}
+			properties, total, err := store.Properties.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, p := range properties {
+				got = append(got, p.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != len(tt.want) {
+				t.Errorf("got %v (total %d), want %v", got, total, tt.want)
+			}
+		})
+	}
+}
+
+// newReservationStore returns a memory store holding available plots
+// p1, p2 and p3 of property prop
+func newReservationStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewMemoryStore()
+	now := time.Now()
+	var plots []Plot
+	for _, id := range []string{"p1", "p2", "p3"} {
+		plots = append(plots, Plot{ID: id, PropertyID: "prop", PlotNumber: id, Status: "available", CreatedAt: now, UpdatedAt: now})
+	}
+	if err := store.Plots.CreateBatch(context.Background(), plots); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestMemoryReservationRace(t *testing.T) {
+	tests := []struct {
+		name    string
+		plotIDs func(i int) []string
+	}{
+		{"same plot", func(int) []string { return []string{"p1"} }},
+		{"overlapping plots", func(i int) []string {
+			if i%2 == 0 {
+				return []string{"p1", "p2"}
+			}
+			return []string{"p2", "p3"}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newReservationStore(t)
+			const buyers = 50
+
+			var wg sync.WaitGroup
+			errs := make([]error, buyers)
+			start := make(chan struct{})
+			for i := 0; i < buyers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-start
+					now := time.Now()
+					errs[i] = store.Reservations.Create(ctx, &Reservation{
+						ID:         fmt.Sprintf("r%d", i),
+						PropertyID: "prop",
+						UserID:     fmt.Sprintf("u%d", i),
+						PlotIDs:    tt.plotIDs(i),
+						Status:     "active",
+						ExpiresAt:  now.Add(time.Hour),
+						CreatedAt:  now,
+						UpdatedAt:  now,
+					})
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+
+			winner := -1
+			for i, err := range errs {
+				switch {
+				case err == nil && winner >= 0:
+					t.Fatalf("buyers %d and %d both got the plot", winner, i)
+				case err == nil:
+					winner = i
+				case !errors.Is(err, ErrConflict):
+					t.Errorf("buyer %d: err = %v, want ErrConflict", i, err)
+				}
+			}
+			if winner < 0 {
+				t.Fatal("no buyer got the plot")
+			}
+
+			for _, id := range tt.plotIDs(winner) {
+				plot, err := store.Plots.GetByID(ctx, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if plot.Status != "reserved" || plot.ReservationID == nil || *plot.ReservationID != fmt.Sprintf("r%d", winner) {
+					t.Errorf("plot %s is %s for %v, want reserved for r%d", id, plot.Status, plot.ReservationID, winner)
+				}
+			}
+			_, total, err := store.Reservations.List(ctx, ReservationFilter{PaginationParams: PaginationParams{Page: 1, Limit: 100}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 1 {
+				t.Errorf("%d reservations stored, want 1", total)
+			}
+		})
+	}
+}
+
+func TestMemoryReservationCreate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		held    []string // plots reserved beforehand
+		plotIDs []string
+		wantErr error
+	}{
+		{"available", nil, []string{"p1", "p2"}, nil},
+		{"one plot held", []string{"p2"}, []string{"p1", "p2"}, ErrConflict},
+		{"unknown plot", nil, []string{"p1", "p9"}, ErrConflict},
+		{"other plots held", []string{"p3"}, []string{"p1", "p2"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newReservationStore(t)
+			now := time.Now()
+			if tt.held != nil {
+				if err := store.Reservations.Create(ctx, &Reservation{
+					ID: "held", PropertyID: "prop", PlotIDs: tt.held, Status: "active", ExpiresAt: now.Add(time.Hour),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := store.Reservations.Create(ctx, &Reservation{
+				ID: "new", PropertyID: "prop", PlotIDs: tt.plotIDs, Status: "active", ExpiresAt: now.Add(time.Hour),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				// A refused reservation must not hold any of its plots
+				for _, id := range tt.plotIDs {
+					plot, err := store.Plots.GetByID(ctx, id)
+					if err != nil {
+						continue
+					}
+					if plot.ReservationID != nil && *plot.ReservationID == "new" {
+						t.Errorf("plot %s is held by the refused reservation", id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryReservationConvert(t *testing.T) {
+	ctx := context.Background()
+	store := newReservationStore(t)
+	now := time.Now()
+	if err := store.Reservations.Create(ctx, &Reservation{
+		ID: "r1", PropertyID: "prop", UserID: "u1", PlotIDs: []string{"p1", "p2"}, Status: "active", ExpiresAt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	schedule := func(id string) *PaymentSchedule {
+		reservationID := "r1"
+		return &PaymentSchedule{ID: id, UserID: "u1", PropertyID: "prop", ReservationID: &reservationID, CreatedAt: now}
+	}
+
+	converted, err := store.Reservations.Convert(ctx, "r1", schedule("s1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.Status != "converted" {
+		t.Errorf("status = %s, want converted", converted.Status)
+	}
+	for _, id := range []string{"p1", "p2"} {
+		if plot, _ := store.Plots.GetByID(ctx, id); plot.Status != "sold" {
+			t.Errorf("plot %s is %s, want sold", id, plot.Status)
+		}
+	}
+	if _, err := store.Schedules.GetByID(ctx, "s1"); err != nil {
+		t.Errorf("schedule of the conversion: %v", err)
+	}
+
+	// Converting again must not leave a second schedule behind
+	if _, err := store.Reservations.Convert(ctx, "r1", schedule("s2")); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second convert err = %v, want ErrConflict", err)
+	}
+	if _, err := store.Schedules.GetByID(ctx, "s2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("schedule of the refused conversion: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryPlotUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := newReservationStore(t)
+	stale, err := store.Plots.GetByID(ctx, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reservations.Create(ctx, &Reservation{
+		ID: "r1", PropertyID: "prop", PlotIDs: []string{"p1"}, Status: "active", ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A copy read before the reservation must not release the plot
+	if err := store.Plots.Update(ctx, stale); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale update err = %v, want ErrConflict", err)
+	}
+	held, _ := store.Plots.GetByID(ctx, "p1")
+	if held.Status != "reserved" || held.ReservationID == nil {
+		t.Fatalf("held plot after a refused update: %+v", held)
+	}
+
+	// Edits that keep the status are still allowed on a held plot
+	held.Price = 50000
+	if err := store.Plots.Update(ctx, held); err != nil {
+		t.Fatalf("price update on a held plot: %v", err)
+	}
+	if got, _ := store.Plots.GetByID(ctx, "p1"); got.Price != 50000 || got.ReservationID == nil {
+		t.Errorf("held plot after a price update: %+v", got)
+	}
+
+	free, _ := store.Plots.GetByID(ctx, "p2")
+	free.Status = "sold"
+	if err := store.Plots.Update(ctx, free); err != nil {
+		t.Errorf("status update on a free plot: %v", err)
+	}
+	if err := store.Plots.Update(ctx, &Plot{ID: "p9", Status: "sold"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown plot err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryBlogSave(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	post := &BlogPost{ID: "post", Title: "Old", Slug: "old-title", ContentMarkdown: "a"}
+	first := &BlogRevision{ID: "rev1", PostID: "post", Title: "Old", ContentMarkdown: "a"}
+	if err := store.Blog.Create(ctx, post, first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Revision != 1 {
+		t.Errorf("first revision = %d, want 1", first.Revision)
+	}
+
+	renamed := *post
+	renamed.Title, renamed.Slug, renamed.ContentMarkdown = "New", "new-title", "b"
+	redirect := &SlugRedirect{ID: "redirect", EntityType: "blog_post", OldSlug: "old-title", EntityID: "post"}
+	second := &BlogRevision{ID: "rev2", PostID: "post", Title: "New", ContentMarkdown: "b"}
+	if err := store.Blog.Save(ctx, &renamed, redirect, second); err != nil {
+		t.Fatal(err)
+	}
+	if second.Revision != 2 {
+		t.Errorf("second revision = %d, want 2", second.Revision)
+	}
+	if got, err := store.SlugRedirects.Get(ctx, "blog_post", "old-title"); err != nil || got.EntityID != "post" {
+		t.Errorf("redirect = %+v, %v", got, err)
+	}
+
+	// When the revision cannot be stored, neither the content nor the
+	// redirect is kept
+	failed := renamed
+	failed.Title, failed.Slug = "Newer", "newer-title"
+	orphan := &BlogRevision{ID: "rev3", PostID: "deleted-post", Title: "Newer"}
+	err := store.Blog.Save(ctx, &failed, &SlugRedirect{ID: "r2", EntityType: "blog_post", OldSlug: "new-title", EntityID: "post"}, orphan)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("save with a failing revision: err = %v, want ErrNotFound", err)
+	}
+	if got, _ := store.Blog.GetByID(ctx, "post"); got.Title != "New" || got.Slug != "new-title" {
+		t.Errorf("post is %q at %s after the failed save, want the previous content", got.Title, got.Slug)
+	}
+	if _, err := store.SlugRedirects.Get(ctx, "blog_post", "new-title"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("redirect of the failed save: err = %v, want ErrNotFound", err)
+	}
+	if _, total, _ := store.BlogRevisions.List(ctx, "post", PaginationParams{Page: 1, Limit: 10}); total != 2 {
+		t.Errorf("%d revisions, want 2", total)
+	}
+}
+
+func TestMemoryPropertySave(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, p := range []Property{
+		{ID: "a", Title: "Palm Court", Slug: "palm-court"},
+		{ID: "b", Title: "Cedar Park", Slug: "cedar-park"},
+	} {
+		if err := store.Properties.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	redirect := func(oldSlug string) *SlugRedirect {
+		return &SlugRedirect{ID: oldSlug, EntityType: slugEntityProperty, OldSlug: oldSlug, EntityID: "a"}
+	}
+
+	renamed := &Property{ID: "a", Title: "Palm Gardens", Slug: "palm-gardens"}
+	if err := store.Properties.Save(ctx, renamed, redirect("palm-court")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.SlugRedirects.Get(ctx, slugEntityProperty, "palm-court"); err != nil || got.EntityID != "a" {
+		t.Errorf("redirect = %+v, %v", got, err)
+	}
+
+	// A save that fails stores neither the property nor its redirect
+	taken := &Property{ID: "a", Title: "Cedar Park", Slug: "cedar-park"}
+	if err := store.Properties.Save(ctx, taken, redirect("palm-gardens")); !errors.Is(err, ErrConflict) {
+		t.Fatalf("save with a taken slug: err = %v, want ErrConflict", err)
+	}
+	if got, _ := store.Properties.GetByID(ctx, "a"); got.Slug != "palm-gardens" {
+		t.Errorf("slug = %s after the failed save, want palm-gardens", got.Slug)
+	}
+	if _, err := store.SlugRedirects.Get(ctx, slugEntityProperty, "palm-gardens"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("redirect of the failed save: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Properties.Save(ctx, &Property{ID: "gone", Slug: "gone"}, redirect("old-gone")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("save of an unknown property: err = %v, want ErrNotFound", err)
+	}
+}
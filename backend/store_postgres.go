@@ -0,0 +1,1060 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewPostgresStore returns a Store that talks directly to Postgres through pgx
+func NewPostgresStore(pool *pgxpool.Pool) *Store {
+	return &Store{
+		Properties:    &pgPropertyRepo{pool: pool},
+		Plots:         &pgPlotRepo{pool: pool},
+		Reservations:  &pgReservationRepo{pool: pool},
+		PaymentPlans:  &pgPaymentPlanRepo{pool: pool},
+		Schedules:     &pgScheduleRepo{pool: pool},
+		Payments:      &pgPaymentRepo{pool: pool},
+		Blog:          &pgBlogRepo{pool: pool},
+		BlogRevisions: &pgBlogRevisionRepo{pool: pool},
+		Contacts:      &pgContactRepo{pool: pool},
+		Newsletter:    &pgNewsletterRepo{pool: pool},
+		Segments:      &pgSegmentRepo{pool: pool},
+		Campaigns:     &pgCampaignRepo{pool: pool},
+		Users:         &pgUserRepo{pool: pool},
+		Reviews:       &pgReviewRepo{pool: pool},
+		Favorites:     &pgFavoriteRepo{pool: pool},
+		Brochures:     &pgBrochureRepo{pool: pool},
+		Leads:         &pgLeadRepo{pool: pool},
+		Sessions:      &pgSessionRepo{pool: pool},
+		SlugRedirects: &pgSlugRedirectRepo{pool: pool},
+	}
+}
+
+// pgError maps pgx and Postgres errors onto repository errors
+func pgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrConflict
+		case sqlStateNotFound:
+			return ErrNotFound
+		case sqlStateConflict:
+			return ErrConflict
+		}
+	}
+	return err
+}
+
+// pgQuery accumulates WHERE clauses and their positional arguments
+type pgQuery struct {
+	where []string
+	args  []interface{}
+}
+
+// arg registers a value and returns its $n placeholder
+func (q *pgQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// and adds a clause joined with AND
+func (q *pgQuery) and(clause string) {
+	q.where = append(q.where, clause)
+}
+
+// likeEscaper escapes the wildcards of LIKE in user input, so it is matched
+// literally with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike returns s as a literal LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+func (q *pgQuery) whereSQL() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// pgList runs a filtered, ordered and paginated SELECT along with its total count
+func pgList[T any](ctx context.Context, pool *pgxpool.Pool, columns, table string, q *pgQuery, orderBy string, page PaginationParams) ([]T, int, error) {
+	var total int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+table+q.whereSQL(), q.args...).Scan(&total); err != nil {
+		return nil, 0, pgError(err)
+	}
+
+	sql := "SELECT " + columns + " FROM " + table + q.whereSQL() + " ORDER BY " + orderBy
+	if page.Limit > 0 {
+		sql += " LIMIT " + q.arg(page.Limit) + " OFFSET " + q.arg(page.Offset())
+	}
+
+	items, err := pgSelect[T](ctx, pool, sql, q.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// pgSelect runs a query and scans every row into T by column name
+func pgSelect[T any](ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) ([]T, error) {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return items, nil
+}
+
+// pgGet runs a query expected to return one row and scans it into T
+func pgGet[T any](ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) (*T, error) {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	item, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[T])
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return item, nil
+}
+
+// pgExec runs a write, returning ErrNotFound when no row was affected
+func pgExec(ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) error {
+	tag, err := pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return pgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ============ PROPERTIES ============
+
+const propertyColumns = `id, title, slug, COALESCE(description, '') AS description,
+	COALESCE(location, '') AS location, latitude, longitude, boundary, COALESCE(price, 0) AS price,
+	COALESCE(status, '') AS status, COALESCE(units, 0) AS units, COALESCE(acres, 0) AS acres,
+	COALESCE(features, '[]'::jsonb) AS features, COALESCE(image_url, '') AS image_url,
+	COALESCE(image_alt, '') AS image_alt, agent_id, created_at, updated_at`
+
+type pgPropertyRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgPropertyRepo) List(ctx context.Context, filter PropertyFilter) ([]Property, int, error) {
+	q := &pgQuery{}
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	if filter.Location != "" {
+		q.and("location ILIKE '%' || " + q.arg(escapeLike(filter.Location)) + ` || '%' ESCAPE '\'`)
+	}
+	if filter.MinPrice != nil {
+		q.and("price >= " + q.arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		q.and("price <= " + q.arg(*filter.MaxPrice))
+	}
+	if filter.MinUnits != nil {
+		q.and("units >= " + q.arg(*filter.MinUnits))
+	}
+	if filter.MaxUnits != nil {
+		q.and("units <= " + q.arg(*filter.MaxUnits))
+	}
+	if filter.MinAcres != nil {
+		q.and("acres >= " + q.arg(*filter.MinAcres))
+	}
+	if filter.MaxAcres != nil {
+		q.and("acres <= " + q.arg(*filter.MaxAcres))
+	}
+	if len(filter.Features) > 0 {
+		q.and("features @> " + q.arg(filter.Features) + "::jsonb")
+	}
+	if filter.Query != "" {
+		q.and("search_vector @@ websearch_to_tsquery('english', " + q.arg(filter.Query) + ")")
+	}
+	if filter.Near != nil {
+		center := "ll_to_earth(" + q.arg(filter.Near.Lat) + ", " + q.arg(filter.Near.Lng) + ")"
+		radius := q.arg(filter.RadiusKm * 1000)
+		// earth_box uses the GiST index, earth_distance trims its corners
+		q.and("earth_box(" + center + ", " + radius + ") @> ll_to_earth(latitude, longitude)")
+		q.and("earth_distance(" + center + ", ll_to_earth(latitude, longitude)) <= " + radius)
+	}
+	if filter.BBox != nil {
+		b := filter.BBox
+		q.and("latitude BETWEEN " + q.arg(b.MinLat) + " AND " + q.arg(b.MaxLat))
+		if b.MinLng <= b.MaxLng {
+			q.and("longitude BETWEEN " + q.arg(b.MinLng) + " AND " + q.arg(b.MaxLng))
+		} else {
+			q.and("(longitude >= " + q.arg(b.MinLng) + " OR longitude <= " + q.arg(b.MaxLng) + ")")
+		}
+	}
+
+	orderBy := "created_at"
+	switch filter.SortBy {
+	case "price", "acres":
+		orderBy = filter.SortBy
+	}
+	if filter.SortAsc {
+		orderBy += " ASC NULLS FIRST"
+	} else {
+		orderBy += " DESC NULLS LAST"
+	}
+
+	return pgList[Property](ctx, r.pool, propertyColumns, "properties", q, orderBy+", id", filter.PaginationParams)
+}
+
+func (r *pgPropertyRepo) GetByID(ctx context.Context, id string) (*Property, error) {
+	return pgGet[Property](ctx, r.pool, "SELECT "+propertyColumns+" FROM properties WHERE id = $1", id)
+}
+
+func (r *pgPropertyRepo) GetBySlug(ctx context.Context, slug string) (*Property, error) {
+	return pgGet[Property](ctx, r.pool, "SELECT "+propertyColumns+" FROM properties WHERE slug = $1", slug)
+}
+
+func (r *pgPropertyRepo) Create(ctx context.Context, p *Property) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO properties
+		(id, title, slug, description, location, price, status, units, acres, features, image_url, image_alt,
+		latitude, longitude, boundary, agent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		p.ID, p.Title, p.Slug, p.Description, p.Location, p.Price, p.Status, p.Units, p.Acres,
+		p.Features, p.ImageURL, p.ImageAlt, p.Latitude, p.Longitude, p.Boundary, p.AgentID, p.CreatedAt, p.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgPropertyRepo) Save(ctx context.Context, p *Property, sr *SlugRedirect) error {
+	property, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	var redirect *string
+	if sr != nil {
+		row, err := json.Marshal(sr)
+		if err != nil {
+			return err
+		}
+		redirect = new(string)
+		*redirect = string(row)
+	}
+	_, err = r.pool.Exec(ctx, "SELECT save_property($1::jsonb, $2::jsonb)", string(property), redirect)
+	return pgError(err)
+}
+
+func (r *pgPropertyRepo) SetInventory(ctx context.Context, id string, units int, status string) error {
+	return pgExec(ctx, r.pool, "UPDATE properties SET units = $2, status = $3, updated_at = NOW() WHERE id = $1", id, units, status)
+}
+
+func (r *pgPropertyRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM properties WHERE id = $1", id)
+}
+
+// ============ PLOTS ============
+
+const plotColumns = `id, property_id, plot_number, size_sqm, price, is_corner, status, reservation_id, created_at, updated_at`
+
+type pgPlotRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgPlotRepo) ListByProperty(ctx context.Context, propertyID, status string) ([]Plot, error) {
+	q := &pgQuery{}
+	q.and("property_id = " + q.arg(propertyID))
+	if status != "" {
+		q.and("status = " + q.arg(status))
+	}
+	return pgSelect[Plot](ctx, r.pool, "SELECT "+plotColumns+" FROM plots"+q.whereSQL()+" ORDER BY plot_number", q.args...)
+}
+
+func (r *pgPlotRepo) GetByID(ctx context.Context, id string) (*Plot, error) {
+	return pgGet[Plot](ctx, r.pool, "SELECT "+plotColumns+" FROM plots WHERE id = $1", id)
+}
+
+func (r *pgPlotRepo) CreateBatch(ctx context.Context, plots []Plot) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, p := range plots {
+			_, err := tx.Exec(ctx, `INSERT INTO plots
+				(id, property_id, plot_number, size_sqm, price, is_corner, status, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				p.ID, p.PropertyID, p.PlotNumber, p.SizeSqm, p.Price, p.IsCorner, p.Status, p.CreatedAt, p.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return pgError(err)
+}
+
+func (r *pgPlotRepo) Update(ctx context.Context, p *Plot) error {
+	err := pgExec(ctx, r.pool, `UPDATE plots SET
+		size_sqm = $2, price = $3, is_corner = $4, status = $5, updated_at = $6
+		WHERE id = $1 AND (reservation_id IS NULL OR status = $5)`,
+		p.ID, p.SizeSqm, p.Price, p.IsCorner, p.Status, p.UpdatedAt)
+	if errors.Is(err, ErrNotFound) {
+		if _, getErr := r.GetByID(ctx, p.ID); getErr == nil {
+			return ErrConflict
+		}
+	}
+	return err
+}
+
+func (r *pgPlotRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM plots WHERE id = $1", id)
+}
+
+// ============ RESERVATIONS ============
+
+const reservationColumns = `id, property_id, user_id, plot_ids, status, expires_at, created_at, updated_at`
+
+// pgReservationRepo calls the reservation functions from migrations 0016
+// and 0028, which lock and update the plots in the same statement
+type pgReservationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgReservationRepo) Create(ctx context.Context, res *Reservation) error {
+	created, err := pgGet[Reservation](ctx, r.pool, "SELECT "+reservationColumns+" FROM reserve_plots($1, $2, $3, $4, $5)",
+		res.ID, res.PropertyID, res.UserID, res.PlotIDs, res.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	*res = *created
+	return nil
+}
+
+func (r *pgReservationRepo) GetByID(ctx context.Context, id string) (*Reservation, error) {
+	return pgGet[Reservation](ctx, r.pool, "SELECT "+reservationColumns+" FROM reservations WHERE id = $1", id)
+}
+
+func (r *pgReservationRepo) List(ctx context.Context, filter ReservationFilter) ([]Reservation, int, error) {
+	q := &pgQuery{}
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	if filter.PropertyID != "" {
+		q.and("property_id = " + q.arg(filter.PropertyID))
+	}
+	if filter.UserID != "" {
+		q.and("user_id = " + q.arg(filter.UserID))
+	}
+	return pgList[Reservation](ctx, r.pool, reservationColumns, "reservations", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgReservationRepo) Complete(ctx context.Context, id, status string) (*Reservation, error) {
+	return pgGet[Reservation](ctx, r.pool, "SELECT "+reservationColumns+" FROM complete_reservation($1, $2)", id, status)
+}
+
+func (r *pgReservationRepo) Convert(ctx context.Context, id string, schedule *PaymentSchedule) (*Reservation, error) {
+	row, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, err
+	}
+	return pgGet[Reservation](ctx, r.pool, "SELECT "+reservationColumns+" FROM convert_reservation($1, $2::jsonb)", id, string(row))
+}
+
+func (r *pgReservationRepo) ExpireDue(ctx context.Context, now time.Time) ([]Reservation, error) {
+	return pgSelect[Reservation](ctx, r.pool, "SELECT "+reservationColumns+" FROM expire_reservations($1)", now)
+}
+
+// ============ PAYMENT PLANS ============
+
+const paymentPlanColumns = `id, property_id, name, deposit_percent, months, markup_percent, is_active, created_at, updated_at`
+
+type pgPaymentPlanRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgPaymentPlanRepo) ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]PaymentPlan, error) {
+	q := &pgQuery{}
+	q.and("property_id = " + q.arg(propertyID))
+	if activeOnly {
+		q.and("is_active = true")
+	}
+	return pgSelect[PaymentPlan](ctx, r.pool, "SELECT "+paymentPlanColumns+" FROM payment_plans"+q.whereSQL()+" ORDER BY months, created_at", q.args...)
+}
+
+func (r *pgPaymentPlanRepo) GetByID(ctx context.Context, id string) (*PaymentPlan, error) {
+	return pgGet[PaymentPlan](ctx, r.pool, "SELECT "+paymentPlanColumns+" FROM payment_plans WHERE id = $1", id)
+}
+
+func (r *pgPaymentPlanRepo) Create(ctx context.Context, p *PaymentPlan) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO payment_plans (`+paymentPlanColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		p.ID, p.PropertyID, p.Name, p.DepositPercent, p.Months, p.MarkupPercent, p.IsActive, p.CreatedAt, p.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgPaymentPlanRepo) Update(ctx context.Context, p *PaymentPlan) error {
+	return pgExec(ctx, r.pool, `UPDATE payment_plans SET
+		name = $2, deposit_percent = $3, months = $4, markup_percent = $5, is_active = $6, updated_at = $7
+		WHERE id = $1`,
+		p.ID, p.Name, p.DepositPercent, p.Months, p.MarkupPercent, p.IsActive, p.UpdatedAt)
+}
+
+func (r *pgPaymentPlanRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM payment_plans WHERE id = $1", id)
+}
+
+// ============ PAYMENT SCHEDULES ============
+
+const scheduleColumns = `id, user_id, property_id, reservation_id, payment_plan_id, plan_name, base_price,
+	deposit_percent, months, markup_percent, total_amount, installments, start_date, created_at`
+
+type pgScheduleRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgScheduleRepo) List(ctx context.Context, filter PaymentScheduleFilter) ([]PaymentSchedule, int, error) {
+	q := &pgQuery{}
+	if filter.UserID != "" {
+		q.and("user_id = " + q.arg(filter.UserID))
+	}
+	if filter.PropertyID != "" {
+		q.and("property_id = " + q.arg(filter.PropertyID))
+	}
+	return pgList[PaymentSchedule](ctx, r.pool, scheduleColumns, "payment_schedules", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgScheduleRepo) GetByID(ctx context.Context, id string) (*PaymentSchedule, error) {
+	return pgGet[PaymentSchedule](ctx, r.pool, "SELECT "+scheduleColumns+" FROM payment_schedules WHERE id = $1", id)
+}
+
+func (r *pgScheduleRepo) Create(ctx context.Context, s *PaymentSchedule) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO payment_schedules (`+scheduleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		s.ID, s.UserID, s.PropertyID, s.ReservationID, s.PaymentPlanID, s.PlanName, s.BasePrice,
+		s.DepositPercent, s.Months, s.MarkupPercent, s.TotalAmount, s.Installments, s.StartDate, s.CreatedAt)
+	return pgError(err)
+}
+
+// ============ PAYMENTS ============
+
+const paymentColumns = `id, schedule_id, user_id, property_id, amount, method, reference, received_at,
+	receipt_number, status, reversed_at, reversal_reason, recorded_by, created_at`
+
+type pgPaymentRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgPaymentRepo) List(ctx context.Context, filter PaymentFilter) ([]Payment, int, error) {
+	q := &pgQuery{}
+	if filter.UserID != "" {
+		q.and("user_id = " + q.arg(filter.UserID))
+	}
+	if filter.PropertyID != "" {
+		q.and("property_id = " + q.arg(filter.PropertyID))
+	}
+	if filter.ScheduleID != "" {
+		q.and("schedule_id = " + q.arg(filter.ScheduleID))
+	}
+	return pgList[Payment](ctx, r.pool, paymentColumns, "payments", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgPaymentRepo) ListBySchedule(ctx context.Context, scheduleID string) ([]Payment, error) {
+	return pgSelect[Payment](ctx, r.pool, "SELECT "+paymentColumns+" FROM payments WHERE schedule_id = $1 ORDER BY created_at", scheduleID)
+}
+
+func (r *pgPaymentRepo) GetByID(ctx context.Context, id string) (*Payment, error) {
+	return pgGet[Payment](ctx, r.pool, "SELECT "+paymentColumns+" FROM payments WHERE id = $1", id)
+}
+
+// Create leaves receipt_number to its column default, which draws from a sequence
+func (r *pgPaymentRepo) Create(ctx context.Context, p *Payment) error {
+	payment, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, "SELECT receipt_number FROM record_payment($1::jsonb)", string(payment)).Scan(&p.ReceiptNumber)
+	return pgError(err)
+}
+
+func (r *pgPaymentRepo) Reverse(ctx context.Context, id, reason string, at time.Time) (*Payment, error) {
+	payment, err := pgGet[Payment](ctx, r.pool, `UPDATE payments SET status = 'reversed', reversed_at = $2, reversal_reason = $3
+		WHERE id = $1 AND status = 'recorded'
+		RETURNING `+paymentColumns, id, at, reason)
+	if errors.Is(err, ErrNotFound) {
+		if _, getErr := r.GetByID(ctx, id); getErr == nil {
+			return nil, ErrConflict
+		}
+	}
+	return payment, err
+}
+
+// ============ BLOG POSTS ============
+
+const blogPostColumns = `id, title, slug, COALESCE(excerpt, '') AS excerpt,
+	COALESCE(content_markdown, '') AS content_markdown, content_html, table_of_contents, reading_time_minutes,
+	COALESCE(category, '') AS category, COALESCE(tags, '[]'::jsonb) AS tags,
+	COALESCE(image_url, '') AS image_url, COALESCE(image_alt, '') AS image_alt,
+	COALESCE(author, '') AS author, COALESCE(published, false) AS published, status, publish_at,
+	published_at, announce, announcement_id, created_at, updated_at`
+
+type pgBlogRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgBlogRepo) List(ctx context.Context, filter BlogFilter) ([]BlogPost, int, error) {
+	q := &pgQuery{}
+	orderBy := "created_at DESC"
+	if filter.PublishedOnly {
+		q.and("status = 'published'")
+		orderBy = "published_at DESC, created_at DESC"
+	}
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	if filter.Category != "" {
+		q.and("category ILIKE " + q.arg(escapeLike(filter.Category)) + ` ESCAPE '\'`)
+	}
+	return pgList[BlogPost](ctx, r.pool, blogPostColumns, "blog_posts", q, orderBy, filter.PaginationParams)
+}
+
+func (r *pgBlogRepo) GetByID(ctx context.Context, id string) (*BlogPost, error) {
+	return pgGet[BlogPost](ctx, r.pool, "SELECT "+blogPostColumns+" FROM blog_posts WHERE id = $1", id)
+}
+
+func (r *pgBlogRepo) GetBySlug(ctx context.Context, slug string) (*BlogPost, error) {
+	return pgGet[BlogPost](ctx, r.pool, "SELECT "+blogPostColumns+" FROM blog_posts WHERE slug = $1", slug)
+}
+
+// Create and Save go through functions that write the post, redirect and
+// revision in one transaction
+func (r *pgBlogRepo) Create(ctx context.Context, p *BlogPost, rev *BlogRevision) error {
+	post, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	revision, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, "SELECT revision FROM create_blog_post($1::jsonb, $2::jsonb)",
+		string(post), string(revision)).Scan(&rev.Revision)
+	return pgError(err)
+}
+
+func (r *pgBlogRepo) Save(ctx context.Context, p *BlogPost, sr *SlugRedirect, rev *BlogRevision) error {
+	post, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	revision, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	var redirect *string
+	if sr != nil {
+		row, err := json.Marshal(sr)
+		if err != nil {
+			return err
+		}
+		redirect = new(string)
+		*redirect = string(row)
+	}
+	err = r.pool.QueryRow(ctx, "SELECT revision FROM save_blog_post($1::jsonb, $2::jsonb, $3::jsonb)",
+		string(post), redirect, string(revision)).Scan(&rev.Revision)
+	return pgError(err)
+}
+
+func (r *pgBlogRepo) Update(ctx context.Context, p *BlogPost) error {
+	return pgExec(ctx, r.pool, `UPDATE blog_posts SET
+		title = $2, slug = $3, excerpt = $4, content_markdown = $5, content_html = $6, table_of_contents = $7,
+		reading_time_minutes = $8, category = $9, tags = $10, image_url = $11, image_alt = $12, author = $13,
+		announce = $14, updated_at = $15
+		WHERE id = $1`,
+		p.ID, p.Title, p.Slug, p.Excerpt, p.ContentMarkdown, p.ContentHTML, p.TableOfContents, p.ReadingTimeMinutes,
+		p.Category, p.Tags, p.ImageURL, p.ImageAlt, p.Author, p.Announce, p.UpdatedAt)
+}
+
+func (r *pgBlogRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM blog_posts WHERE id = $1", id)
+}
+
+func (r *pgBlogRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, "SELECT transition_blog_post($1, $2, $3, $4)", id, from, to, at)
+	return pgError(err)
+}
+
+func (r *pgBlogRepo) Due(ctx context.Context, now time.Time) ([]BlogPost, error) {
+	return pgSelect[BlogPost](ctx, r.pool, "SELECT "+blogPostColumns+` FROM blog_posts
+		WHERE status = 'scheduled' AND publish_at <= $1 ORDER BY publish_at`, now)
+}
+
+func (r *pgBlogRepo) Announce(ctx context.Context, id string, c *Campaign) error {
+	campaign, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, "SELECT announce_blog_post($1, $2::jsonb)", id, string(campaign))
+	return pgError(err)
+}
+
+// ============ BLOG REVISIONS ============
+
+const blogRevisionColumns = `id, post_id, revision, title, excerpt, content_markdown, category, tags, image_url, image_alt,
+	author, edited_by, editor_email, restored_from, created_at`
+
+type pgBlogRevisionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgBlogRevisionRepo) List(ctx context.Context, postID string, page PaginationParams) ([]BlogRevision, int, error) {
+	q := &pgQuery{}
+	q.and("post_id = " + q.arg(postID))
+	return pgList[BlogRevision](ctx, r.pool, blogRevisionColumns, "blog_revisions", q, "revision DESC", page)
+}
+
+func (r *pgBlogRevisionRepo) Get(ctx context.Context, postID string, number int) (*BlogRevision, error) {
+	return pgGet[BlogRevision](ctx, r.pool, "SELECT "+blogRevisionColumns+
+		" FROM blog_revisions WHERE post_id = $1 AND revision = $2", postID, number)
+}
+
+// Create goes through a function that numbers the revision while holding a
+// lock on the post
+func (r *pgBlogRevisionRepo) Create(ctx context.Context, rev *BlogRevision) error {
+	err := r.pool.QueryRow(ctx, "SELECT revision FROM add_blog_revision($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		rev.ID, rev.PostID, rev.Title, rev.Excerpt, rev.ContentMarkdown, rev.Category, rev.Tags, rev.ImageURL, rev.ImageAlt,
+		rev.Author, rev.EditedBy, rev.EditorEmail, rev.RestoredFrom, rev.CreatedAt).Scan(&rev.Revision)
+	return pgError(err)
+}
+
+// ============ CONTACT SUBMISSIONS ============
+
+const contactColumns = `id, first_name, last_name, email, phone, message, property_id,
+	COALESCE(is_read, false) AS is_read, created_at`
+
+type pgContactRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgContactRepo) List(ctx context.Context, filter ContactFilter) ([]ContactSubmission, int, error) {
+	q := &pgQuery{}
+	if filter.UnreadOnly {
+		q.and("is_read = false")
+	}
+	return pgList[ContactSubmission](ctx, r.pool, contactColumns, "contact_submissions", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgContactRepo) GetByID(ctx context.Context, id string) (*ContactSubmission, error) {
+	return pgGet[ContactSubmission](ctx, r.pool, "SELECT "+contactColumns+" FROM contact_submissions WHERE id = $1", id)
+}
+
+func (r *pgContactRepo) Create(ctx context.Context, s *ContactSubmission) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO contact_submissions
+		(id, first_name, last_name, email, phone, message, property_id, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		s.ID, s.FirstName, s.LastName, s.Email, s.Phone, s.Message, s.PropertyID, s.IsRead, s.CreatedAt)
+	return pgError(err)
+}
+
+func (r *pgContactRepo) MarkRead(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "UPDATE contact_submissions SET is_read = true WHERE id = $1", id)
+}
+
+// ============ NEWSLETTER SUBSCRIBERS ============
+
+// Subscribers are read through the subscriber_profiles view, which adds the
+// linked account and its favorites, and written to newsletter_subscribers
+const subscriberColumns = `id, email, COALESCE(name, '') AS name, status, COALESCE(active, false) AS active,
+	created_at, confirmed_at, unsub_at, confirmation_sent_at, tracking_opt_out, source, tags, interests, user_id,
+	favorite_property_ids`
+
+type pgNewsletterRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgNewsletterRepo) List(ctx context.Context, filter NewsletterFilter) ([]NewsletterSubscriber, int, error) {
+	q := &pgQuery{}
+	if filter.ActiveOnly {
+		q.and("active = true")
+	}
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	return pgList[NewsletterSubscriber](ctx, r.pool, subscriberColumns, "subscriber_profiles", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgNewsletterRepo) GetByEmail(ctx context.Context, email string) (*NewsletterSubscriber, error) {
+	return pgGet[NewsletterSubscriber](ctx, r.pool, "SELECT "+subscriberColumns+" FROM subscriber_profiles WHERE LOWER(email) = LOWER($1)", email)
+}
+
+func (r *pgNewsletterRepo) Create(ctx context.Context, s *NewsletterSubscriber) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO newsletter_subscribers
+		(id, email, name, status, active, created_at, confirmed_at, unsub_at, confirmation_sent_at, tracking_opt_out,
+		source, tags, interests)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		s.ID, s.Email, s.Name, s.Status, s.Active, s.CreatedAt, s.ConfirmedAt, s.UnsubAt, s.ConfirmationSentAt,
+		s.TrackingOptOut, s.Source, s.Tags, s.Interests)
+	return pgError(err)
+}
+
+func (r *pgNewsletterRepo) Update(ctx context.Context, s *NewsletterSubscriber) error {
+	return pgExec(ctx, r.pool, `UPDATE newsletter_subscribers SET
+		email = $2, name = $3, status = $4, active = $5, confirmed_at = $6, unsub_at = $7, confirmation_sent_at = $8,
+		tracking_opt_out = $9, tags = $10, interests = $11
+		WHERE id = $1`,
+		s.ID, s.Email, s.Name, s.Status, s.Active, s.ConfirmedAt, s.UnsubAt, s.ConfirmationSentAt, s.TrackingOptOut,
+		s.Tags, s.Interests)
+}
+
+// ============ NEWSLETTER SEGMENTS ============
+
+const segmentColumns = `id, name, description, filter, created_by, created_at, updated_at`
+
+type pgSegmentRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgSegmentRepo) List(ctx context.Context, page PaginationParams) ([]Segment, int, error) {
+	return pgList[Segment](ctx, r.pool, segmentColumns, "newsletter_segments", &pgQuery{}, "name", page)
+}
+
+func (r *pgSegmentRepo) GetByID(ctx context.Context, id string) (*Segment, error) {
+	return pgGet[Segment](ctx, r.pool, "SELECT "+segmentColumns+" FROM newsletter_segments WHERE id = $1", id)
+}
+
+func (r *pgSegmentRepo) Create(ctx context.Context, s *Segment) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO newsletter_segments (`+segmentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		s.ID, s.Name, s.Description, s.Filter, s.CreatedBy, s.CreatedAt, s.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgSegmentRepo) Update(ctx context.Context, s *Segment) error {
+	return pgExec(ctx, r.pool, `UPDATE newsletter_segments SET
+		name = $2, description = $3, filter = $4, updated_at = $5
+		WHERE id = $1`,
+		s.ID, s.Name, s.Description, s.Filter, s.UpdatedAt)
+}
+
+func (r *pgSegmentRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM newsletter_segments WHERE id = $1", id)
+}
+
+// ============ NEWSLETTER CAMPAIGNS ============
+
+const campaignColumns = `id, subject, html_body, text_body, segment, status, scheduled_at, started_at,
+	completed_at, recipient_count, sent_count, failed_count, skipped_count, created_by, created_at, updated_at`
+
+const recipientColumns = `id, campaign_id, subscriber_id, email, status, attempts, error, sent_at, created_at`
+
+type pgCampaignRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgCampaignRepo) List(ctx context.Context, filter CampaignFilter) ([]Campaign, int, error) {
+	q := &pgQuery{}
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	if filter.Segment != "" {
+		q.and("segment = " + q.arg(filter.Segment))
+	}
+	return pgList[Campaign](ctx, r.pool, campaignColumns, "campaign_overview", q, "created_at DESC", filter.PaginationParams)
+}
+
+func (r *pgCampaignRepo) GetByID(ctx context.Context, id string) (*Campaign, error) {
+	return pgGet[Campaign](ctx, r.pool, "SELECT "+campaignColumns+" FROM campaign_overview WHERE id = $1", id)
+}
+
+func (r *pgCampaignRepo) Create(ctx context.Context, c *Campaign) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO newsletter_campaigns
+		(id, subject, html_body, text_body, segment, status, scheduled_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		c.ID, c.Subject, c.HTMLBody, c.TextBody, c.Segment, c.Status, c.ScheduledAt, c.CreatedBy, c.CreatedAt, c.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgCampaignRepo) Update(ctx context.Context, c *Campaign) error {
+	return pgExec(ctx, r.pool, `UPDATE newsletter_campaigns SET
+		subject = $2, html_body = $3, text_body = $4, segment = $5, updated_at = $6 WHERE id = $1`,
+		c.ID, c.Subject, c.HTMLBody, c.TextBody, c.Segment, c.UpdatedAt)
+}
+
+func (r *pgCampaignRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM newsletter_campaigns WHERE id = $1", id)
+}
+
+func (r *pgCampaignRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, "SELECT transition_campaign($1, $2, $3, $4)", id, from, to, at)
+	return pgError(err)
+}
+
+func (r *pgCampaignRepo) Due(ctx context.Context, now time.Time) ([]Campaign, error) {
+	return pgSelect[Campaign](ctx, r.pool, "SELECT "+campaignColumns+` FROM campaign_overview
+		WHERE status = 'sending' OR (status = 'scheduled' AND scheduled_at <= $1)
+		ORDER BY scheduled_at`, now)
+}
+
+func (r *pgCampaignRepo) AddRecipients(ctx context.Context, campaignID string, recipients []CampaignRecipient) error {
+	subscriberIDs := make([]*string, len(recipients))
+	emails := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		subscriberIDs[i] = recipient.SubscriberID
+		emails[i] = recipient.Email
+	}
+	_, err := r.pool.Exec(ctx, "SELECT add_campaign_recipients($1, $2, $3)", campaignID, subscriberIDs, emails)
+	return pgError(err)
+}
+
+func (r *pgCampaignRepo) PendingRecipients(ctx context.Context, campaignID string, limit int) ([]CampaignRecipient, error) {
+	return pgSelect[CampaignRecipient](ctx, r.pool, "SELECT "+recipientColumns+` FROM campaign_recipients
+		WHERE campaign_id = $1 AND status = 'pending' ORDER BY attempts, email LIMIT $2`, campaignID, limit)
+}
+
+func (r *pgCampaignRepo) GetRecipient(ctx context.Context, id string) (*CampaignRecipient, error) {
+	return pgGet[CampaignRecipient](ctx, r.pool, "SELECT "+recipientColumns+" FROM campaign_recipients WHERE id = $1", id)
+}
+
+func (r *pgCampaignRepo) UpdateRecipient(ctx context.Context, rc *CampaignRecipient) error {
+	return pgExec(ctx, r.pool, `UPDATE campaign_recipients SET
+		status = $2, attempts = $3, error = $4, sent_at = $5 WHERE id = $1`,
+		rc.ID, rc.Status, rc.Attempts, rc.Error, rc.SentAt)
+}
+
+func (r *pgCampaignRepo) ListRecipients(ctx context.Context, filter CampaignRecipientFilter) ([]CampaignRecipient, int, error) {
+	q := &pgQuery{}
+	q.and("campaign_id = " + q.arg(filter.CampaignID))
+	if filter.Status != "" {
+		q.and("status = " + q.arg(filter.Status))
+	}
+	return pgList[CampaignRecipient](ctx, r.pool, recipientColumns, "campaign_recipients", q, "email", filter.PaginationParams)
+}
+
+func (r *pgCampaignRepo) RecordEvent(ctx context.Context, e *CampaignEvent) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO campaign_events
+		(id, campaign_id, recipient_id, type, url, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.ID, e.CampaignID, e.RecipientID, e.Type, e.URL, e.CreatedAt)
+	return pgError(err)
+}
+
+func (r *pgCampaignRepo) EventStats(ctx context.Context, campaignID string) (*CampaignStats, error) {
+	return pgGet[CampaignStats](ctx, r.pool, "SELECT opens, unique_opens, clicks, unique_clicks FROM campaign_event_stats($1)", campaignID)
+}
+
+func (r *pgCampaignRepo) TopLinks(ctx context.Context, campaignID string, limit int) ([]CampaignLinkStats, error) {
+	return pgSelect[CampaignLinkStats](ctx, r.pool, "SELECT url, clicks, unique_clicks FROM campaign_top_links($1, $2)", campaignID, limit)
+}
+
+// ============ USERS ============
+
+const userColumns = `id, email, COALESCE(password, '') AS password,
+	COALESCE(first_name, '') AS first_name, COALESCE(last_name, '') AS last_name,
+	COALESCE(role, 'user') AS role, COALESCE(is_active, true) AS is_active, created_at, updated_at`
+
+type pgUserRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgUserRepo) List(ctx context.Context, page PaginationParams) ([]User, int, error) {
+	return pgList[User](ctx, r.pool, userColumns, "users", &pgQuery{}, "created_at DESC", page)
+}
+
+func (r *pgUserRepo) GetByID(ctx context.Context, id string) (*User, error) {
+	return pgGet[User](ctx, r.pool, "SELECT "+userColumns+" FROM users WHERE id = $1", id)
+}
+
+func (r *pgUserRepo) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return pgGet[User](ctx, r.pool, "SELECT "+userColumns+" FROM users WHERE LOWER(email) = LOWER($1)", email)
+}
+
+func (r *pgUserRepo) Create(ctx context.Context, u *User) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO users
+		(id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		u.ID, u.Email, u.Password, u.FirstName, u.LastName, u.Role, u.IsActive, u.CreatedAt, u.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgUserRepo) Update(ctx context.Context, u *User) error {
+	return pgExec(ctx, r.pool, `UPDATE users SET
+		email = $2, password = $3, first_name = $4, last_name = $5, role = $6, is_active = $7, updated_at = $8
+		WHERE id = $1`,
+		u.ID, u.Email, u.Password, u.FirstName, u.LastName, u.Role, u.IsActive, u.UpdatedAt)
+}
+
+func (r *pgUserRepo) Delete(ctx context.Context, id string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM users WHERE id = $1", id)
+}
+
+// ============ REVIEWS ============
+
+const reviewColumns = `id, user_id, property_id, rating, COALESCE(comment, '') AS comment, created_at, updated_at`
+
+type pgReviewRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgReviewRepo) ListByUser(ctx context.Context, userID string) ([]Review, error) {
+	return pgSelect[Review](ctx, r.pool, "SELECT "+reviewColumns+" FROM reviews WHERE user_id = $1 ORDER BY created_at DESC", userID)
+}
+
+func (r *pgReviewRepo) ListByProperty(ctx context.Context, propertyID string) ([]Review, error) {
+	return pgSelect[Review](ctx, r.pool, "SELECT "+reviewColumns+" FROM reviews WHERE property_id = $1 ORDER BY created_at DESC", propertyID)
+}
+
+func (r *pgReviewRepo) Create(ctx context.Context, rv *Review) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO reviews
+		(id, user_id, property_id, rating, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		rv.ID, rv.UserID, rv.PropertyID, rv.Rating, rv.Comment, rv.CreatedAt, rv.UpdatedAt)
+	return pgError(err)
+}
+
+// ============ FAVORITES ============
+
+type pgFavoriteRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgFavoriteRepo) ListByUser(ctx context.Context, userID string) ([]Favorite, error) {
+	return pgSelect[Favorite](ctx, r.pool, `SELECT id, user_id, property_id, created_at
+		FROM favorites WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+func (r *pgFavoriteRepo) Add(ctx context.Context, f *Favorite) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO favorites (id, user_id, property_id, created_at)
+		VALUES ($1, $2, $3, $4)`, f.ID, f.UserID, f.PropertyID, f.CreatedAt)
+	return pgError(err)
+}
+
+func (r *pgFavoriteRepo) Remove(ctx context.Context, userID, propertyID string) error {
+	return pgExec(ctx, r.pool, "DELETE FROM favorites WHERE user_id = $1 AND property_id = $2", userID, propertyID)
+}
+
+// ============ BROCHURE REQUESTS ============
+
+const brochureColumns = `id, user_id, email, property_id, download_count, last_downloaded_at, created_at`
+
+type pgBrochureRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgBrochureRepo) Create(ctx context.Context, b *BrochureRequest) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO brochure_requests (id, user_id, email, property_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)`, b.ID, b.UserID, b.Email, b.PropertyID, b.CreatedAt)
+	return pgError(err)
+}
+
+func (r *pgBrochureRepo) GetByID(ctx context.Context, id string) (*BrochureRequest, error) {
+	return pgGet[BrochureRequest](ctx, r.pool, "SELECT "+brochureColumns+" FROM brochure_requests WHERE id = $1", id)
+}
+
+func (r *pgBrochureRepo) RecordDownload(ctx context.Context, id string, at time.Time) error {
+	return pgExec(ctx, r.pool, `UPDATE brochure_requests SET download_count = download_count + 1, last_downloaded_at = $2
+		WHERE id = $1`, id, at)
+}
+
+func (r *pgBrochureRepo) Stats(ctx context.Context, limit int) ([]BrochureStats, error) {
+	var maxRows *int
+	if limit > 0 {
+		maxRows = &limit
+	}
+	return pgSelect[BrochureStats](ctx, r.pool, "SELECT property_id, property_title, requests, downloads FROM brochure_stats($1)", maxRows)
+}
+
+// ============ LEADS ============
+
+const leadColumns = `id, email, source, user_id, request_count, created_at, last_seen_at`
+
+type pgLeadRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgLeadRepo) List(ctx context.Context, filter LeadFilter) ([]Lead, int, error) {
+	q := &pgQuery{}
+	if filter.Source != "" {
+		q.and("source = " + q.arg(filter.Source))
+	}
+	if filter.Search != "" {
+		q.and("email ILIKE " + q.arg("%"+escapeLike(filter.Search)+"%") + ` ESCAPE '\'`)
+	}
+	return pgList[Lead](ctx, r.pool, leadColumns, "leads", q, "last_seen_at DESC", filter.PaginationParams)
+}
+
+func (r *pgLeadRepo) Capture(ctx context.Context, lead *Lead) error {
+	captured, err := pgGet[Lead](ctx, r.pool, "SELECT "+leadColumns+" FROM capture_lead($1, $2, $3, $4, $5)",
+		lead.ID, lead.Email, lead.Source, lead.UserID, lead.LastSeenAt)
+	if err != nil {
+		return err
+	}
+	*lead = *captured
+	return nil
+}
+
+// ============ SESSIONS ============
+
+const sessionColumns = `id, user_id, refresh_jti, expires_at, revoked_at, created_at, updated_at`
+
+type pgSessionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgSessionRepo) Create(ctx context.Context, s *Session) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO sessions
+		(id, user_id, refresh_jti, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		s.ID, s.UserID, s.RefreshJTI, s.ExpiresAt, s.CreatedAt, s.UpdatedAt)
+	return pgError(err)
+}
+
+func (r *pgSessionRepo) GetByID(ctx context.Context, id string) (*Session, error) {
+	return pgGet[Session](ctx, r.pool, "SELECT "+sessionColumns+" FROM sessions WHERE id = $1", id)
+}
+
+func (r *pgSessionRepo) Rotate(ctx context.Context, id, oldJTI, newJTI string, expiresAt time.Time) error {
+	return pgExec(ctx, r.pool, `UPDATE sessions SET refresh_jti = $3, expires_at = $4, updated_at = NOW()
+		WHERE id = $1 AND refresh_jti = $2 AND revoked_at IS NULL`, id, oldJTI, newJTI, expiresAt)
+}
+
+func (r *pgSessionRepo) Revoke(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE sessions SET revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL`, id)
+	return pgError(err)
+}
+
+func (r *pgSessionRepo) RevokeByUser(ctx context.Context, userID string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE sessions SET revoked_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return pgError(err)
+}
+
+// ============ SLUG REDIRECTS ============
+
+type pgSlugRedirectRepo struct {
+	pool *pgxpool.Pool
+}
+
+func (r *pgSlugRedirectRepo) Save(ctx context.Context, sr *SlugRedirect) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO slug_redirects (id, entity_type, old_slug, entity_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = EXCLUDED.created_at`,
+		sr.ID, sr.EntityType, sr.OldSlug, sr.EntityID, sr.CreatedAt)
+	return pgError(err)
+}
+
+func (r *pgSlugRedirectRepo) Get(ctx context.Context, entityType, slug string) (*SlugRedirect, error) {
+	return pgGet[SlugRedirect](ctx, r.pool, `SELECT id, entity_type, old_slug, entity_id, created_at
+		FROM slug_redirects WHERE entity_type = $1 AND old_slug = $2`, entityType, slug)
+}
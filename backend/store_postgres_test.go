@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Lekki", "Lekki"},
+		{"100%", `100\%`},
+		{"plot_1", `plot\_1`},
+		{`a\b`, `a\\b`},
+		{`%_\`, `\%\_\\`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPgError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no rows", pgx.ErrNoRows, ErrNotFound},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, ErrConflict},
+		{"raised not found", &pgconn.PgError{Code: sqlStateNotFound}, ErrNotFound},
+		{"raised conflict", &pgconn.PgError{Code: sqlStateConflict}, ErrConflict},
+	}
+	for _, tt := range tests {
+		if got := pgError(tt.err); !errors.Is(got, tt.want) {
+			t.Errorf("%s: pgError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Bad input other than an ID, such as a malformed JSON value, is a real
+	// error rather than a missing record
+	bad := &pgconn.PgError{Code: "22P02", Message: "invalid input syntax for type json"}
+	if got := pgError(bad); errors.Is(got, ErrNotFound) || errors.Is(got, ErrConflict) {
+		t.Errorf("22P02: pgError = %v, want the error itself", got)
+	}
+}
+
+// ilikeMatch reports whether s matches the ILIKE pattern with ESCAPE '\',
+// the way Postgres evaluates the patterns built with escapeLike
+func ilikeMatch(s, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '%':
+			expr.WriteString(".*")
+		case c == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
+
+// TestLiteralLocationFilter checks that each store matches a location
+// filter as a literal case-insensitive substring, wildcards included
+func TestLiteralLocationFilter(t *testing.T) {
+	ctx := context.Background()
+	locations := map[string]string{
+		"star":    "Plot 5* Lekki",
+		"percent": "50% Ajah",
+		"under":   "Block_A Ikoyi",
+		"slash":   `Lagos\Island`,
+		"plain":   "Plot 57 Lekki",
+	}
+	memory := NewMemoryStore()
+	for id, location := range locations {
+		if err := memory.Properties.Create(ctx, &Property{ID: id, Slug: id, Location: location}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"5*", []string{"star"}},
+		{"plot 5", []string{"plain", "star"}},
+		{"50%", []string{"percent"}},
+		{"k_a", []string{"under"}},
+		{`s\i`, []string{"slash"}},
+		{"*", []string{"star"}},
+		{"%", []string{"percent"}},
+		{"_", []string{"under"}},
+		{".", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			found, _, err := memory.Properties.List(ctx, PropertyFilter{
+				PaginationParams: PaginationParams{Page: 1, Limit: 10},
+				Location:         tt.filter,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string][]string{}
+			for _, p := range found {
+				got["memory"] = append(got["memory"], p.ID)
+			}
+
+			pgPattern := "%" + escapeLike(tt.filter) + "%"
+			postgrest := regexp.MustCompile("(?i)" + postgrestPattern(tt.filter))
+			for id, location := range locations {
+				if ilikeMatch(location, pgPattern) {
+					got["postgres"] = append(got["postgres"], id)
+				}
+				if postgrest.MatchString(location) {
+					got["supabase"] = append(got["supabase"], id)
+				}
+			}
+			for _, store := range []string{"memory", "postgres", "supabase"} {
+				sort.Strings(got[store])
+				if !reflect.DeepEqual(got[store], tt.want) {
+					t.Errorf("%s store matched %v, want %v", store, got[store], tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,1369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	postgrest "github.com/supabase-community/postgrest-go"
+	"github.com/supabase-community/supabase-go"
+)
+
+// NewSupabaseStore returns a Store that reads and writes through the
+// Supabase PostgREST API. The postgrest client has no context support, so
+// request contexts are accepted for interface parity only.
+func NewSupabaseStore(client *supabase.Client) *Store {
+	return &Store{
+		Properties:    &supabasePropertyRepo{client: client},
+		Plots:         &supabasePlotRepo{client: client},
+		Reservations:  &supabaseReservationRepo{client: client},
+		PaymentPlans:  &supabasePaymentPlanRepo{client: client},
+		Schedules:     &supabaseScheduleRepo{client: client},
+		Payments:      &supabasePaymentRepo{client: client},
+		Blog:          &supabaseBlogRepo{client: client},
+		BlogRevisions: &supabaseBlogRevisionRepo{client: client},
+		Contacts:      &supabaseContactRepo{client: client},
+		Newsletter:    &supabaseNewsletterRepo{client: client},
+		Segments:      &supabaseSegmentRepo{client: client},
+		Campaigns:     &supabaseCampaignRepo{client: client},
+		Users:         &supabaseUserRepo{client: client},
+		Reviews:       &supabaseReviewRepo{client: client},
+		Favorites:     &supabaseFavoriteRepo{client: client},
+		Brochures:     &supabaseBrochureRepo{client: client},
+		Leads:         &supabaseLeadRepo{client: client},
+		Sessions:      &supabaseSessionRepo{client: client},
+		SlugRedirects: &supabaseSlugRedirectRepo{client: client},
+	}
+}
+
+// supabaseError maps PostgREST error codes onto repository errors
+func supabaseError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "(PGRST116)"):
+		return ErrNotFound
+	case strings.Contains(msg, "(23505)"):
+		return ErrConflict
+	}
+	return err
+}
+
+// postgrestPattern quotes s as a regular expression for the imatch filter.
+// PostgREST turns every * of an ilike pattern into a wildcard and has no
+// way to escape it, so literal case-insensitive matches use imatch instead.
+func postgrestPattern(s string) string {
+	return regexp.QuoteMeta(s)
+}
+
+// supabaseRPC calls a Postgres function through PostgREST and decodes its
+// JSON result into out. Errors raised by the function are mapped like pgError.
+func supabaseRPC(client *supabase.Client, name string, params, out interface{}) error {
+	body := client.Rpc(name, "", params)
+	if body == "" {
+		return fmt.Errorf("rpc %s: no response", name)
+	}
+	var rpcErr struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal([]byte(body), &rpcErr) == nil && rpcErr.Code != "" {
+		switch rpcErr.Code {
+		case sqlStateNotFound:
+			return ErrNotFound
+		case sqlStateConflict, "23505":
+			return ErrConflict
+		}
+		return fmt.Errorf("rpc %s: %s (%s)", name, rpcErr.Message, rpcErr.Code)
+	}
+	return json.Unmarshal([]byte(body), out)
+}
+
+// supabasePage applies newest-first ordering and pagination to a query
+func supabasePage(query *postgrest.FilterBuilder, page PaginationParams) *postgrest.FilterBuilder {
+	return supabaseSortedPage(query, "created_at", false, page)
+}
+
+// supabaseSortedPage orders a query by column and applies pagination
+func supabaseSortedPage(query *postgrest.FilterBuilder, column string, ascending bool, page PaginationParams) *postgrest.FilterBuilder {
+	query = query.Order(column, &postgrest.OrderOpts{Ascending: ascending})
+	if page.Limit > 0 {
+		offset := page.Offset()
+		query = query.Range(offset, offset+page.Limit-1, "")
+	}
+	return query
+}
+
+// supabaseSingle fetches exactly one row matching column = value
+func supabaseSingle(client *supabase.Client, table, column, value string, out interface{}) error {
+	_, err := client.From(table).Select("*", "", false).Eq(column, value).Single().ExecuteTo(out)
+	return supabaseError(err)
+}
+
+// supabaseInsert inserts a row, returning ErrConflict on unique violations
+func supabaseInsert(client *supabase.Client, table string, row interface{}) error {
+	_, _, err := client.From(table).Insert(row, false, "", "minimal", "").Execute()
+	return supabaseError(err)
+}
+
+// supabaseUpdate replaces the row with the given id, returning ErrNotFound if none matched
+func supabaseUpdate(client *supabase.Client, table, id string, row interface{}) error {
+	var updated []map[string]interface{}
+	_, err := client.From(table).Update(row, "representation", "").Eq("id", id).ExecuteTo(&updated)
+	if err != nil {
+		return supabaseError(err)
+	}
+	if len(updated) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// supabaseDelete removes rows matching all filters, returning ErrNotFound if none matched
+func supabaseDelete(client *supabase.Client, table string, filters map[string]string) error {
+	var deleted []map[string]interface{}
+	_, err := client.From(table).Delete("representation", "").Match(filters).ExecuteTo(&deleted)
+	if err != nil {
+		return supabaseError(err)
+	}
+	if len(deleted) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ============ PROPERTIES ============
+
+type supabasePropertyRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabasePropertyRepo) List(ctx context.Context, filter PropertyFilter) ([]Property, int, error) {
+	properties := []Property{}
+	query := r.client.From("properties").Select("*", "exact", false)
+
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	if filter.Location != "" {
+		query = query.Filter("location", "imatch", postgrestPattern(filter.Location))
+	}
+	if len(filter.Features) > 0 {
+		features, err := json.Marshal(filter.Features)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Filter("features", "cs", string(features))
+	}
+	if filter.Query != "" {
+		query = query.TextSearch("search_vector", filter.Query, "english", "websearch")
+	}
+
+	// PostgREST keys filters by column, so ranges are combined into one and=()
+	var ranges []string
+	addRange := func(column, op, bound string) {
+		ranges = append(ranges, column+"."+op+"."+bound)
+	}
+	if filter.MinPrice != nil {
+		addRange("price", "gte", formatFloat(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		addRange("price", "lte", formatFloat(*filter.MaxPrice))
+	}
+	if filter.MinUnits != nil {
+		addRange("units", "gte", strconv.Itoa(*filter.MinUnits))
+	}
+	if filter.MaxUnits != nil {
+		addRange("units", "lte", strconv.Itoa(*filter.MaxUnits))
+	}
+	if filter.MinAcres != nil {
+		addRange("acres", "gte", formatFloat(*filter.MinAcres))
+	}
+	if filter.MaxAcres != nil {
+		addRange("acres", "lte", formatFloat(*filter.MaxAcres))
+	}
+	addBox := func(b BoundingBox) {
+		addRange("latitude", "gte", formatFloat(b.MinLat))
+		addRange("latitude", "lte", formatFloat(b.MaxLat))
+		if b.MinLng <= b.MaxLng {
+			addRange("longitude", "gte", formatFloat(b.MinLng))
+			addRange("longitude", "lte", formatFloat(b.MaxLng))
+		} else {
+			ranges = append(ranges, "or(longitude.gte."+formatFloat(b.MinLng)+",longitude.lte."+formatFloat(b.MaxLng)+")")
+		}
+	}
+	if filter.BBox != nil {
+		addBox(*filter.BBox)
+	}
+	if filter.Near != nil {
+		addBox(boundingBoxAround(*filter.Near, filter.RadiusKm))
+	}
+	if len(ranges) > 0 {
+		query = query.And(strings.Join(ranges, ","), "")
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+
+	// PostgREST cannot filter on distance, so radius searches fetch the
+	// bounding box and apply the exact distance check and paging here
+	page := filter.PaginationParams
+	if filter.Near != nil {
+		page = PaginationParams{}
+	}
+	count, err := supabaseSortedPage(query, sortBy, filter.SortAsc, page).ExecuteTo(&properties)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	if filter.Near == nil {
+		return properties, int(count), nil
+	}
+
+	nearby := make([]Property, 0, len(properties))
+	for _, p := range properties {
+		if p.Latitude != nil && p.Longitude != nil &&
+			HaversineKm(*filter.Near, GeoPoint{Lat: *p.Latitude, Lng: *p.Longitude}) <= filter.RadiusKm {
+			nearby = append(nearby, p)
+		}
+	}
+	return paginate(nearby, filter.PaginationParams), len(nearby), nil
+}
+
+// formatFloat renders a filter bound without exponent notation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (r *supabasePropertyRepo) GetByID(ctx context.Context, id string) (*Property, error) {
+	var property Property
+	if err := supabaseSingle(r.client, "properties", "id", id, &property); err != nil {
+		return nil, err
+	}
+	return &property, nil
+}
+
+func (r *supabasePropertyRepo) GetBySlug(ctx context.Context, slug string) (*Property, error) {
+	var property Property
+	if err := supabaseSingle(r.client, "properties", "slug", slug, &property); err != nil {
+		return nil, err
+	}
+	return &property, nil
+}
+
+func (r *supabasePropertyRepo) Create(ctx context.Context, property *Property) error {
+	return supabaseInsert(r.client, "properties", property)
+}
+
+func (r *supabasePropertyRepo) Save(ctx context.Context, property *Property, redirect *SlugRedirect) error {
+	var saved []Property
+	return supabaseRPC(r.client, "save_property", map[string]interface{}{
+		"p_property": property,
+		"p_redirect": redirect,
+	}, &saved)
+}
+
+func (r *supabasePropertyRepo) SetInventory(ctx context.Context, id string, units int, status string) error {
+	return supabaseUpdate(r.client, "properties", id, map[string]interface{}{
+		"units":      units,
+		"status":     status,
+		"updated_at": time.Now(),
+	})
+}
+
+func (r *supabasePropertyRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "properties", map[string]string{"id": id})
+}
+
+// ============ PLOTS ============
+
+type supabasePlotRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabasePlotRepo) ListByProperty(ctx context.Context, propertyID, status string) ([]Plot, error) {
+	plots := []Plot{}
+	query := r.client.From("plots").Select("*", "", false).Eq("property_id", propertyID)
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+	if _, err := query.Order("plot_number", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&plots); err != nil {
+		return nil, supabaseError(err)
+	}
+	return plots, nil
+}
+
+func (r *supabasePlotRepo) GetByID(ctx context.Context, id string) (*Plot, error) {
+	var plot Plot
+	if err := supabaseSingle(r.client, "plots", "id", id, &plot); err != nil {
+		return nil, err
+	}
+	return &plot, nil
+}
+
+// CreateBatch sends every plot in one request, which PostgREST inserts in a single statement
+func (r *supabasePlotRepo) CreateBatch(ctx context.Context, plots []Plot) error {
+	return supabaseInsert(r.client, "plots", plots)
+}
+
+func (r *supabasePlotRepo) Update(ctx context.Context, plot *Plot) error {
+	var updated []Plot
+	_, err := r.client.From("plots").Update(map[string]interface{}{
+		"size_sqm":   plot.SizeSqm,
+		"price":      plot.Price,
+		"is_corner":  plot.IsCorner,
+		"status":     plot.Status,
+		"updated_at": plot.UpdatedAt,
+	}, "representation", "").Eq("id", plot.ID).
+		Or("reservation_id.is.null,status.eq."+plot.Status, "").
+		ExecuteTo(&updated)
+	if err != nil {
+		return supabaseError(err)
+	}
+	if len(updated) == 0 {
+		if _, err := r.GetByID(ctx, plot.ID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *supabasePlotRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "plots", map[string]string{"id": id})
+}
+
+// ============ RESERVATIONS ============
+
+type supabaseReservationRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseReservationRepo) Create(ctx context.Context, res *Reservation) error {
+	var created []Reservation
+	err := supabaseRPC(r.client, "reserve_plots", map[string]interface{}{
+		"p_id":          res.ID,
+		"p_property_id": res.PropertyID,
+		"p_user_id":     res.UserID,
+		"p_plot_ids":    res.PlotIDs,
+		"p_expires_at":  res.ExpiresAt,
+	}, &created)
+	if err != nil {
+		return err
+	}
+	if len(created) == 1 {
+		*res = created[0]
+	}
+	return nil
+}
+
+func (r *supabaseReservationRepo) GetByID(ctx context.Context, id string) (*Reservation, error) {
+	var res Reservation
+	if err := supabaseSingle(r.client, "reservations", "id", id, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *supabaseReservationRepo) List(ctx context.Context, filter ReservationFilter) ([]Reservation, int, error) {
+	reservations := []Reservation{}
+	query := r.client.From("reservations").Select("*", "exact", false)
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	if filter.PropertyID != "" {
+		query = query.Eq("property_id", filter.PropertyID)
+	}
+	if filter.UserID != "" {
+		query = query.Eq("user_id", filter.UserID)
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&reservations)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return reservations, int(count), nil
+}
+
+func (r *supabaseReservationRepo) Complete(ctx context.Context, id, status string) (*Reservation, error) {
+	var completed []Reservation
+	err := supabaseRPC(r.client, "complete_reservation", map[string]interface{}{
+		"p_id":     id,
+		"p_status": status,
+	}, &completed)
+	if err != nil {
+		return nil, err
+	}
+	if len(completed) != 1 {
+		return nil, ErrNotFound
+	}
+	return &completed[0], nil
+}
+
+func (r *supabaseReservationRepo) Convert(ctx context.Context, id string, schedule *PaymentSchedule) (*Reservation, error) {
+	var converted []Reservation
+	err := supabaseRPC(r.client, "convert_reservation", map[string]interface{}{
+		"p_id":       id,
+		"p_schedule": schedule,
+	}, &converted)
+	if err != nil {
+		return nil, err
+	}
+	if len(converted) != 1 {
+		return nil, ErrNotFound
+	}
+	return &converted[0], nil
+}
+
+func (r *supabaseReservationRepo) ExpireDue(ctx context.Context, now time.Time) ([]Reservation, error) {
+	expired := []Reservation{}
+	err := supabaseRPC(r.client, "expire_reservations", map[string]interface{}{"p_now": now}, &expired)
+	return expired, err
+}
+
+// ============ PAYMENT PLANS ============
+
+type supabasePaymentPlanRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabasePaymentPlanRepo) ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]PaymentPlan, error) {
+	plans := []PaymentPlan{}
+	query := r.client.From("payment_plans").Select("*", "", false).Eq("property_id", propertyID)
+	if activeOnly {
+		query = query.Eq("is_active", "true")
+	}
+	query = query.Order("months", &postgrest.OrderOpts{Ascending: true}).Order("created_at", &postgrest.OrderOpts{Ascending: true})
+	if _, err := query.ExecuteTo(&plans); err != nil {
+		return nil, supabaseError(err)
+	}
+	return plans, nil
+}
+
+func (r *supabasePaymentPlanRepo) GetByID(ctx context.Context, id string) (*PaymentPlan, error) {
+	var plan PaymentPlan
+	if err := supabaseSingle(r.client, "payment_plans", "id", id, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *supabasePaymentPlanRepo) Create(ctx context.Context, plan *PaymentPlan) error {
+	return supabaseInsert(r.client, "payment_plans", plan)
+}
+
+func (r *supabasePaymentPlanRepo) Update(ctx context.Context, plan *PaymentPlan) error {
+	return supabaseUpdate(r.client, "payment_plans", plan.ID, plan)
+}
+
+func (r *supabasePaymentPlanRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "payment_plans", map[string]string{"id": id})
+}
+
+// ============ PAYMENT SCHEDULES ============
+
+type supabaseScheduleRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseScheduleRepo) List(ctx context.Context, filter PaymentScheduleFilter) ([]PaymentSchedule, int, error) {
+	schedules := []PaymentSchedule{}
+	query := r.client.From("payment_schedules").Select("*", "exact", false)
+	if filter.UserID != "" {
+		query = query.Eq("user_id", filter.UserID)
+	}
+	if filter.PropertyID != "" {
+		query = query.Eq("property_id", filter.PropertyID)
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&schedules)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return schedules, int(count), nil
+}
+
+func (r *supabaseScheduleRepo) GetByID(ctx context.Context, id string) (*PaymentSchedule, error) {
+	var schedule PaymentSchedule
+	if err := supabaseSingle(r.client, "payment_schedules", "id", id, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *supabaseScheduleRepo) Create(ctx context.Context, schedule *PaymentSchedule) error {
+	return supabaseInsert(r.client, "payment_schedules", schedule)
+}
+
+// ============ PAYMENTS ============
+
+type supabasePaymentRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabasePaymentRepo) List(ctx context.Context, filter PaymentFilter) ([]Payment, int, error) {
+	payments := []Payment{}
+	query := r.client.From("payments").Select("*", "exact", false)
+	if filter.UserID != "" {
+		query = query.Eq("user_id", filter.UserID)
+	}
+	if filter.PropertyID != "" {
+		query = query.Eq("property_id", filter.PropertyID)
+	}
+	if filter.ScheduleID != "" {
+		query = query.Eq("schedule_id", filter.ScheduleID)
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&payments)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return payments, int(count), nil
+}
+
+func (r *supabasePaymentRepo) ListBySchedule(ctx context.Context, scheduleID string) ([]Payment, error) {
+	payments := []Payment{}
+	_, err := r.client.From("payments").Select("*", "", false).Eq("schedule_id", scheduleID).
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&payments)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return payments, nil
+}
+
+func (r *supabasePaymentRepo) GetByID(ctx context.Context, id string) (*Payment, error) {
+	var payment Payment
+	if err := supabaseSingle(r.client, "payments", "id", id, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Create omits receipt_number so the column default assigns the next one
+func (r *supabasePaymentRepo) Create(ctx context.Context, payment *Payment) error {
+	payment.ReceiptNumber = ""
+	var created []Payment
+	if err := supabaseRPC(r.client, "record_payment", map[string]interface{}{"p_payment": payment}, &created); err != nil {
+		return err
+	}
+	if len(created) == 1 {
+		payment.ReceiptNumber = created[0].ReceiptNumber
+	}
+	return nil
+}
+
+func (r *supabasePaymentRepo) Reverse(ctx context.Context, id, reason string, at time.Time) (*Payment, error) {
+	var reversed []Payment
+	_, err := r.client.From("payments").Update(map[string]interface{}{
+		"status":          "reversed",
+		"reversed_at":     at,
+		"reversal_reason": reason,
+	}, "representation", "").Eq("id", id).Eq("status", "recorded").ExecuteTo(&reversed)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	if len(reversed) == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrConflict
+	}
+	return &reversed[0], nil
+}
+
+// ============ BLOG POSTS ============
+
+type supabaseBlogRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseBlogRepo) List(ctx context.Context, filter BlogFilter) ([]BlogPost, int, error) {
+	posts := []BlogPost{}
+	query := r.client.From("blog_posts").Select("*", "exact", false)
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Filter("category", "imatch", "^"+postgrestPattern(filter.Category)+"$")
+	}
+	orderBy := "created_at"
+	if filter.PublishedOnly {
+		query = query.Eq("status", BlogPublished)
+		orderBy = "published_at"
+	}
+	count, err := supabaseSortedPage(query, orderBy, false, filter.PaginationParams).ExecuteTo(&posts)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return posts, int(count), nil
+}
+
+func (r *supabaseBlogRepo) GetByID(ctx context.Context, id string) (*BlogPost, error) {
+	var post BlogPost
+	if err := supabaseSingle(r.client, "blog_posts", "id", id, &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *supabaseBlogRepo) GetBySlug(ctx context.Context, slug string) (*BlogPost, error) {
+	var post BlogPost
+	if err := supabaseSingle(r.client, "blog_posts", "slug", slug, &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// Create and Save go through functions that write the post, redirect and
+// revision in one transaction
+func (r *supabaseBlogRepo) Create(ctx context.Context, post *BlogPost, rev *BlogRevision) error {
+	var added []BlogRevision
+	err := supabaseRPC(r.client, "create_blog_post", map[string]interface{}{
+		"p_post":     post,
+		"p_revision": rev,
+	}, &added)
+	return supabaseRevisionNumber("create_blog_post", rev, added, err)
+}
+
+func (r *supabaseBlogRepo) Save(ctx context.Context, post *BlogPost, redirect *SlugRedirect, rev *BlogRevision) error {
+	var added []BlogRevision
+	err := supabaseRPC(r.client, "save_blog_post", map[string]interface{}{
+		"p_post":     post,
+		"p_redirect": redirect,
+		"p_revision": rev,
+	}, &added)
+	return supabaseRevisionNumber("save_blog_post", rev, added, err)
+}
+
+func (r *supabaseBlogRepo) Update(ctx context.Context, p *BlogPost) error {
+	return supabaseUpdate(r.client, "blog_posts", p.ID, map[string]interface{}{
+		"title":                p.Title,
+		"slug":                 p.Slug,
+		"excerpt":              p.Excerpt,
+		"content_markdown":     p.ContentMarkdown,
+		"content_html":         p.ContentHTML,
+		"table_of_contents":    p.TableOfContents,
+		"reading_time_minutes": p.ReadingTimeMinutes,
+		"category":             p.Category,
+		"tags":                 p.Tags,
+		"image_url":            p.ImageURL,
+		"image_alt":            p.ImageAlt,
+		"author":               p.Author,
+		"announce":             p.Announce,
+		"updated_at":           p.UpdatedAt,
+	})
+}
+
+func (r *supabaseBlogRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "blog_posts", map[string]string{"id": id})
+}
+
+func (r *supabaseBlogRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	var moved []BlogPost
+	return supabaseRPC(r.client, "transition_blog_post", map[string]interface{}{
+		"p_id":   id,
+		"p_from": from,
+		"p_to":   to,
+		"p_at":   at,
+	}, &moved)
+}
+
+func (r *supabaseBlogRepo) Due(ctx context.Context, now time.Time) ([]BlogPost, error) {
+	posts := []BlogPost{}
+	_, err := r.client.From("blog_posts").Select("*", "", false).
+		Eq("status", BlogScheduled).Lte("publish_at", now.UTC().Format(time.RFC3339Nano)).
+		Order("publish_at", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&posts)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return posts, nil
+}
+
+func (r *supabaseBlogRepo) Announce(ctx context.Context, id string, campaign *Campaign) error {
+	var created []Campaign
+	return supabaseRPC(r.client, "announce_blog_post", map[string]interface{}{
+		"p_post_id":  id,
+		"p_campaign": campaign,
+	}, &created)
+}
+
+// ============ BLOG REVISIONS ============
+
+type supabaseBlogRevisionRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseBlogRevisionRepo) List(ctx context.Context, postID string, page PaginationParams) ([]BlogRevision, int, error) {
+	revisions := []BlogRevision{}
+	query := r.client.From("blog_revisions").Select("*", "exact", false).Eq("post_id", postID)
+	count, err := supabaseSortedPage(query, "revision", false, page).ExecuteTo(&revisions)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return revisions, int(count), nil
+}
+
+func (r *supabaseBlogRevisionRepo) Get(ctx context.Context, postID string, number int) (*BlogRevision, error) {
+	var revision BlogRevision
+	_, err := r.client.From("blog_revisions").Select("*", "", false).
+		Eq("post_id", postID).Eq("revision", strconv.Itoa(number)).Single().ExecuteTo(&revision)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return &revision, nil
+}
+
+// Create goes through a function that numbers the revision while holding a
+// lock on the post
+func (r *supabaseBlogRevisionRepo) Create(ctx context.Context, rev *BlogRevision) error {
+	var added []BlogRevision
+	err := supabaseRPC(r.client, "add_blog_revision", map[string]interface{}{
+		"p_id":               rev.ID,
+		"p_post_id":          rev.PostID,
+		"p_title":            rev.Title,
+		"p_excerpt":          rev.Excerpt,
+		"p_content_markdown": rev.ContentMarkdown,
+		"p_category":         rev.Category,
+		"p_tags":             rev.Tags,
+		"p_image_url":        rev.ImageURL,
+		"p_image_alt":        rev.ImageAlt,
+		"p_author":           rev.Author,
+		"p_edited_by":        rev.EditedBy,
+		"p_editor_email":     rev.EditorEmail,
+		"p_restored_from":    rev.RestoredFrom,
+		"p_created_at":       rev.CreatedAt,
+	}, &added)
+	return supabaseRevisionNumber("add_blog_revision", rev, added, err)
+}
+
+// supabaseRevisionNumber copies the number of the revision a function
+// stored onto rev
+func supabaseRevisionNumber(name string, rev *BlogRevision, added []BlogRevision, err error) error {
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		return fmt.Errorf("rpc %s: no revision returned", name)
+	}
+	rev.Revision = added[0].Revision
+	return nil
+}
+
+// ============ CONTACT SUBMISSIONS ============
+
+type supabaseContactRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseContactRepo) List(ctx context.Context, filter ContactFilter) ([]ContactSubmission, int, error) {
+	submissions := []ContactSubmission{}
+	query := r.client.From("contact_submissions").Select("*", "exact", false)
+	if filter.UnreadOnly {
+		query = query.Eq("is_read", "false")
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&submissions)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return submissions, int(count), nil
+}
+
+func (r *supabaseContactRepo) GetByID(ctx context.Context, id string) (*ContactSubmission, error) {
+	var submission ContactSubmission
+	if err := supabaseSingle(r.client, "contact_submissions", "id", id, &submission); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+func (r *supabaseContactRepo) Create(ctx context.Context, submission *ContactSubmission) error {
+	return supabaseInsert(r.client, "contact_submissions", submission)
+}
+
+func (r *supabaseContactRepo) MarkRead(ctx context.Context, id string) error {
+	return supabaseUpdate(r.client, "contact_submissions", id, map[string]interface{}{"is_read": true})
+}
+
+// ============ NEWSLETTER SUBSCRIBERS ============
+
+// supabaseNewsletterRepo reads through the subscriber_profiles view, which
+// adds the linked account and its favorites, and writes to newsletter_subscribers
+type supabaseNewsletterRepo struct {
+	client *supabase.Client
+}
+
+// supabaseSubscriberRow is the writable part of a subscriber
+type supabaseSubscriberRow struct {
+	ID                 string     `json:"id"`
+	Email              string     `json:"email"`
+	Name               string     `json:"name"`
+	Status             string     `json:"status"`
+	Active             bool       `json:"active"`
+	CreatedAt          time.Time  `json:"created_at"`
+	ConfirmedAt        *time.Time `json:"confirmed_at"`
+	UnsubAt            *time.Time `json:"unsub_at"`
+	ConfirmationSentAt *time.Time `json:"confirmation_sent_at"`
+	TrackingOptOut     bool       `json:"tracking_opt_out"`
+	Source             string     `json:"source"`
+	Tags               []string   `json:"tags"`
+	Interests          []string   `json:"interests"`
+}
+
+func (r *supabaseNewsletterRepo) List(ctx context.Context, filter NewsletterFilter) ([]NewsletterSubscriber, int, error) {
+	subscribers := []NewsletterSubscriber{}
+	query := r.client.From("subscriber_profiles").Select("*", "exact", false)
+	if filter.ActiveOnly {
+		query = query.Eq("active", "true")
+	}
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&subscribers)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return subscribers, int(count), nil
+}
+
+func (r *supabaseNewsletterRepo) GetByEmail(ctx context.Context, email string) (*NewsletterSubscriber, error) {
+	var subscriber NewsletterSubscriber
+	if err := supabaseSingle(r.client, "subscriber_profiles", "email", strings.ToLower(email), &subscriber); err != nil {
+		return nil, err
+	}
+	return &subscriber, nil
+}
+
+func (r *supabaseNewsletterRepo) Create(ctx context.Context, s *NewsletterSubscriber) error {
+	return supabaseInsert(r.client, "newsletter_subscribers", supabaseSubscriberRow{
+		ID:                 s.ID,
+		Email:              s.Email,
+		Name:               s.Name,
+		Status:             s.Status,
+		Active:             s.Active,
+		CreatedAt:          s.CreatedAt,
+		ConfirmedAt:        s.ConfirmedAt,
+		UnsubAt:            s.UnsubAt,
+		ConfirmationSentAt: s.ConfirmationSentAt,
+		TrackingOptOut:     s.TrackingOptOut,
+		Source:             s.Source,
+		Tags:               s.Tags,
+		Interests:          s.Interests,
+	})
+}
+
+func (r *supabaseNewsletterRepo) Update(ctx context.Context, s *NewsletterSubscriber) error {
+	return supabaseUpdate(r.client, "newsletter_subscribers", s.ID, map[string]interface{}{
+		"email":                s.Email,
+		"name":                 s.Name,
+		"status":               s.Status,
+		"active":               s.Active,
+		"confirmed_at":         s.ConfirmedAt,
+		"unsub_at":             s.UnsubAt,
+		"confirmation_sent_at": s.ConfirmationSentAt,
+		"tracking_opt_out":     s.TrackingOptOut,
+		"tags":                 s.Tags,
+		"interests":            s.Interests,
+	})
+}
+
+// ============ NEWSLETTER SEGMENTS ============
+
+type supabaseSegmentRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseSegmentRepo) List(ctx context.Context, page PaginationParams) ([]Segment, int, error) {
+	segments := []Segment{}
+	query := r.client.From("newsletter_segments").Select("*", "exact", false)
+	count, err := supabaseSortedPage(query, "name", true, page).ExecuteTo(&segments)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return segments, int(count), nil
+}
+
+func (r *supabaseSegmentRepo) GetByID(ctx context.Context, id string) (*Segment, error) {
+	var segment Segment
+	if err := supabaseSingle(r.client, "newsletter_segments", "id", id, &segment); err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *supabaseSegmentRepo) Create(ctx context.Context, segment *Segment) error {
+	return supabaseInsert(r.client, "newsletter_segments", segment)
+}
+
+func (r *supabaseSegmentRepo) Update(ctx context.Context, segment *Segment) error {
+	return supabaseUpdate(r.client, "newsletter_segments", segment.ID, map[string]interface{}{
+		"name":        segment.Name,
+		"description": segment.Description,
+		"filter":      segment.Filter,
+		"updated_at":  segment.UpdatedAt,
+	})
+}
+
+func (r *supabaseSegmentRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "newsletter_segments", map[string]string{"id": id})
+}
+
+// ============ NEWSLETTER CAMPAIGNS ============
+
+// supabaseCampaignRepo reads through the campaign_overview view, which adds
+// the recipient counts, and writes to newsletter_campaigns
+type supabaseCampaignRepo struct {
+	client *supabase.Client
+}
+
+// supabaseCampaignRow is the writable part of a campaign
+type supabaseCampaignRow struct {
+	ID          string     `json:"id"`
+	Subject     string     `json:"subject"`
+	HTMLBody    string     `json:"html_body"`
+	TextBody    string     `json:"text_body"`
+	Segment     string     `json:"segment"`
+	Status      string     `json:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+	CreatedBy   *string    `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (r *supabaseCampaignRepo) List(ctx context.Context, filter CampaignFilter) ([]Campaign, int, error) {
+	campaigns := []Campaign{}
+	query := r.client.From("campaign_overview").Select("*", "exact", false)
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	if filter.Segment != "" {
+		query = query.Eq("segment", filter.Segment)
+	}
+	count, err := supabasePage(query, filter.PaginationParams).ExecuteTo(&campaigns)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return campaigns, int(count), nil
+}
+
+func (r *supabaseCampaignRepo) GetByID(ctx context.Context, id string) (*Campaign, error) {
+	var campaign Campaign
+	if err := supabaseSingle(r.client, "campaign_overview", "id", id, &campaign); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *supabaseCampaignRepo) Create(ctx context.Context, c *Campaign) error {
+	return supabaseInsert(r.client, "newsletter_campaigns", supabaseCampaignRow{
+		ID:          c.ID,
+		Subject:     c.Subject,
+		HTMLBody:    c.HTMLBody,
+		TextBody:    c.TextBody,
+		Segment:     c.Segment,
+		Status:      c.Status,
+		ScheduledAt: c.ScheduledAt,
+		CreatedBy:   c.CreatedBy,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	})
+}
+
+func (r *supabaseCampaignRepo) Update(ctx context.Context, c *Campaign) error {
+	return supabaseUpdate(r.client, "newsletter_campaigns", c.ID, map[string]interface{}{
+		"subject":    c.Subject,
+		"html_body":  c.HTMLBody,
+		"text_body":  c.TextBody,
+		"segment":    c.Segment,
+		"updated_at": c.UpdatedAt,
+	})
+}
+
+func (r *supabaseCampaignRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "newsletter_campaigns", map[string]string{"id": id})
+}
+
+func (r *supabaseCampaignRepo) Transition(ctx context.Context, id string, from []string, to string, at time.Time) error {
+	var moved []supabaseCampaignRow
+	return supabaseRPC(r.client, "transition_campaign", map[string]interface{}{
+		"p_id":   id,
+		"p_from": from,
+		"p_to":   to,
+		"p_at":   at,
+	}, &moved)
+}
+
+func (r *supabaseCampaignRepo) Due(ctx context.Context, now time.Time) ([]Campaign, error) {
+	campaigns := []Campaign{}
+	due := "status.eq.sending,and(status.eq.scheduled,scheduled_at.lte." + now.UTC().Format(time.RFC3339Nano) + ")"
+	_, err := r.client.From("campaign_overview").Select("*", "", false).Or(due, "").
+		Order("scheduled_at", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&campaigns)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return campaigns, nil
+}
+
+// AddRecipients goes through a function so that existing recipients are
+// skipped rather than overwritten
+func (r *supabaseCampaignRepo) AddRecipients(ctx context.Context, campaignID string, recipients []CampaignRecipient) error {
+	subscriberIDs := make([]*string, len(recipients))
+	emails := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		subscriberIDs[i] = recipient.SubscriberID
+		emails[i] = recipient.Email
+	}
+	var added int
+	return supabaseRPC(r.client, "add_campaign_recipients", map[string]interface{}{
+		"p_campaign_id":    campaignID,
+		"p_subscriber_ids": subscriberIDs,
+		"p_emails":         emails,
+	}, &added)
+}
+
+func (r *supabaseCampaignRepo) PendingRecipients(ctx context.Context, campaignID string, limit int) ([]CampaignRecipient, error) {
+	recipients := []CampaignRecipient{}
+	_, err := r.client.From("campaign_recipients").Select("*", "", false).
+		Eq("campaign_id", campaignID).Eq("status", "pending").
+		Order("attempts", &postgrest.OrderOpts{Ascending: true}).
+		Order("email", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").ExecuteTo(&recipients)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return recipients, nil
+}
+
+func (r *supabaseCampaignRepo) GetRecipient(ctx context.Context, id string) (*CampaignRecipient, error) {
+	var recipient CampaignRecipient
+	if err := supabaseSingle(r.client, "campaign_recipients", "id", id, &recipient); err != nil {
+		return nil, err
+	}
+	return &recipient, nil
+}
+
+func (r *supabaseCampaignRepo) UpdateRecipient(ctx context.Context, recipient *CampaignRecipient) error {
+	return supabaseUpdate(r.client, "campaign_recipients", recipient.ID, map[string]interface{}{
+		"status":   recipient.Status,
+		"attempts": recipient.Attempts,
+		"error":    recipient.Error,
+		"sent_at":  recipient.SentAt,
+	})
+}
+
+func (r *supabaseCampaignRepo) ListRecipients(ctx context.Context, filter CampaignRecipientFilter) ([]CampaignRecipient, int, error) {
+	recipients := []CampaignRecipient{}
+	query := r.client.From("campaign_recipients").Select("*", "exact", false).Eq("campaign_id", filter.CampaignID)
+	if filter.Status != "" {
+		query = query.Eq("status", filter.Status)
+	}
+	count, err := supabaseSortedPage(query, "email", true, filter.PaginationParams).ExecuteTo(&recipients)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return recipients, int(count), nil
+}
+
+func (r *supabaseCampaignRepo) RecordEvent(ctx context.Context, event *CampaignEvent) error {
+	return supabaseInsert(r.client, "campaign_events", event)
+}
+
+func (r *supabaseCampaignRepo) EventStats(ctx context.Context, campaignID string) (*CampaignStats, error) {
+	var stats []CampaignStats
+	err := supabaseRPC(r.client, "campaign_event_stats", map[string]interface{}{"p_campaign_id": campaignID}, &stats)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) != 1 {
+		return &CampaignStats{}, nil
+	}
+	return &stats[0], nil
+}
+
+func (r *supabaseCampaignRepo) TopLinks(ctx context.Context, campaignID string, limit int) ([]CampaignLinkStats, error) {
+	links := []CampaignLinkStats{}
+	err := supabaseRPC(r.client, "campaign_top_links", map[string]interface{}{
+		"p_campaign_id": campaignID,
+		"p_limit":       limit,
+	}, &links)
+	return links, err
+}
+
+// ============ USERS ============
+
+// supabaseUserRow exposes the password hash, which User hides from JSON
+type supabaseUserRow struct {
+	User
+	Password string `json:"password"`
+}
+
+func (row supabaseUserRow) toUser() *User {
+	user := row.User
+	user.Password = row.Password
+	return &user
+}
+
+type supabaseUserRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseUserRepo) List(ctx context.Context, page PaginationParams) ([]User, int, error) {
+	users := []User{}
+	query := r.client.From("users").Select("*", "exact", false)
+	count, err := supabasePage(query, page).ExecuteTo(&users)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return users, int(count), nil
+}
+
+func (r *supabaseUserRepo) GetByID(ctx context.Context, id string) (*User, error) {
+	var row supabaseUserRow
+	if err := supabaseSingle(r.client, "users", "id", id, &row); err != nil {
+		return nil, err
+	}
+	return row.toUser(), nil
+}
+
+func (r *supabaseUserRepo) GetByEmail(ctx context.Context, email string) (*User, error) {
+	var row supabaseUserRow
+	if err := supabaseSingle(r.client, "users", "email", strings.ToLower(email), &row); err != nil {
+		return nil, err
+	}
+	return row.toUser(), nil
+}
+
+func (r *supabaseUserRepo) Create(ctx context.Context, user *User) error {
+	return supabaseInsert(r.client, "users", supabaseUserRow{User: *user, Password: user.Password})
+}
+
+func (r *supabaseUserRepo) Update(ctx context.Context, user *User) error {
+	return supabaseUpdate(r.client, "users", user.ID, supabaseUserRow{User: *user, Password: user.Password})
+}
+
+func (r *supabaseUserRepo) Delete(ctx context.Context, id string) error {
+	return supabaseDelete(r.client, "users", map[string]string{"id": id})
+}
+
+// ============ REVIEWS ============
+
+type supabaseReviewRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseReviewRepo) listBy(column, value string) ([]Review, error) {
+	reviews := []Review{}
+	query := r.client.From("reviews").Select("*", "", false).Eq(column, value)
+	if _, err := supabasePage(query, PaginationParams{}).ExecuteTo(&reviews); err != nil {
+		return nil, supabaseError(err)
+	}
+	return reviews, nil
+}
+
+func (r *supabaseReviewRepo) ListByUser(ctx context.Context, userID string) ([]Review, error) {
+	return r.listBy("user_id", userID)
+}
+
+func (r *supabaseReviewRepo) ListByProperty(ctx context.Context, propertyID string) ([]Review, error) {
+	return r.listBy("property_id", propertyID)
+}
+
+func (r *supabaseReviewRepo) Create(ctx context.Context, review *Review) error {
+	return supabaseInsert(r.client, "reviews", review)
+}
+
+// ============ FAVORITES ============
+
+type supabaseFavoriteRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseFavoriteRepo) ListByUser(ctx context.Context, userID string) ([]Favorite, error) {
+	favorites := []Favorite{}
+	query := r.client.From("favorites").Select("*", "", false).Eq("user_id", userID)
+	if _, err := supabasePage(query, PaginationParams{}).ExecuteTo(&favorites); err != nil {
+		return nil, supabaseError(err)
+	}
+	return favorites, nil
+}
+
+func (r *supabaseFavoriteRepo) Add(ctx context.Context, favorite *Favorite) error {
+	return supabaseInsert(r.client, "favorites", favorite)
+}
+
+func (r *supabaseFavoriteRepo) Remove(ctx context.Context, userID, propertyID string) error {
+	return supabaseDelete(r.client, "favorites", map[string]string{
+		"user_id":     userID,
+		"property_id": propertyID,
+	})
+}
+
+// ============ BROCHURE REQUESTS ============
+
+type supabaseBrochureRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseBrochureRepo) Create(ctx context.Context, request *BrochureRequest) error {
+	return supabaseInsert(r.client, "brochure_requests", request)
+}
+
+func (r *supabaseBrochureRepo) GetByID(ctx context.Context, id string) (*BrochureRequest, error) {
+	var request BrochureRequest
+	if err := supabaseSingle(r.client, "brochure_requests", "id", id, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// RecordDownload goes through a function because PostgREST cannot increment a column
+func (r *supabaseBrochureRepo) RecordDownload(ctx context.Context, id string, at time.Time) error {
+	var recorded []BrochureRequest
+	err := supabaseRPC(r.client, "record_brochure_download", map[string]interface{}{
+		"p_id": id,
+		"p_at": at,
+	}, &recorded)
+	if err != nil {
+		return err
+	}
+	if len(recorded) != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *supabaseBrochureRepo) Stats(ctx context.Context, limit int) ([]BrochureStats, error) {
+	params := map[string]interface{}{"p_limit": nil}
+	if limit > 0 {
+		params["p_limit"] = limit
+	}
+	stats := []BrochureStats{}
+	err := supabaseRPC(r.client, "brochure_stats", params, &stats)
+	return stats, err
+}
+
+// ============ LEADS ============
+
+type supabaseLeadRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseLeadRepo) List(ctx context.Context, filter LeadFilter) ([]Lead, int, error) {
+	leads := []Lead{}
+	query := r.client.From("leads").Select("*", "exact", false)
+	if filter.Source != "" {
+		query = query.Eq("source", filter.Source)
+	}
+	if filter.Search != "" {
+		query = query.Filter("email", "imatch", postgrestPattern(filter.Search))
+	}
+	count, err := supabaseSortedPage(query, "last_seen_at", false, filter.PaginationParams).ExecuteTo(&leads)
+	if err != nil {
+		return nil, 0, supabaseError(err)
+	}
+	return leads, int(count), nil
+}
+
+func (r *supabaseLeadRepo) Capture(ctx context.Context, lead *Lead) error {
+	var captured []Lead
+	err := supabaseRPC(r.client, "capture_lead", map[string]interface{}{
+		"p_id":      lead.ID,
+		"p_email":   lead.Email,
+		"p_source":  lead.Source,
+		"p_user_id": lead.UserID,
+		"p_at":      lead.LastSeenAt,
+	}, &captured)
+	if err != nil {
+		return err
+	}
+	if len(captured) == 1 {
+		*lead = captured[0]
+	}
+	return nil
+}
+
+// ============ SESSIONS ============
+
+type supabaseSessionRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseSessionRepo) Create(ctx context.Context, session *Session) error {
+	return supabaseInsert(r.client, "sessions", session)
+}
+
+func (r *supabaseSessionRepo) GetByID(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	if err := supabaseSingle(r.client, "sessions", "id", id, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *supabaseSessionRepo) Rotate(ctx context.Context, id, oldJTI, newJTI string, expiresAt time.Time) error {
+	var updated []Session
+	_, err := r.client.From("sessions").
+		Update(map[string]interface{}{
+			"refresh_jti": newJTI,
+			"expires_at":  expiresAt,
+			"updated_at":  time.Now(),
+		}, "representation", "").
+		Eq("id", id).Eq("refresh_jti", oldJTI).Is("revoked_at", "null").
+		ExecuteTo(&updated)
+	if err != nil {
+		return supabaseError(err)
+	}
+	if len(updated) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// revokeWhere marks every active session matching column = value as revoked
+func (r *supabaseSessionRepo) revokeWhere(column, value string) error {
+	now := time.Now()
+	_, _, err := r.client.From("sessions").
+		Update(map[string]interface{}{"revoked_at": now, "updated_at": now}, "minimal", "").
+		Eq(column, value).Is("revoked_at", "null").
+		Execute()
+	return supabaseError(err)
+}
+
+func (r *supabaseSessionRepo) Revoke(ctx context.Context, id string) error {
+	return r.revokeWhere("id", id)
+}
+
+func (r *supabaseSessionRepo) RevokeByUser(ctx context.Context, userID string) error {
+	return r.revokeWhere("user_id", userID)
+}
+
+// ============ SLUG REDIRECTS ============
+
+type supabaseSlugRedirectRepo struct {
+	client *supabase.Client
+}
+
+func (r *supabaseSlugRedirectRepo) Save(ctx context.Context, redirect *SlugRedirect) error {
+	row := map[string]interface{}{
+		"entity_type": redirect.EntityType,
+		"old_slug":    redirect.OldSlug,
+		"entity_id":   redirect.EntityID,
+		"created_at":  redirect.CreatedAt,
+	}
+	_, _, err := r.client.From("slug_redirects").Insert(row, true, "entity_type,old_slug", "minimal", "").Execute()
+	return supabaseError(err)
+}
+
+func (r *supabaseSlugRedirectRepo) Get(ctx context.Context, entityType, slug string) (*SlugRedirect, error) {
+	var redirect SlugRedirect
+	_, err := r.client.From("slug_redirects").Select("*", "", false).
+		Eq("entity_type", entityType).Eq("old_slug", slug).
+		Single().ExecuteTo(&redirect)
+	if err != nil {
+		return nil, supabaseError(err)
+	}
+	return &redirect, nil
+}
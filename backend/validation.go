@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// validate enforces the `validate` struct tags on request types. Field
+// errors are reported under their JSON or query names so forms can match them up.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("geojson_polygon", func(fl validator.FieldLevel) bool {
+		return isGeoJSONPolygon(fl.Field().Bytes())
+	})
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, key := range []string{"json", "query"} {
+			name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return ""
+	})
+	return v
+}
+
+// validateRequest checks a parsed request body, returning one FieldError per
+// failing field or nil when the body is valid
+func validateRequest(req interface{}) []FieldError {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	fields := make([]FieldError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		// Report nested fields by path, e.g. plots[2].plot_number
+		field := fe.Field()
+		if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+			field = path
+		}
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return fields
+}
+
+// validationError writes a 422 response listing the failing fields
+func validationError(c *fiber.Ctx, fields []FieldError) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(ErrorResponse{
+		Error:   "Validation Failed",
+		Message: "One or more fields are invalid",
+		Code:    fiber.StatusUnprocessableEntity,
+		Fields:  fields,
+	})
+}
+
+// fieldMessage describes a failed rule in words suitable for a form
+func fieldMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid":
+		return "must be a valid ID"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "required_with":
+		return "is required with " + strings.ToLower(fe.Param())
+	case "unique":
+		return "must not contain duplicates"
+	case "geojson_polygon":
+		return "must be a GeoJSON Polygon or MultiPolygon"
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "datetime":
+		if fe.Param() == "2006-01-02" {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+		return "must be a date formatted as " + fe.Param()
+	}
+	return "is invalid"
+}
+
+// uuidParams answers 404 for a request whose named route parameters are not
+// UUIDs. Every record is keyed by UUID, so no such record can exist, and
+// the stores need not tell a malformed ID apart from other bad input.
+func uuidParams(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, name := range names {
+			if validate.Var(c.Params(name), "uuid") != nil {
+				return errorJSON(c, fiber.StatusNotFound, "Not found")
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestValidateRequestReportsJSONFieldNames(t *testing.T) {
+	propertyID := "not-a-uuid"
+	fields := validateRequest(&ContactFormRequest{
+		FirstName:  "Ada",
+		Email:      "ada@",
+		Phone:      "555 0100",
+		Message:    "too short",
+		PropertyID: &propertyID,
+	})
+
+	got := map[string]FieldError{}
+	for _, f := range fields {
+		got[f.Field] = f
+	}
+	want := map[string]string{
+		"last_name":   "is required",
+		"email":       "must be a valid email address",
+		"message":     "must be at least 10 characters",
+		"property_id": "must be a valid ID",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d field errors, want %d: %+v", len(got), len(want), fields)
+	}
+	for field, message := range want {
+		if got[field].Message != message {
+			t.Errorf("%s: message %q, want %q", field, got[field].Message, message)
+		}
+	}
+	if got["message"].Rule != "min" || got["message"].Param != "10" {
+		t.Errorf("message: rule %s=%s, want min=10", got["message"].Rule, got["message"].Param)
+	}
+}
+
+func TestValidateRequestAcceptsValidBodies(t *testing.T) {
+	role := "admin"
+	for name, req := range map[string]interface{}{
+		"contact":     &ContactFormRequest{FirstName: "A", LastName: "B", Email: "a@example.com", Phone: "1", Message: "Hello there, team"},
+		"review":      &ReviewRequest{PropertyID: "6f1c2b4e-3d5a-4c7b-9e8f-0a1b2c3d4e5f", Rating: 5},
+		"update user": &UpdateUserRequest{Role: &role},
+		"empty patch": &UpdateProfileRequest{},
+	} {
+		if fields := validateRequest(req); fields != nil {
+			t.Errorf("%s: unexpected field errors %+v", name, fields)
+		}
+	}
+
+	role = "owner"
+	fields := validateRequest(&UpdateUserRequest{Role: &role})
+	if len(fields) != 1 || fields[0].Message != "must be one of: admin, user" {
+		t.Errorf("role owner: got %+v", fields)
+	}
+}
+
+func TestValidationErrorResponse(t *testing.T) {
+	app, h := newTestApp(t)
+
+	var resp ErrorResponse
+	status := call(t, app, "POST", "/api/v1/contact", "", fiber.Map{"email": "nobody"}, &resp)
+	if status != fiber.StatusUnprocessableEntity || resp.Code != status {
+		t.Fatalf("status = %d (code %d), want 422", status, resp.Code)
+	}
+	reported := map[string]bool{}
+	for _, f := range resp.Fields {
+		reported[f.Field] = true
+	}
+	for _, field := range []string{"first_name", "last_name", "email", "phone", "message"} {
+		if !reported[field] {
+			t.Errorf("%s is missing from the field errors %+v", field, resp.Fields)
+		}
+	}
+
+	createUser(t, h, "buyer@example.com", "buyer-pass", "user", true)
+	buyer := loginAs(t, app, "buyer@example.com", "buyer-pass")
+	resp = ErrorResponse{}
+	status = call(t, app, "POST", "/api/v1/reviews", buyer.AccessToken, fiber.Map{"property_id": uuid.New().String(), "rating": 9}, &resp)
+	if status != fiber.StatusUnprocessableEntity || len(resp.Fields) != 1 || resp.Fields[0].Field != "rating" {
+		t.Errorf("rating 9: status %d, fields %+v", status, resp.Fields)
+	}
+}
+
+func TestUUIDParams(t *testing.T) {
+	app := fiber.New()
+	app.Get("/plots/:id/:plotId", uuidParams("id", "plotId"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	id := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"both UUIDs", "/plots/" + id + "/" + id, fiber.StatusNoContent},
+		{"malformed first", "/plots/42/" + id, fiber.StatusNotFound},
+		{"malformed second", "/plots/" + id + "/plot-1", fiber.StatusNotFound},
+		{"quote", "/plots/" + id + "/" + id + "'", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			var out interface{}
+			if tt.wantStatus == fiber.StatusNotFound {
+				out = &resp
+			}
+			if status := call(t, app, "GET", tt.path, "", nil, out); status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if out != nil && resp.Code != fiber.StatusNotFound {
+				t.Errorf("body = %+v, want a JSON error", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runEvery calls fn every interval until ctx is cancelled, logging failures
+// instead of stopping so that one bad run does not end the loop
+func runEvery(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}
+}
+
+// startWorkers launches the background jobs of the API server
+func startWorkers(ctx context.Context, h *Handler) {
+	go runEvery(ctx, "Reservation sweeper", envDuration("RESERVATION_SWEEP_INTERVAL", time.Minute), h.ExpireReservations)
+	go func() {
+		if err := h.RenderBlogPosts(ctx); err != nil {
+			log.Printf("Blog renderer failed: %v", err)
+		}
+	}()
+	go runEvery(ctx, "Blog publisher", envDuration("BLOG_PUBLISH_INTERVAL", time.Minute), h.PublishScheduledPosts)
+	go runEvery(ctx, "Campaign sender", envDuration("CAMPAIGN_BATCH_INTERVAL", time.Minute), h.SendCampaigns)
+	h.mail.queue.Run(ctx, envInt("MAIL_WORKERS", 2))
+}